@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/collectors"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/reporting"
+)
+
+// arrService is one configured *arr instance: the classic [sonarr] and
+// [radarr] tables plus any [[arr]] entries.
+type arrService struct {
+	name      string
+	kind      string
+	cfg       config.ArrConfig
+	collector collectors.ArrCollector
+	// instance is set for [[arr]] entries, whose files are labelled with the
+	// instance name and carry its grace window.
+	instance bool
+}
+
+// arrFilesByKind holds tracked files keyed by service kind, one of
+// collectors.ArrKinds.
+type arrFilesByKind map[string][]models.ArrFile
+
+// engineInputs returns the Sonarr and Radarr file lists the analysis engine
+// takes. The engine only tells them apart to label files whose Service is
+// unset, which only the [sonarr] and [radarr] tables produce, so Whisparr and
+// Lidarr files, always from named [[arr]] entries, join the second list.
+func (a arrFilesByKind) engineInputs() (sonarrFiles, otherFiles []models.ArrFile) {
+	for _, kind := range collectors.ArrKinds {
+		if kind == "sonarr" {
+			sonarrFiles = a[kind]
+		} else {
+			otherFiles = append(otherFiles, a[kind]...)
+		}
+	}
+	return sonarrFiles, otherFiles
+}
+
+func configuredArrServices(cfg *config.Config) []arrService {
+	var services []arrService
+	if cfg.Sonarr.URL != "" {
+		services = append(services, arrService{"Sonarr", "sonarr", cfg.Sonarr, collectors.NewSonarrCollector(cfg.Sonarr.URL, cfg.Sonarr.APIKey), false})
+	}
+	if cfg.Radarr.URL != "" {
+		services = append(services, arrService{"Radarr", "radarr", cfg.Radarr, collectors.NewRadarrCollector(cfg.Radarr.URL, cfg.Radarr.APIKey), false})
+	}
+	for _, inst := range cfg.Arr {
+		collector, err := collectors.NewArrCollector(inst.Kind, inst.URL, inst.APIKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping [[arr]] %s: %v\n", inst.Name, err)
+			continue
+		}
+		services = append(services, arrService{inst.Name, inst.Kind, inst.ArrConfig, collector, true})
+	}
+	for _, svc := range services {
+		svc.collector.SetTransport(cfg.HTTP.Transport)
+		svc.collector.SetAuth(svc.cfg.Username, svc.cfg.Password, svc.cfg.Headers)
+		svc.collector.SetRequestTimeout(cfg.HTTP.ArrRequestTimeout)
+		svc.collector.SetCircuitBreaker(cfg.HTTP.ArrMaxFailures)
+	}
+	return services
+}
+
+// loadArrCache opens [paths].arr_cache_file, or returns nil when it is unset.
+// An unreadable cache is reported and replaced by the next save.
+func loadArrCache(cfg *config.Config) *collectors.ArrCache {
+	if cfg.Paths.ArrCacheFile == "" {
+		return nil
+	}
+	cache, err := collectors.LoadArrCache(cfg.Paths.ArrCacheFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; collecting every service in full\n", err)
+		cache = collectors.NewArrCache(cfg.Paths.ArrCacheFile)
+	}
+	return cache
+}
+
+// collectServiceFiles collects svc's tracked files, incrementally from its
+// cached snapshot when there is a usable one and the service supports it,
+// else in full. It returns the snapshot to cache when the files are
+// complete, without its Files.
+func collectServiceFiles(ctx context.Context, cfg *config.Config, svc arrService, cache *collectors.ArrCache, verbose bool) ([]models.ArrFile, collectors.ArrSnapshot, error) {
+	started := time.Now()
+	snap := collectors.ArrSnapshot{URL: svc.cfg.URL, Watermark: started, FullAt: started}
+	incremental, ok := svc.collector.(collectors.IncrementalArrCollector)
+	if cache != nil && ok {
+		maxAge := time.Duration(cfg.Paths.ArrFullRefreshHours) * time.Hour
+		if prev, usable := cache.Usable(svc.name, svc.cfg.URL, maxAge, started); usable {
+			files, err := incremental.CollectSince(ctx, prev.Watermark, prev.Files)
+			if err == nil {
+				if verbose {
+					fmt.Printf("Updated %s from history since %s\n", svc.name, prev.Watermark.Format(time.RFC3339))
+				}
+				snap.FullAt = prev.FullAt
+				return files, snap, nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: incremental %s collection failed, fetching everything: %v\n", svc.name, err)
+		}
+	}
+	files, err := svc.collector.Collect(ctx)
+	return files, snap, err
+}
+
+// runArrCheck stats every file the Arr services track and prints a per-service
+// reconciliation, without walking the filesystem. It returns 2 when any
+// tracked file is missing, matching the audit's findings exit code.
+func runArrCheck(ctx context.Context, cfg *config.Config, verbose bool) int {
+	excludedRoots := resolveExcludedRootFolders(ctx, cfg)
+	arrFiles, _ := collectArrFiles(ctx, cfg, excludedRoots, verbose)
+	sonarrFiles, otherArrFiles := arrFiles.engineInputs()
+
+	code := 0
+	for _, r := range analysis.ReconcileArrOnDisk(sonarrFiles, otherArrFiles, cfg.PathMappings, cfg.Permissions.SkipPaths) {
+		fmt.Println(reporting.ReconciliationLine(r))
+		if len(r.Missing) > 0 {
+			code = 2
+		}
+		if verbose {
+			for _, path := range r.Missing {
+				fmt.Printf("  missing: %s\n", path)
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestCollectArrFiles_CollectFailureFailsService(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/system/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v3/series", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database locked", http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{Sonarr: config.ArrConfig{URL: srv.URL, APIKey: "key"}}
+	cfg.HTTP.Transport = &http.Transport{}
+	files, statuses := collectArrFiles(context.Background(), cfg, nil, false)
+	if len(files["sonarr"]) != 0 {
+		t.Fatalf("got %d files, want none", len(files["sonarr"]))
+	}
+	if len(statuses) != 1 || statuses[0].OK || !strings.Contains(statuses[0].Error, "collecting files failed") {
+		t.Fatalf("status = %+v, want Sonarr failed with the collection error", statuses)
+	}
+
+	result := &analysis.AnalysisResult{ConnectionStatus: statuses}
+	if problems := result.IncompleteCollection(); len(problems) != 1 {
+		t.Errorf("IncompleteCollection = %q, want the failed collection so --strict fails", problems)
+	}
+}
+
+func TestCollectArrFiles_GroupsByKind(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/system/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v1/artist", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	})
+	mux.HandleFunc("/api/v1/trackfile", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":10,"artistId":1,"path":"/music/a/01.flac"}]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{Arr: []config.ArrInstanceConfig{
+		{Kind: "lidarr", Name: "Lidarr", ArrConfig: config.ArrConfig{URL: srv.URL, APIKey: "key"}},
+	}}
+	cfg.HTTP.Transport = &http.Transport{}
+	files, statuses := collectArrFiles(context.Background(), cfg, nil, false)
+	if len(statuses) != 1 || !statuses[0].OK {
+		t.Fatalf("status = %+v, want Lidarr connected", statuses)
+	}
+	if len(files["lidarr"]) != 1 || files["lidarr"][0].Service != "Lidarr" {
+		t.Fatalf("lidarr files = %+v, want the track labelled Lidarr", files["lidarr"])
+	}
+	if len(files["radarr"]) != 0 || len(files["sonarr"]) != 0 {
+		t.Errorf("Lidarr files leaked into other kinds: %+v", files)
+	}
+
+	sonarrFiles, otherFiles := files.engineInputs()
+	if len(sonarrFiles) != 0 || len(otherFiles) != 1 {
+		t.Errorf("engineInputs = %d Sonarr and %d other files, want 0 and 1", len(sonarrFiles), len(otherFiles))
+	}
+}
+
+func TestExcludeArrFiles(t *testing.T) {
+	files := []models.ArrFile{
+		{Path: "/tv/Show/S01E01.mkv"},
+		{Path: "/tv2/Show/S01E01.mkv"},
+		{Path: "/tvshows/Show/S01E01.mkv"},
+		{Path: "/movies/Film.mkv"},
+	}
+	mappings := map[string]string{"/tv": "/mnt/media/tv", "/tv2": "/mnt/media/tv2"}
+
+	kept := excludeArrFiles(slices.Clone(files), []string{"/mnt/media/tv"}, mappings)
+	var got []string
+	for _, f := range kept {
+		got = append(got, f.Path)
+	}
+	want := []string{"/tv2/Show/S01E01.mkv", "/tvshows/Show/S01E01.mkv", "/movies/Film.mkv"}
+	if !slices.Equal(got, want) {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+
+	if kept := excludeArrFiles(slices.Clone(files), nil, mappings); len(kept) != len(files) {
+		t.Errorf("no exclusions kept %d of %d files", len(kept), len(files))
+	}
+}
+
+func TestResolveExcludedRootFolders(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/rootfolder", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"path":"/tv"},{"path":"/tv2/"}]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{
+		Sonarr: config.ArrConfig{
+			URL:                srv.URL,
+			APIKey:             "key",
+			ExcludeRootFolders: []string{"/tv", "/tv3"},
+		},
+		PathMappings: map[string]string{"/tv": "/mnt/media/tv"},
+	}
+	cfg.HTTP.Transport = &http.Transport{}
+	got := resolveExcludedRootFolders(context.Background(), cfg)
+	if want := []string{"/mnt/media/tv"}; !slices.Equal(got, want) {
+		t.Errorf("excluded = %q, want %q (/tv3 is not a root folder, /tv2 was not excluded)", got, want)
+	}
+
+	cfg.Sonarr.URL = "http://127.0.0.1:1"
+	if got := resolveExcludedRootFolders(context.Background(), cfg); len(got) != 0 {
+		t.Errorf("excluded = %q when root folders could not be fetched, want none", got)
+	}
+}
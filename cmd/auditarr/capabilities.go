@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/collectors"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/reporting"
+)
+
+// capability is one collector or notifier built into this binary and
+// whether the loaded config turns it on.
+type capability struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// runCapabilities lists the collectors and notifiers this build supports.
+// Without a config to read, everything is reported disabled.
+func runCapabilities(args []string) {
+	fs := flag.NewFlagSet("capabilities", flag.ExitOnError)
+	configPath := configFlag(fs)
+	jsonOutput := fs.Bool("json", false, "Emit capabilities as JSON")
+	_ = fs.Parse(args)
+
+	cfg, err := loadConfig(*configPath, false)
+	if err != nil {
+		if *configPath != "" {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: no config loaded (%v); showing what is available only\n", err)
+	}
+
+	caps := capabilities(cfg)
+	if *jsonOutput {
+		out, err := json.MarshalIndent(caps, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode capabilities: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	lastType := ""
+	for _, c := range caps {
+		if c.Type != lastType {
+			if lastType != "" {
+				fmt.Println()
+			}
+			fmt.Printf("%ss:\n", strings.ToUpper(c.Type[:1])+c.Type[1:])
+			lastType = c.Type
+		}
+		state := "disabled"
+		if c.Enabled {
+			state = "enabled"
+		}
+		fmt.Println(strings.TrimRight(fmt.Sprintf("  %-22s %-9s %s", c.Name, state, c.Detail), " "))
+	}
+}
+
+// capabilities builds the list from the registered implementations, with
+// enabled states from cfg when it is non-nil.
+func capabilities(cfg *config.Config) []capability {
+	var caps []capability
+
+	fsCap := capability{Type: "collector", Name: "filesystem"}
+	if cfg != nil {
+		fsCap.Enabled = true
+		media, torrent := cfg.Paths.ScanRoots()
+		fsCap.Detail = strings.Join(nonEmpty(media, torrent), ", ")
+	}
+	caps = append(caps, fsCap)
+
+	var instances map[string][]string
+	if cfg != nil {
+		instances = make(map[string][]string)
+		for _, svc := range configuredArrServices(cfg) {
+			instances[svc.kind] = append(instances[svc.kind], svc.name)
+		}
+	}
+	for _, kind := range collectors.ArrKinds {
+		names := instances[kind]
+		caps = append(caps, capability{Type: "collector", Name: kind, Enabled: len(names) > 0, Detail: strings.Join(names, ", ")})
+	}
+
+	qb := capability{Type: "collector", Name: "qbittorrent", Detail: "WebUI API"}
+	backup := capability{Type: "collector", Name: "qbittorrent-fastresume", Detail: "BT_backup .fastresume files"}
+	if cfg != nil {
+		if cfg.Qbittorrent.URL != "" {
+			qb.Enabled = true
+			qb.Detail = cfg.Qbittorrent.URL
+		}
+		if cfg.Qbittorrent.BackupDir != "" {
+			backup.Enabled = true
+			backup.Detail = cfg.Qbittorrent.BackupDir
+		}
+	}
+	caps = append(caps, qb, backup)
+
+	// ffprobe is turned on per run with --verify-media, so report whether it
+	// could be rather than whether the config asks for it.
+	ffprobe := capability{Type: "collector", Name: "ffprobe", Detail: "--verify-media; ffprobe found on PATH"}
+	if _, err := collectors.NewMediaVerifier(0, 0, 0); err != nil {
+		ffprobe.Detail = "--verify-media; " + err.Error()
+	} else {
+		ffprobe.Enabled = true
+	}
+	caps = append(caps, ffprobe)
+
+	enabledNotifiers := make(map[string]bool)
+	if cfg != nil {
+		for _, n := range configuredNotifiers(cfg, "") {
+			enabledNotifiers[n.Name()] = true
+		}
+	}
+	for _, kind := range reporting.NotifierKinds {
+		caps = append(caps, capability{Type: "notifier", Name: kind, Enabled: enabledNotifiers[kind]})
+	}
+	return caps
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
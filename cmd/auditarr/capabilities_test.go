@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/collectors"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/reporting"
+)
+
+func TestCapabilities_JSONShape(t *testing.T) {
+	data, err := json.Marshal(capabilities(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, c := range decoded {
+		for _, key := range []string{"type", "name", "enabled"} {
+			if _, ok := c[key]; !ok {
+				t.Errorf("capability %v has no %q key", c, key)
+			}
+		}
+		if c["type"] != "collector" && c["type"] != "notifier" {
+			t.Errorf("capability %v has type %v, want collector or notifier", c["name"], c["type"])
+		}
+		if _, ok := c["detail"]; ok && c["detail"] == "" {
+			t.Errorf("capability %v has an empty detail; it should be omitted", c["name"])
+		}
+		if c["name"] != "ffprobe" && c["enabled"] == true {
+			t.Errorf("capability %v is enabled without a config", c["name"])
+		}
+		names = append(names, c["name"].(string))
+	}
+	for _, want := range slices.Concat([]string{"filesystem", "qbittorrent", "qbittorrent-fastresume", "ffprobe"}, collectors.ArrKinds, reporting.NotifierKinds) {
+		if !slices.Contains(names, want) {
+			t.Errorf("capabilities list %q, missing %q", names, want)
+		}
+	}
+}
+
+func TestCapabilities_EnabledFromConfig(t *testing.T) {
+	cfg := &config.Config{
+		Sonarr: config.ArrConfig{URL: "http://sonarr:8989", APIKey: "key"},
+		Arr: []config.ArrInstanceConfig{
+			{Kind: "sonarr", Name: "Sonarr 4K", ArrConfig: config.ArrConfig{URL: "http://sonarr4k:8989", APIKey: "key"}},
+		},
+	}
+	cfg.Paths.MediaRoot = "/mnt/media"
+	cfg.Qbittorrent.URL = "http://qbittorrent:8080"
+	cfg.Notifications.DiscordWebhook = "https://discord.invalid/webhook"
+
+	got := make(map[string]capability)
+	for _, c := range capabilities(cfg) {
+		got[c.Name] = c
+	}
+	for _, tc := range []struct {
+		name    string
+		enabled bool
+		detail  string
+	}{
+		{"filesystem", true, "/mnt/media"},
+		{"sonarr", true, "Sonarr, Sonarr 4K"},
+		{"radarr", false, ""},
+		{"qbittorrent", true, "http://qbittorrent:8080"},
+		{"qbittorrent-fastresume", false, "BT_backup .fastresume files"},
+		{"discord", true, ""},
+	} {
+		c := got[tc.name]
+		if c.Enabled != tc.enabled || c.Detail != tc.detail {
+			t.Errorf("%s = %+v, want enabled %t with detail %q", tc.name, c, tc.enabled, tc.detail)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/jdpx/auditarr/internal/collectors"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// Doctor check outcomes. A fail means a scan would be wrong or impossible; a
+// warn means it would run but some results may be misleading.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorSampleSize is how many files the on-disk checks stat, keeping doctor
+// quick on large libraries.
+const doctorSampleSize = 20
+
+// doctorCheck is one line of the doctor checklist.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// runDoctor runs the onboarding checks a new setup usually gets wrong and
+// prints a checklist with a hint for each problem. It exits 1 when any check
+// fails.
+func runDoctor(args []string) {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := configFlag(flags)
+	jsonOutput := flags.Bool("json", false, "Emit the checklist as JSON")
+	_ = flags.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	checks := doctorChecks(ctx, *configPath)
+
+	if *jsonOutput {
+		out, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode checks: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, c := range checks {
+			fmt.Printf("[%s] %s: %s\n", strings.ToUpper(c.Status), c.Name, c.Detail)
+			if c.Hint != "" {
+				fmt.Printf("       %s\n", c.Hint)
+			}
+		}
+	}
+
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			os.Exit(1)
+		}
+	}
+}
+
+// doctorChecks runs every check in order. Later checks need a loaded config,
+// so a config that doesn't load is the only result.
+func doctorChecks(ctx context.Context, configPath string) []doctorCheck {
+	path, err := config.Locate(configPath)
+	if err == nil {
+		var cfg *config.Config
+		if cfg, err = config.Load(path); err == nil {
+			checks := []doctorCheck{{Name: "config", Status: doctorPass, Detail: "loaded " + path}}
+			return append(checks, doctorConfigChecks(ctx, cfg)...)
+		}
+	}
+	return []doctorCheck{{
+		Name:   "config",
+		Status: doctorFail,
+		Detail: err.Error(),
+		Hint:   "Start from config.example.toml and pass its location with --config.",
+	}}
+}
+
+func doctorConfigChecks(ctx context.Context, cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+
+	// Root folders come first: with api_root set they add path mappings
+	// that the Arr file checks below rely on.
+	resolveExcludedRootFolders(ctx, cfg)
+
+	var arrFiles []models.ArrFile
+	services := configuredArrServices(cfg)
+	if len(services) == 0 {
+		checks = append(checks, doctorCheck{
+			Name:   "services",
+			Status: doctorWarn,
+			Detail: "no Arr services configured",
+			Hint:   "Without [sonarr], [radarr] or [[arr]] every file is reported as orphaned.",
+		})
+	}
+	for _, svc := range services {
+		check := doctorCheck{Name: svc.name, Status: doctorPass, Detail: "reachable at " + svc.cfg.URL}
+		if err := svc.collector.TestConnection(ctx); err != nil {
+			check.Status = doctorFail
+			check.Detail = fmt.Sprintf("%s: %v", collectors.FailureReason(err), err)
+			check.Hint = "Check the url and api_key, and that this host can reach the service."
+			checks = append(checks, check)
+			continue
+		}
+		files, err := svc.collector.Collect(ctx)
+		if err != nil {
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("reachable, but fetching tracked files failed: %v", err)
+			check.Hint = "Large libraries may need scan --arr-timeout-per-request above the 30s default."
+		}
+		arrFiles = append(arrFiles, files...)
+		checks = append(checks, check)
+	}
+	if cfg.Qbittorrent.URL != "" {
+		check := doctorCheck{Name: "qBittorrent", Status: doctorPass, Detail: "reachable at " + cfg.Qbittorrent.URL}
+		if err := newQBCollector(cfg).TestConnection(ctx); err != nil {
+			check.Status = doctorFail
+			check.Detail = fmt.Sprintf("%s: %v", collectors.FailureReason(err), err)
+			check.Hint = "Check the url, username and password, and that the WebUI allows this host."
+		}
+		checks = append(checks, check)
+	}
+
+	checks = append(checks, doctorPathMappings(cfg, arrFiles))
+	checks = append(checks, doctorMediaRoot(cfg))
+	checks = append(checks, doctorHardlinks(cfg))
+	checks = append(checks, doctorReportDirs(cfg)...)
+	if len(arrFiles) > 0 {
+		checks = append(checks, doctorArrSample(cfg, arrFiles))
+	}
+	return checks
+}
+
+func doctorPathMappings(cfg *config.Config, arrFiles []models.ArrFile) doctorCheck {
+	arrPaths := make([]string, 0, len(arrFiles))
+	for _, f := range arrFiles {
+		arrPaths = append(arrPaths, f.Path)
+	}
+	if warnings := cfg.PathMappingWarnings(arrPaths); len(warnings) > 0 {
+		return doctorCheck{
+			Name:   "path mappings",
+			Status: doctorFail,
+			Detail: strings.Join(warnings, "; "),
+			Hint:   "Map each path prefix the Arr services report to where it is mounted here, in [path_mappings] or with api_root and fs_root.",
+		}
+	}
+	detail := fmt.Sprintf("%d mapping(s), all targets exist", len(cfg.PathMappings))
+	if cfg.DefaultPathMappings {
+		detail += " (built-in /data defaults)"
+	}
+	return doctorCheck{Name: "path mappings", Status: doctorPass, Detail: detail}
+}
+
+func doctorMediaRoot(cfg *config.Config) doctorCheck {
+	media, _ := cfg.Paths.ScanRoots()
+	files, err := sampleMediaFiles(media, 1)
+	switch {
+	case err != nil:
+		return doctorCheck{
+			Name:   "media root",
+			Status: doctorFail,
+			Detail: err.Error(),
+			Hint:   "Check that media_root is mounted and readable by this user.",
+		}
+	case len(files) == 0:
+		return doctorCheck{
+			Name:   "media root",
+			Status: doctorFail,
+			Detail: "no media files under " + media,
+			Hint:   "media_root should be the library directory itself, not an empty mount point.",
+		}
+	}
+	return doctorCheck{Name: "media root", Status: doctorPass, Detail: "media files found under " + media}
+}
+
+// doctorHardlinks checks that hardlinks can exist between the roots and that
+// stat reports link counts: with none above one in a sample of library
+// files, the mount usually hides them.
+func doctorHardlinks(cfg *config.Config) doctorCheck {
+	media, torrent := cfg.Paths.ScanRoots()
+	check := doctorCheck{Name: "hardlinks", Status: doctorPass}
+	if torrent != "" {
+		same, err := utils.SameDevice(media, torrent)
+		switch {
+		case err != nil:
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("could not compare media and torrent filesystems: %v", err)
+			return check
+		case !same:
+			check.Status = doctorWarn
+			check.Detail = "media_root and torrent_root are on different filesystems, so no file can be hardlinked between them"
+			check.Hint = "Put both under one mount (e.g. /data) if you use hardlinks; otherwise healthy vs at-risk is not meaningful."
+			return check
+		}
+	}
+
+	files, err := sampleMediaFiles(media, doctorSampleSize)
+	if err != nil || len(files) == 0 {
+		check.Status = doctorWarn
+		check.Detail = "no media files to check link counts on"
+		return check
+	}
+	for _, f := range files {
+		var stat syscall.Stat_t
+		if syscall.Stat(f, &stat) == nil && stat.Nlink > 1 {
+			check.Detail = "link counts are visible (found " + f + ")"
+			return check
+		}
+	}
+	check.Status = doctorWarn
+	check.Detail = fmt.Sprintf("none of %d sampled library files has more than one link", len(files))
+	check.Hint = "If your downloads are hardlinked, this mount hides link counts (common with SMB and some FUSE filesystems); scan where the files are stored instead."
+	return check
+}
+
+// doctorReportDirs checks that each report directory, or the nearest
+// existing parent the reporters would create it under, is writable.
+func doctorReportDirs(cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+	for _, dir := range cfg.GetReportPaths() {
+		check := doctorCheck{Name: "report dir", Status: doctorPass, Detail: dir + " is writable"}
+		existing := dir
+		for {
+			if _, err := os.Stat(existing); err == nil || filepath.Dir(existing) == existing {
+				break
+			}
+			existing = filepath.Dir(existing)
+		}
+		f, err := os.CreateTemp(existing, ".auditarr-doctor-*")
+		if err != nil {
+			check.Status = doctorFail
+			check.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+			check.Hint = "Give this user write access or point outputs.report_dir elsewhere."
+		} else {
+			f.Close()
+			os.Remove(f.Name())
+			if existing != dir {
+				check.Detail = fmt.Sprintf("%s will be created under %s", dir, existing)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// doctorArrSample stats an evenly spaced sample of the files the Arr
+// services track, after path mapping, to confirm they are found on disk.
+func doctorArrSample(cfg *config.Config, arrFiles []models.ArrFile) doctorCheck {
+	step := max(len(arrFiles)/doctorSampleSize, 1)
+	var sampled int
+	var missing []string
+	for i := 0; i < len(arrFiles) && sampled < doctorSampleSize; i += step {
+		sampled++
+		local := utils.NormalizePath(arrFiles[i].Path, cfg.PathMappings)
+		if _, err := os.Stat(local); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (looked for %s)", arrFiles[i].Path, local))
+		}
+	}
+
+	check := doctorCheck{Name: "arr files on disk", Status: doctorPass, Detail: fmt.Sprintf("%d of %d sampled files found", sampled-len(missing), sampled)}
+	switch {
+	case len(missing) == sampled:
+		check.Status = doctorFail
+		check.Detail += "; e.g. " + missing[0]
+		check.Hint = "The path mappings don't lead to the files; compare the Arr path with where it is mounted here."
+	case len(missing) > 0:
+		check.Status = doctorWarn
+		check.Detail += "; e.g. " + missing[0]
+		check.Hint = "A few missing files are normal after deletes; many point to a mapping that covers only some root folders."
+	}
+	return check
+}
+
+// sampleMediaFiles returns up to limit media files under root in walk order.
+// Unreadable subdirectories are skipped; only an unreadable root is an error.
+func sampleMediaFiles(root string, limit int) ([]string, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && utils.IsMediaFile(path) {
+			files = append(files, path)
+			if len(files) >= limit {
+				return fs.SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.SkipAll) {
+		return files, err
+	}
+	return files, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestDoctorPathMappings(t *testing.T) {
+	media := t.TempDir()
+	arrFiles := []models.ArrFile{{Path: "/tv/Show/S01E01.mkv"}}
+
+	cfg := &config.Config{PathMappings: map[string]string{"/tv": media}}
+	if check := doctorPathMappings(cfg, arrFiles); check.Status != doctorPass {
+		t.Errorf("existing target: %+v, want pass", check)
+	}
+
+	cfg = &config.Config{PathMappings: map[string]string{"/tv": filepath.Join(media, "missing")}}
+	if check := doctorPathMappings(cfg, arrFiles); check.Status != doctorFail || !strings.Contains(check.Detail, "does not exist") {
+		t.Errorf("missing target: %+v, want fail", check)
+	}
+
+	cfg = &config.Config{
+		PathMappings:        map[string]string{"/data/media": media},
+		DefaultPathMappings: true,
+	}
+	if check := doctorPathMappings(cfg, arrFiles); check.Status != doctorFail || !strings.Contains(check.Detail, "none of the 1 Arr paths") {
+		t.Errorf("defaults with unmapped Arr paths: %+v, want fail", check)
+	}
+}
+
+func TestDoctorReportDirs(t *testing.T) {
+	existing := t.TempDir()
+	nested := filepath.Join(existing, "reports", "daily")
+
+	cfg := &config.Config{}
+	cfg.Outputs.ReportDir = []string{existing, nested}
+	checks := doctorReportDirs(cfg)
+	if len(checks) != 2 {
+		t.Fatalf("checks = %+v, want one per report dir", checks)
+	}
+	if checks[0].Status != doctorPass || checks[0].Detail != existing+" is writable" {
+		t.Errorf("existing dir: %+v, want pass", checks[0])
+	}
+	if checks[1].Status != doctorPass || checks[1].Detail != nested+" will be created under "+existing {
+		t.Errorf("missing dir: %+v, want pass under its nearest parent", checks[1])
+	}
+	if entries, _ := os.ReadDir(existing); len(entries) != 0 {
+		t.Errorf("doctor left files behind: %v", entries)
+	}
+
+	// A regular file in the way fails the temp-file probe even for root,
+	// unlike a read-only directory.
+	blocker := filepath.Join(t.TempDir(), "reports")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Outputs.ReportDir = []string{filepath.Join(blocker, "daily")}
+	if checks := doctorReportDirs(cfg); checks[0].Status != doctorFail || !strings.Contains(checks[0].Detail, "not writable") {
+		t.Errorf("unwritable dir: %+v, want fail", checks[0])
+	}
+}
+
+func TestDoctorArrSample(t *testing.T) {
+	media := t.TempDir()
+	for _, name := range []string{"a.mkv", "b.mkv"} {
+		if err := os.WriteFile(filepath.Join(media, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{PathMappings: map[string]string{"/tv": media}}
+	arrFiles := func(names ...string) []models.ArrFile {
+		files := make([]models.ArrFile, len(names))
+		for i, name := range names {
+			files[i] = models.ArrFile{Path: "/tv/" + name}
+		}
+		return files
+	}
+
+	for _, tc := range []struct {
+		name   string
+		files  []models.ArrFile
+		status string
+		detail string
+	}{
+		{"all found", arrFiles("a.mkv", "b.mkv"), doctorPass, "2 of 2 sampled files found"},
+		{"some missing", arrFiles("a.mkv", "gone.mkv"), doctorWarn, "1 of 2 sampled files found; e.g. /tv/gone.mkv"},
+		{"mapping mismatch", arrFiles("x.mkv", "y.mkv"), doctorFail, "0 of 2 sampled files found; e.g. /tv/x.mkv"},
+	} {
+		check := doctorArrSample(cfg, tc.files)
+		if check.Status != tc.status || !strings.HasPrefix(check.Detail, tc.detail) {
+			t.Errorf("%s: %+v, want %s with %q", tc.name, check, tc.status, tc.detail)
+		}
+	}
+
+	if check := doctorArrSample(cfg, arrFiles("x.mkv")); check.Hint == "" || !strings.Contains(check.Hint, "path mappings") {
+		t.Errorf("mismatch hint = %q, want it to point at the path mappings", check.Hint)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/collectors"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// runExplain classifies a single file the way scan would and prints each
+// step, to debug path mappings and unexpected classifications.
+func runExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	configPath := configFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: auditarr explain <path> [--config path]")
+		fs.PrintDefaults()
+	}
+
+	// Allow the path to come before the flags, as with ack.
+	var target string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		target, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+	if target == "" {
+		target = fs.Arg(0)
+	}
+	if target == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(*configPath, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	rules, err := compileRules(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	excludedRoots := resolveExcludedRootFolders(ctx, cfg)
+	fsCollector := collectors.NewFilesystemCollector(cfg.Paths.MediaRoot, cfg.Paths.TorrentRoot, cfg.Paths.ExtraScanPaths)
+	fsCollector.SetExcludePaths(excludedRoots)
+
+	media, err := fsCollector.CollectFile(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot explain %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	var siblings []models.MediaFile
+	if media.IsSidecar {
+		entries, _ := os.ReadDir(filepath.Dir(media.Path))
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if f, err := fsCollector.CollectFile(filepath.Join(filepath.Dir(media.Path), entry.Name())); err == nil && !f.IsSidecar {
+				siblings = append(siblings, f)
+			}
+		}
+	}
+
+	arrFiles, _ := collectArrFiles(ctx, cfg, excludedRoots, false)
+
+	var torrents []models.Torrent
+	if media.Source == models.MediaSourceTorrent {
+		var qbStatus *analysis.ServiceStatus
+		torrents, qbStatus, _ = collectTorrents(ctx, cfg, false)
+		if qbStatus != nil && !qbStatus.OK {
+			fmt.Fprintln(os.Stderr, "Warning: no torrent data, so the active-torrent check is unreliable")
+		}
+	}
+
+	engine := newEngine(cfg, false, rules, false)
+	sonarrFiles, otherArrFiles := arrFiles.engineInputs()
+	printExplanation(engine.Explain(media, siblings, sonarrFiles, otherArrFiles, torrents))
+}
+
+func printExplanation(ex *analysis.Explanation) {
+	m := ex.Media
+	fmt.Printf("\nFile:        %s\n", m.Path)
+	fmt.Printf("Source:      %s\n", m.Source)
+	fmt.Printf("Modified:    %s (%s ago)\n", m.ModTime.Format(time.RFC3339), time.Since(m.ModTime).Round(time.Minute))
+	fmt.Printf("Hardlinks:   %d (hardlinked: %t)\n", m.HardlinkCount, m.IsHardlinked)
+	fmt.Printf("Lookup key:  %s\n", ex.LookupKey)
+
+	if ex.Skipped {
+		fmt.Println("\nThe file is under permissions.skip_paths, so the audit ignores it.")
+		return
+	}
+
+	if ex.Sidecar {
+		fmt.Println("\nSidecar:     yes (judged by a matching video in the same directory, not by Arr)")
+	} else {
+		fmt.Println()
+		switch {
+		case ex.Match != nil:
+			fmt.Printf("Arr match:   %s (%s)\n", ex.Match.Path, arrRecordLabel(ex.Match))
+		default:
+			fmt.Println("Arr match:   none")
+		}
+		if len(ex.Candidates) > 1 {
+			fmt.Printf("             %d records share this lookup key; picked the one whose mapped path matches exactly:\n", len(ex.Candidates))
+			for _, c := range ex.Candidates {
+				fmt.Printf("               %s -> %s\n", c.ArrPath, c.MappedPath)
+			}
+		}
+		if ex.Match == nil && len(ex.NearMisses) > 0 {
+			fmt.Println("             Arr tracks a file with the same name elsewhere; check [path_mappings]:")
+			for _, c := range ex.NearMisses {
+				fmt.Printf("               %s -> %s (%s)\n", c.ArrPath, c.MappedPath, arrRecordLabel(&c.File))
+			}
+		}
+		if m.Source == models.MediaSourceTorrent {
+			fmt.Printf("In torrent:  %t (still managed by qBittorrent)\n", ex.InActiveTorrent)
+		}
+		if ex.GraceHours > 0 {
+			fmt.Printf("Grace:       %dh window, within grace: %t\n", ex.GraceHours, ex.WithinGrace)
+		} else {
+			fmt.Println("Grace:       none applies")
+		}
+		if ex.RuleName != "" {
+			fmt.Printf("Rule:        matched custom rule %q\n", ex.RuleName)
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case ex.Suppressed:
+		fmt.Println("Result:      suppressed by the baseline (acknowledged)")
+	case ex.Classified == nil:
+		fmt.Println("Result:      not reported (within grace window, ignored by a rule, or an untracked subtitle)")
+	default:
+		fmt.Printf("Result:      %s [%s]\n", ex.Classified.Classification, ex.Classified.Code)
+		fmt.Printf("Reason:      %s\n", ex.Classified.Reason)
+	}
+}
+
+func arrRecordLabel(f *models.ArrFile) string {
+	service := f.Service
+	switch {
+	case service != "":
+	case f.SeriesID > 0:
+		service = "sonarr"
+	case f.MovieID > 0:
+		service = "radarr"
+	default:
+		service = "arr"
+	}
+	return fmt.Sprintf("%s, monitored: %t", service, f.Monitored)
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -22,12 +27,33 @@ func main() {
 		fmt.Fprintln(os.Stderr, "Usage: auditarr <command> [options]")
 		fmt.Fprintln(os.Stderr, "Commands:")
 		fmt.Fprintln(os.Stderr, "  scan    Run one-time audit")
+		fmt.Fprintln(os.Stderr, "  watch   Run audits continuously on an interval")
+		fmt.Fprintln(os.Stderr, "  health  Check connectivity to configured services")
+		fmt.Fprintln(os.Stderr, "  doctor  Check the config, services, path mappings, hardlinks and report dir, with hints")
+		fmt.Fprintln(os.Stderr, "  ack     Acknowledge a finding so future scans suppress it")
+		fmt.Fprintln(os.Stderr, "  explain Show why a file was classified the way it was")
+		fmt.Fprintln(os.Stderr, "  apply-permissions  Fix reported ownership and modes in place (requires --yes)")
+		fmt.Fprintln(os.Stderr, "  capabilities  List built-in collectors and notifiers and which the config enables (alias --list-collectors)")
 		os.Exit(1)
 	}
 
 	switch os.Args[1] {
 	case "scan":
 		runScan(os.Args[2:])
+	case "watch":
+		runWatch(os.Args[2:])
+	case "health":
+		runHealth(os.Args[2:])
+	case "doctor":
+		runDoctor(os.Args[2:])
+	case "ack":
+		runAck(os.Args[2:])
+	case "explain":
+		runExplain(os.Args[2:])
+	case "apply-permissions":
+		runApplyPermissions(os.Args[2:])
+	case "capabilities", "--list-collectors":
+		runCapabilities(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
 		os.Exit(1)
@@ -36,12 +62,18 @@ func main() {
 
 func runScan(args []string) {
 	fs := flag.NewFlagSet("scan", flag.ExitOnError)
-	configPath := fs.String("config", "/etc/auditarr/config.toml", "Path to configuration file")
-	verbose := fs.Bool("verbose", false, "Enable verbose output")
-	skipPermissions := fs.Bool("skip-permissions", false, "Skip permission auditing")
+	var configPaths configList
+	fs.Var(&configPaths, "config", "Path, glob or directory (its *.toml files) of configuration files, or - to read it from stdin; repeat to audit several stacks in turn (default: first found of ./auditarr.toml, $XDG_CONFIG_HOME/auditarr/config.toml, ~/.config/auditarr/config.toml, /etc/auditarr/config.toml)")
+	arrOnly := fs.Bool("arr-only", false, "Only check that files tracked by each Arr service exist on disk, skipping the full audit")
+	opts := bindScanFlags(fs)
+	fs.BoolVar(&opts.resume, "resume", false, "Continue an interrupted scan from [paths].checkpoint_file, skipping directories it already finished")
+	fs.StringVar(&opts.compareBaseline, "compare-baseline", "", "Compare findings with this earlier JSON report and exit 2 only on findings it doesn't list, printing just those")
+	fs.BoolVar(&opts.strict, "strict", false, "Exit 1 when any collection was incomplete (failed walk, unreachable or degraded service, skipped fetches) instead of reporting findings from partial data")
 	_ = fs.Parse(args)
+	validateGroupBy(opts.groupBy)
+	validateFingerprint(opts.fingerprint)
 
-	cfg, err := config.Load(*configPath)
+	paths, err := configPaths.expand()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 		os.Exit(1)
@@ -58,121 +90,1004 @@ func runScan(args []string) {
 		cancel()
 	}()
 
+	if len(paths) == 1 {
+		code, _ := scanConfig(ctx, paths[0], *arrOnly, *opts)
+		os.Exit(code)
+	}
+	if err := checkBatchFlags(paths, *opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	worst := 0
+	var outcomes []string
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			outcomes = append(outcomes, fmt.Sprintf("%s: skipped (interrupted)", path))
+			worst = worseExitCode(worst, 1)
+			continue
+		}
+		fmt.Printf("=== %s ===\n", path)
+		code, outcome := scanConfig(ctx, path, *arrOnly, *opts)
+		worst = worseExitCode(worst, code)
+		outcomes = append(outcomes, fmt.Sprintf("%s: %s (exit %d)", path, outcome, code))
+	}
+
+	fmt.Printf("Scanned %d configs:\n", len(paths))
+	for _, o := range outcomes {
+		fmt.Printf("  %s\n", o)
+	}
+	os.Exit(worst)
+}
+
+type connectionTester interface {
+	TestConnection(ctx context.Context) error
+}
+
+type healthCheck struct {
+	name   string
+	tester connectionTester
+}
+
+func runHealth(args []string) {
+	fs := flag.NewFlagSet("health", flag.ExitOnError)
+	configPath := configFlag(fs)
+	jsonOutput := fs.Bool("json", false, "Emit service status as JSON")
+	_ = fs.Parse(args)
+
+	cfg, err := loadConfig(*configPath, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	statuses := checkHealth(ctx, cfg)
+	healthy := true
+	for _, status := range statuses {
+		healthy = healthy && status.OK
+	}
+
+	if *jsonOutput {
+		out, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode status: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, status := range statuses {
+			if status.OK {
+				fmt.Printf("[%s] OK\n", status.Name)
+			} else {
+				fmt.Printf("[%s] FAILED (%s): %s\n", status.Name, status.Reason, status.Error)
+			}
+		}
+	}
+
+	if !healthy {
+		os.Exit(1)
+	}
+}
+
+// checkHealth tests the connection to every configured service.
+func checkHealth(ctx context.Context, cfg *config.Config) []analysis.ServiceStatus {
+	var checks []healthCheck
+	for _, svc := range configuredArrServices(cfg) {
+		checks = append(checks, healthCheck{svc.name, svc.collector})
+	}
+	if cfg.Qbittorrent.URL != "" {
+		checks = append(checks, healthCheck{"qBittorrent", newQBCollector(cfg)})
+	}
+	if cfg.Qbittorrent.BackupDir != "" {
+		checks = append(checks, healthCheck{"qBittorrent (backup)", collectors.NewFastresumeCollector(cfg.Qbittorrent.BackupDir)})
+	}
+
+	statuses := make([]analysis.ServiceStatus, 0, len(checks))
+	for _, check := range checks {
+		status := analysis.ServiceStatus{Name: check.name, Enabled: true, OK: true}
+		if err := check.tester.TestConnection(ctx); err != nil {
+			status.OK = false
+			status.Error = err.Error()
+			status.Reason = collectors.FailureReason(err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func runAck(args []string) {
+	fs := flag.NewFlagSet("ack", flag.ExitOnError)
+	configPath := configFlag(fs)
+	reason := fs.String("reason", "", "Why this finding is acceptable")
+	expire := fs.String("expire", "", "Lapse the acknowledgment after this duration (e.g. 72h, 30d)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: auditarr ack <path> [--reason text] [--expire duration]")
+		fs.PrintDefaults()
+	}
+
+	// Allow the path to come before the flags: auditarr ack <path> --reason ...
+	var target string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		target, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+	if target == "" {
+		target = fs.Arg(0)
+	}
+	if target == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(*configPath, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Analysis.BaselineFile == "" {
+		fmt.Fprintln(os.Stderr, "analysis.baseline_file must be set in the config to acknowledge findings")
+		os.Exit(1)
+	}
+
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve path %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	entry := analysis.BaselineEntry{
+		Path:    absTarget,
+		Reason:  *reason,
+		AddedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if *expire != "" {
+		d, err := parseExpiry(*expire)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --expire value: %v\n", err)
+			os.Exit(1)
+		}
+		entry.ExpiresAt = entry.AddedAt.Add(d)
+	}
+
+	baseline, err := analysis.LoadBaseline(cfg.Analysis.BaselineFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load baseline: %v\n", err)
+		os.Exit(1)
+	}
+	baseline.Add(entry)
+	if err := baseline.Save(cfg.Analysis.BaselineFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	if entry.ExpiresAt.IsZero() {
+		fmt.Printf("Acknowledged %s\n", absTarget)
+	} else {
+		fmt.Printf("Acknowledged %s until %s\n", absTarget, entry.ExpiresAt.Format(time.RFC3339))
+	}
+}
+
+// parseExpiry extends time.ParseDuration with a "d" (day) suffix.
+func parseExpiry(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid day count %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
+
+// checkBatchFlags rejects options that can't apply to several configs at
+// once: fixed output paths every stack would overwrite (and read back as its
+// previous run), and stdin, which can only be read once.
+func checkBatchFlags(paths []string, opts scanOptions) error {
+	for _, path := range paths {
+		if path == "-" {
+			return fmt.Errorf("--config - reads stdin, which can only be used with a single config")
+		}
+	}
+	for _, f := range []struct{ name, value string }{
+		{"--report-file", opts.reportFile},
+		{"--dump-raw", opts.dumpRaw},
+		{"--dump-permissions", opts.dumpPermissions},
+	} {
+		if f.value != "" {
+			return fmt.Errorf("%s can only be used with a single config, since every config would write to the same path", f.name)
+		}
+	}
+	return nil
+}
+
+// configList collects repeated --config flags.
+type configList []string
+
+func (c *configList) String() string { return strings.Join(*c, ",") }
+
+func (c *configList) Set(v string) error {
+	*c = append(*c, v)
+	return nil
+}
+
+// expand resolves globs and directories (their *.toml files) in order,
+// dropping duplicates. With no --config it returns a single "" so
+// config.Locate searches the default locations.
+func (c configList) expand() ([]string, error) {
+	if len(c) == 0 {
+		return []string{""}, nil
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range c {
+		matches := []string{pattern}
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			matches, _ = filepath.Glob(filepath.Join(pattern, "*.toml"))
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no *.toml config files in %s", pattern)
+			}
+		} else if strings.ContainsAny(pattern, "*?[") {
+			matches, err = filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid config glob %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no config files match %q", pattern)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				paths = append(paths, m)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// worseExitCode picks the more severe of two scan exit codes: a config that
+// couldn't be audited (1) outranks findings (2), which outrank a clean run.
+func worseExitCode(a, b int) int {
+	rank := func(code int) int {
+		switch code {
+		case 0:
+			return 0
+		case 2:
+			return 1
+		default:
+			return 2
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// scanConfig runs one audit (or Arr-only check) for the config at path and
+// returns its exit code and a one-line outcome for the batch summary.
+func scanConfig(ctx context.Context, path string, arrOnly bool, opts scanOptions) (int, string) {
+	cfg, err := loadScanConfig(path, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1, "failed to load config"
+	}
+	if opts.resume && cfg.Paths.CheckpointFile == "" {
+		fmt.Fprintln(os.Stderr, "--resume requires [paths].checkpoint_file")
+		return 1, "no checkpoint_file for --resume"
+	}
+
+	rules, err := compileRules(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1, "failed to load config"
+	}
+
+	if arrOnly {
+		arrCtx, arrCancel := phaseContext(ctx, opts.servicesTimeout)
+		defer arrCancel()
+		code := runArrCheck(arrCtx, cfg, opts.verbose)
+		if code == 0 {
+			return code, "all tracked files present"
+		}
+		return code, "tracked files missing"
+	}
+
+	var baseline []reporting.Finding
+	if opts.compareBaseline != "" {
+		if baseline, err = reporting.LoadFindings(opts.compareBaseline); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load baseline report: %v\n", err)
+			return 1, "failed to load baseline report"
+		}
+	}
+
+	opts.rules = rules
+	result := runAudit(ctx, cfg, opts)
+	s := result.Summary
+	outcome := fmt.Sprintf("%d healthy, %d at risk, %d orphaned media, %d orphaned downloads", s.HealthyCount, s.AtRiskCount, s.OrphanCount, s.OrphanedDownloadCount)
+	if failed := result.FailedServices(); len(failed) > 0 {
+		outcome += "; unreachable: " + strings.Join(failed, ", ")
+	}
+	if opts.strict {
+		if problems := result.IncompleteCollection(); len(problems) > 0 {
+			fmt.Fprintf(os.Stderr, "Strict: collection was incomplete, so this audit can't be trusted:\n")
+			for _, p := range problems {
+				fmt.Fprintf(os.Stderr, "  %s\n", p)
+			}
+			return 1, fmt.Sprintf("%s; incomplete collection (%d problem(s))", outcome, len(problems))
+		}
+	}
+	if opts.compareBaseline != "" {
+		added := reporting.NewFindings(reporting.ResultFindings(result), baseline)
+		return regressionExitCode(added, opts.compareBaseline), fmt.Sprintf("%s; %d new since baseline", outcome, len(added))
+	}
+	return auditExitCode(result, cfg.Notifications), outcome
+}
+
+// regressionExitCode prints the findings absent from the baseline report and
+// returns 2 when there are any, so CI fails only on new findings.
+func regressionExitCode(added []reporting.Finding, baselinePath string) int {
+	if len(added) == 0 {
+		fmt.Printf("No new findings since %s\n", baselinePath)
+		return 0
+	}
+	fmt.Printf("%d new finding(s) since %s:\n", len(added), baselinePath)
+	for _, f := range added {
+		fmt.Printf("  %s: %s\n", f.Section, f.Path)
+	}
+	return 2
+}
+
+type scanOptions struct {
+	verbose         bool
+	skipPermissions bool
+	verifyMedia     bool
+	dumpPermissions string
+	dumpRaw         string
+	quiet           bool
+	reportFile      string
+	groupBy         string
+	label           string
+	fingerprint     string
+	maxDepth        int
+	resume          bool
+	compareBaseline string
+	strict          bool
+	servicesTimeout time.Duration
+	arrTimeout      time.Duration
+	arrMaxFailures  int
+	fsTimeout       time.Duration
+	progressEvery   time.Duration
+	rules           []analysis.ClassificationRule
+	events          *reporting.EventLog
+	quietHours      *quietHours
+	// watchdog is beaten as the audit makes progress, under watch.
+	watchdog *utils.Watchdog
+}
+
+// bindScanFlags registers the audit flags shared by scan and watch.
+func bindScanFlags(fs *flag.FlagSet) *scanOptions {
+	opts := &scanOptions{}
+	fs.BoolVar(&opts.verbose, "verbose", false, "Enable verbose output")
+	fs.BoolVar(&opts.skipPermissions, "skip-permissions", false, "Skip the permission audit rules")
+	fs.BoolVar(&opts.verifyMedia, "verify-media", false, "Probe media files with ffprobe to detect corrupt containers")
+	fs.StringVar(&opts.reportFile, "report-file", "", "Write the markdown report to this exact path (JSON alongside with a .json extension) instead of a timestamped file in report_dir")
+	fs.StringVar(&opts.groupBy, "group-by", "", "Group at-risk and orphaned findings in the report by \"dir\" or \"show\"")
+	fs.StringVar(&opts.label, "label", "", "Label for this run (e.g. \"nightly-prod\"), shown in the report header, JSON run_label and notification title")
+	fs.StringVar(&opts.fingerprint, "fingerprint", "", "Record per-file fingerprints in the JSON report: \"stat\", \"sample\" or \"full\" (overrides [outputs].fingerprint)")
+	fs.IntVar(&opts.maxDepth, "max-depth", 0, "Walk at most this many levels below each root (overrides [paths].max_depth; 0 uses the config)")
+	fs.DurationVar(&opts.servicesTimeout, "services-timeout", 0, "Time limit for each Arr/qBittorrent collection phase (0 = no limit)")
+	fs.DurationVar(&opts.arrTimeout, "arr-timeout-per-request", 0, "Timeout for each request to an Arr service (0 = 30s)")
+	fs.IntVar(&opts.arrMaxFailures, "arr-max-failures", 5, "Stop requesting from an Arr service after this many consecutive failed requests and mark it degraded (0 = never)")
+	fs.DurationVar(&opts.fsTimeout, "fs-timeout", 0, "Time limit for the filesystem walk (0 = no limit)")
+	fs.BoolVar(&opts.quiet, "quiet", false, "Suppress progress output")
+	fs.DurationVar(&opts.progressEvery, "progress-interval", 5*time.Second, "How often to print scan progress when attached to a terminal")
+	fs.StringVar(&opts.dumpPermissions, "dump-permissions", "", "Write the raw collected permission data as JSON to this file (collects even when the audit is disabled)")
+	fs.StringVar(&opts.dumpRaw, "dump-raw", "", "Write what each collector returned (media files, Arr files per service, torrents) as JSON files to this directory before analysis")
+	return opts
+}
+
+// configFlag registers --config. Without it, config.Locate searches the
+// default locations.
+func configFlag(fs *flag.FlagSet) *string {
+	return fs.String("config", "", "Path to configuration file, or - to read it from stdin (default: first found of ./auditarr.toml, $XDG_CONFIG_HOME/auditarr/config.toml, ~/.config/auditarr/config.toml, /etc/auditarr/config.toml)")
+}
+
+// loadConfig locates and loads the config, naming the file under verbose.
+func loadConfig(path string, verbose bool) (*config.Config, error) {
+	path, err := config.Locate(path)
+	if err != nil {
+		return nil, err
+	}
+	if verbose {
+		fmt.Printf("Using config: %s\n", path)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if notice := cfg.DefaultPathMappingsNotice(); notice != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", notice)
+	}
+	return cfg, nil
+}
+
+// loadScanConfig loads the config at path and applies the scan flags that
+// override it, for both scan and watch, including the guard against
+// --report-file landing inside a scanned root.
+func loadScanConfig(path string, opts scanOptions) (*config.Config, error) {
+	cfg, err := loadConfig(path, opts.verbose)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTP.ArrRequestTimeout = opts.arrTimeout
+	cfg.HTTP.ArrMaxFailures = opts.arrMaxFailures
+	if opts.reportFile != "" {
+		if err := cfg.CheckReportFileOverlap("--report-file", opts.reportFile); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// validateFingerprint exits if --fingerprint is not a supported mode.
+func validateFingerprint(mode string) {
+	switch mode {
+	case "", collectors.FingerprintStat, collectors.FingerprintSample, collectors.FingerprintFull:
+	default:
+		fmt.Fprintf(os.Stderr, "--fingerprint must be one of %s\n", strings.Join(collectors.FingerprintModes, ", "))
+		os.Exit(1)
+	}
+}
+
+// validateGroupBy exits if --group-by is not a supported mode.
+func validateGroupBy(mode string) {
+	switch mode {
+	case "", reporting.GroupByDir, reporting.GroupByShow:
+	default:
+		fmt.Fprintf(os.Stderr, "--group-by must be %q or %q\n", reporting.GroupByDir, reporting.GroupByShow)
+		os.Exit(1)
+	}
+}
+
+// runAudit performs a single collection, analysis and reporting pass.
+func runAudit(ctx context.Context, cfg *config.Config, opts scanOptions) *analysis.AnalysisResult {
 	startTime := time.Now()
+	opts.events.RunStarted(opts.label)
 
-	if *verbose {
+	if opts.verbose {
 		fmt.Println("Starting media audit...")
 	}
 
 	fsCollector := collectors.NewFilesystemCollector(cfg.Paths.MediaRoot, cfg.Paths.TorrentRoot, cfg.Paths.ExtraScanPaths)
+	fsCollector.SetFollowSymlinks(cfg.Paths.FollowSymlinks)
+	fsCollector.SetIgnoreMarkers(cfg.Paths.IgnoreMarkers)
+	fsCollector.SetWalkRetries(cfg.Paths.WalkRetries)
+	fsCollector.SetVerbose(opts.verbose)
+	if cfg.Paths.SnapshotMediaRoot != "" {
+		fsCollector.SetSnapshotRoot(cfg.Paths.MediaRoot, cfg.Paths.SnapshotMediaRoot)
+	}
+	if cfg.Paths.SnapshotTorrentRoot != "" {
+		fsCollector.SetSnapshotRoot(cfg.Paths.TorrentRoot, cfg.Paths.SnapshotTorrentRoot)
+	}
+	maxDepth := cfg.Paths.MaxDepth
+	if opts.maxDepth > 0 {
+		maxDepth = opts.maxDepth
+	}
+	fsCollector.SetMaxDepth(maxDepth)
+	fsCollector.SetMinFileAge(time.Duration(cfg.Paths.MinFileAgeSeconds) * time.Second)
 
-	if *verbose {
+	rootsCtx, rootsCancel := phaseContext(ctx, opts.servicesTimeout)
+	excludedRoots, arrUnmapped, unmappedChecked := resolveRootFolders(rootsCtx, cfg, true)
+	rootsCancel()
+	if len(excludedRoots) > 0 {
+		fsCollector.SetExcludePaths(excludedRoots)
+		if opts.verbose {
+			fmt.Printf("Excluding Arr root folders: %v\n", excludedRoots)
+		}
+	}
+
+	if opts.verbose {
 		fmt.Println("Collecting filesystem data...")
 	}
 
-	mediaFiles, err := fsCollector.Collect(ctx)
+	var checkpoint *collectors.Checkpoint
+	if cfg.Paths.CheckpointFile != "" {
+		checkpoint = collectors.NewCheckpoint(cfg.Paths.CheckpointFile)
+		if opts.resume {
+			cp, err := collectors.LoadCheckpoint(cfg.Paths.CheckpointFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: starting a full scan: %v\n", err)
+			} else {
+				checkpoint = cp
+				if opts.verbose {
+					fmt.Printf("Resuming scan: %d directories already complete\n", cp.Len())
+				}
+			}
+		}
+		fsCollector.SetCheckpoint(checkpoint)
+	}
+
+	// Permissions come from the same walk and stat as the media files.
+	auditPermissions := cfg.Permissions.Enabled && !opts.skipPermissions
+	wantPermissions := auditPermissions || opts.dumpPermissions != ""
+	if wantPermissions {
+		fsCollector.SetCollectPermissions(cfg.Permissions.SkipPaths)
+	}
+
+	var progress *utils.Progress
+	if !opts.quiet && opts.progressEvery > 0 && utils.IsTerminal(os.Stderr) {
+		progress = utils.StartProgress(os.Stderr, "Scanning filesystem", opts.progressEvery)
+		fsCollector.SetProgress(progress)
+	}
+	if opts.watchdog != nil {
+		fsCollector.SetHeartbeat(opts.watchdog.Beat)
+	}
+
+	fsStart := time.Now()
+	fsCtx, fsCancel := phaseContext(ctx, opts.fsTimeout)
+	mediaFiles, err := fsCollector.Collect(fsCtx)
+	fsInterrupted := fsCtx.Err() != nil
+	fsCancel()
+	progress.Stop()
+	opts.events.FilesystemFinished(len(mediaFiles), time.Since(fsStart), err)
+	fsErr := err
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to collect filesystem data: %v\n", err)
+		if checkpoint != nil && fsInterrupted {
+			fmt.Fprintf(os.Stderr, "Scan progress saved to %s; rerun with --resume to continue\n", cfg.Paths.CheckpointFile)
+		}
+	} else if checkpoint != nil {
+		if err := checkpoint.Remove(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove checkpoint: %v\n", err)
+		}
 	}
 
-	if *verbose {
+	if opts.verbose {
 		fmt.Printf("Found %d media files\n", len(mediaFiles))
+		if n := fsCollector.InFlightSkipped(); n > 0 {
+			fmt.Printf("Skipped %d file(s) modified in the last %ds\n", n, cfg.Paths.MinFileAgeSeconds)
+		}
 	}
 
-	var permissions []models.FilePermissions
-	if cfg.Permissions.Enabled && !*skipPermissions {
-		if *verbose {
-			fmt.Println("Collecting permission data...")
+	fingerprint := cfg.Outputs.Fingerprint
+	if opts.fingerprint != "" {
+		fingerprint = opts.fingerprint
+	}
+	if fingerprint != "" {
+		if opts.verbose {
+			fmt.Printf("Fingerprinting files (%s)...\n", fingerprint)
 		}
-		permissions, err = utils.CollectPermissions(cfg.Paths.MediaRoot, cfg.Paths.TorrentRoot, cfg.Permissions.SkipPaths)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to collect permission data: %v\n", err)
-		} else if *verbose {
+		if err := collectors.FingerprintFiles(ctx, mediaFiles, fingerprint); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: fingerprinting stopped early: %v\n", err)
+		}
+	}
+
+	var permissions []models.FilePermissions
+	if wantPermissions {
+		permissions = fsCollector.Permissions()
+		if opts.verbose {
 			fmt.Printf("Collected permissions for %d files\n", len(permissions))
 		}
+
+		if opts.dumpPermissions != "" {
+			if err := reporting.WritePermissionsDump(opts.dumpPermissions, permissions); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write permission dump: %v\n", err)
+			} else {
+				fmt.Printf("Permission dump written to: %s\n", opts.dumpPermissions)
+			}
+		}
 	}
 
-	var sonarrFiles, radarrFiles []models.ArrFile
-	var connectionStatus []analysis.ServiceStatus
+	servicesStart := time.Now()
+	servicesCtx, servicesCancel := phaseContext(ctx, opts.servicesTimeout)
+	arrFiles, connectionStatus := collectArrFiles(servicesCtx, cfg, excludedRoots, opts.verbose)
+	opts.events.ServicesFinished(connectionStatus, time.Since(servicesStart))
+	qbStart := time.Now()
+	torrents, qbStatus, qbWarning := collectTorrents(servicesCtx, cfg, opts.verbose)
+	servicesCancel()
+	opts.watchdog.Beat()
+	// Only Arr statuses so far: whether files left Arr is judged on these.
+	arrOK := arrAnswered(connectionStatus)
+	if qbStatus != nil {
+		opts.events.ServicesFinished([]analysis.ServiceStatus{*qbStatus}, time.Since(qbStart))
+		connectionStatus = append(connectionStatus, *qbStatus)
+	}
 
-	if cfg.Sonarr.URL != "" {
-		sonarrCollector := collectors.NewSonarrCollector(cfg.Sonarr.URL, cfg.Sonarr.APIKey)
-		sonarrStatus := analysis.ServiceStatus{Name: "Sonarr", Enabled: true}
-		if err := sonarrCollector.TestConnection(ctx); err != nil {
-			sonarrStatus.OK = false
-			sonarrStatus.Error = err.Error()
-			fmt.Fprintf(os.Stderr, "[SONARR] Connection failed: %v\n", err)
+	if opts.dumpRaw != "" {
+		arrDump := make(map[string][]models.ArrFile)
+		for kind, files := range arrFiles {
+			for _, f := range files {
+				service := f.Service
+				if service == "" {
+					service = kind
+				}
+				arrDump[service] = append(arrDump[service], f)
+			}
+		}
+		if err := reporting.WriteRawDump(opts.dumpRaw, cfg.PathMappings, mediaFiles, arrDump, torrents); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write raw dump: %v\n", err)
 		} else {
-			sonarrStatus.OK = true
-			fmt.Println("[SONARR] Connected successfully")
+			fmt.Printf("Raw collector data written to: %s\n", opts.dumpRaw)
+		}
+	}
+
+	if opts.verbose {
+		fmt.Println("Analyzing data...")
+	}
+
+	engine := newEngine(cfg, auditPermissions, opts.rules, opts.verbose)
+	if unmappedChecked {
+		engine.SetArrUnmappedFolders(arrUnmapped)
+	}
+
+	reportFile := opts.reportFile
+	if reportFile == "" {
+		reportFile = os.ExpandEnv(cfg.Outputs.ReportFile)
+	}
+	if arrOK {
+		if tracked, from := previousTrackedPaths(cfg, reportFile); from != "" {
+			engine.SetPreviouslyTracked(tracked)
+			if opts.verbose {
+				fmt.Printf("Comparing against %d tracked files in %s\n", len(tracked), from)
+			}
 		}
-		connectionStatus = append(connectionStatus, sonarrStatus)
-		if *verbose {
-			fmt.Println("Collecting Sonarr data...")
+	}
+
+	sonarrFiles, otherArrFiles := arrFiles.engineInputs()
+	result := engine.Analyze(mediaFiles, sonarrFiles, otherArrFiles, torrents, permissions)
+	result.ConnectionStatus = connectionStatus
+	opts.watchdog.Beat()
+	if qbWarning != "" {
+		result.Warnings = append(result.Warnings, qbWarning)
+	}
+
+	var arrPaths []string
+	for _, files := range arrFiles {
+		for _, f := range files {
+			arrPaths = append(arrPaths, f.Path)
 		}
-		sonarrFiles, err = sonarrCollector.Collect(ctx)
+	}
+	for _, warning := range cfg.PathMappingWarnings(arrPaths) {
+		result.Warnings = append(result.Warnings, warning)
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if fsErr != nil {
+		result.FilesystemFailed = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Filesystem collection failed, so scanned files are missing or incomplete: %v", fsErr))
+	}
+
+	if warning := fsCollector.HardlinkWarning(); warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if cfg.Paths.MediaRoot != "" && cfg.Paths.TorrentRoot != "" {
+		same, err := utils.SameDevice(cfg.Paths.ScanRoots())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to collect Sonarr data: %v\n", err)
-		} else if *verbose {
-			fmt.Printf("Found %d Sonarr files\n", len(sonarrFiles))
+			fmt.Fprintf(os.Stderr, "Warning: could not compare media and torrent filesystems: %v\n", err)
+		} else if !same {
+			warning := fmt.Sprintf("media_root (%s) and torrent_root (%s) are on different filesystems. Hardlinks cannot span filesystems, so no library file can be hardlinked to a torrent and hardlink-based health (healthy vs at-risk) is not meaningful for this setup.", cfg.Paths.MediaRoot, cfg.Paths.TorrentRoot)
+			result.Warnings = append(result.Warnings, warning)
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
 		}
 	}
 
-	if cfg.Radarr.URL != "" {
-		radarrCollector := collectors.NewRadarrCollector(cfg.Radarr.URL, cfg.Radarr.APIKey)
-		radarrStatus := analysis.ServiceStatus{Name: "Radarr", Enabled: true}
-		if err := radarrCollector.TestConnection(ctx); err != nil {
-			radarrStatus.OK = false
-			radarrStatus.Error = err.Error()
-			fmt.Fprintf(os.Stderr, "[RADARR] Connection failed: %v\n", err)
-		} else {
-			radarrStatus.OK = true
-			fmt.Println("[RADARR] Connected successfully")
+	if len(cfg.Suspicious.KnownBadHashes) > 0 && len(result.SuspiciousFiles) > 0 {
+		if opts.verbose {
+			fmt.Printf("Hashing %d suspicious files against the known-bad list...\n", len(result.SuspiciousFiles))
+		}
+		matched, err := collectors.MatchKnownBadHashes(ctx, result.SuspiciousFiles, cfg.Suspicious.KnownBadHashes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: known-bad hashing stopped early: %v\n", err)
 		}
-		connectionStatus = append(connectionStatus, radarrStatus)
-		if *verbose {
-			fmt.Println("Collecting Radarr data...")
+		if matched > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d suspicious file(s) match the known-bad hash list\n", matched)
 		}
-		radarrFiles, err = radarrCollector.Collect(ctx)
+	}
+
+	if opts.verifyMedia {
+		verifier, err := collectors.NewMediaVerifier(cfg.Verify.SampleSize, cfg.Verify.Concurrency, time.Duration(cfg.Verify.TimeoutSeconds)*time.Second)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to collect Radarr data: %v\n", err)
-		} else if *verbose {
-			fmt.Printf("Found %d Radarr files\n", len(radarrFiles))
+			fmt.Fprintf(os.Stderr, "Warning: skipping media verification: %v\n", err)
+		} else {
+			if opts.verbose {
+				fmt.Println("Verifying media files with ffprobe...")
+			}
+			result.CorruptFiles, result.Summary.VerifiedCount = verifier.Verify(ctx, mediaFiles)
+			result.Summary.CorruptCount = len(result.CorruptFiles)
+			if opts.verbose {
+				fmt.Printf("Probed %d media files, %d failed\n", result.Summary.VerifiedCount, result.Summary.CorruptCount)
+			}
 		}
 	}
 
-	var torrents []models.Torrent
-	if cfg.Qbittorrent.URL != "" {
-		qbStatus := analysis.ServiceStatus{Name: "qBittorrent", Enabled: true}
-		if *verbose {
-			fmt.Println("Collecting qBittorrent data...")
+	opts.watchdog.Beat()
+	duration := time.Since(startTime)
+	result.Summary.Duration = duration
+
+	reportDirs := cfg.GetReportPaths()
+
+	// Generate Markdown report
+	mdFormatter := reporting.NewMarkdownFormatter()
+	mdFormatter.SetGroupBy(opts.groupBy)
+	mdFormatter.SetLabel(opts.label)
+	reportContent := mdFormatter.Format(result, cfg, duration)
+	var reportPaths []string
+	if reportFile != "" {
+		if err := mdFormatter.WriteToPath(reportContent, reportFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
+		} else {
+			reportPaths = append(reportPaths, reportFile)
+		}
+	} else {
+		reportPaths = writeToDirs(reportDirs, "report", func(dir string) (string, error) {
+			return mdFormatter.WriteToFile(reportContent, dir)
+		})
+	}
+	var reportPath string
+	if len(reportPaths) > 0 {
+		reportPath = reportPaths[0]
+		fmt.Printf("Report written to: %s\n", strings.Join(reportPaths, ", "))
+	}
+
+	// Generate JSON report
+	jsonFormatter := reporting.NewJSONFormatter()
+	jsonFormatter.SetGroupBy(opts.groupBy)
+	jsonFormatter.SetLabel(opts.label)
+	jsonData, err := jsonFormatter.Format(result, cfg, duration)
+	var jsonPaths []string
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to generate JSON report: %v\n", err)
+	} else if reportFile != "" {
+		jsonPath := jsonReportPath(reportFile)
+		if err := jsonFormatter.WriteToPath(jsonData, jsonPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write JSON report: %v\n", err)
+		} else {
+			jsonPaths = append(jsonPaths, jsonPath)
 		}
-		qbCollector := collectors.NewQBCollector(cfg.Qbittorrent.URL, cfg.Qbittorrent.Username, cfg.Qbittorrent.Password)
-		torrents, err = qbCollector.Collect(ctx)
+	} else {
+		jsonPaths = writeToDirs(reportDirs, "JSON report", func(dir string) (string, error) {
+			return jsonFormatter.WriteToFile(jsonData, dir)
+		})
+	}
+	if len(jsonPaths) > 0 {
+		fmt.Printf("JSON report written to: %s\n", strings.Join(jsonPaths, ", "))
+	}
+
+	var sarifPaths []string
+	if cfg.Outputs.SARIF {
+		sarifFormatter := reporting.NewSARIFFormatter()
+		sarifData, err := sarifFormatter.Format(result)
 		if err != nil {
-			qbStatus.OK = false
-			qbStatus.Error = err.Error()
-			fmt.Fprintf(os.Stderr, "Warning: failed to collect qBittorrent data: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to generate SARIF report: %v\n", err)
+		} else if reportFile != "" {
+			sarifPath := utils.SiblingPath(reportFile, ".sarif")
+			if err := sarifFormatter.WriteToPath(sarifData, sarifPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write SARIF report: %v\n", err)
+			} else {
+				sarifPaths = append(sarifPaths, sarifPath)
+			}
 		} else {
-			qbStatus.OK = true
+			sarifPaths = writeToDirs(reportDirs, "SARIF report", func(dir string) (string, error) {
+				return sarifFormatter.WriteToFile(sarifData, dir)
+			})
 		}
-		connectionStatus = append(connectionStatus, qbStatus)
-		if *verbose {
-			fmt.Printf("Found %d torrents\n", len(torrents))
+		if len(sarifPaths) > 0 {
+			fmt.Printf("SARIF report written to: %s\n", strings.Join(sarifPaths, ", "))
 		}
 	}
 
-	if *verbose {
-		fmt.Println("Analyzing data...")
+	if cfg.Outputs.Compress && reportFile == "" {
+		keep := append(append(append([]string(nil), reportPaths...), jsonPaths...), sarifPaths...)
+		n := 0
+		for _, dir := range reportDirs {
+			compressed, err := reporting.CompressReports(dir, keep...)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to compress old reports in %s: %v\n", dir, err)
+			}
+			n += compressed
+		}
+		if opts.verbose && n > 0 {
+			fmt.Printf("Compressed %d earlier report(s)\n", n)
+		}
+	}
+
+	if cfg.Outputs.SQLitePath != "" {
+		sqliteWriter := reporting.NewSQLiteWriter(os.ExpandEnv(cfg.Outputs.SQLitePath))
+		if err := sqliteWriter.Write(result, startTime, duration); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record run in SQLite history: %v\n", err)
+		} else if opts.verbose {
+			fmt.Printf("Run recorded in SQLite history: %s\n", cfg.Outputs.SQLitePath)
+		}
 	}
 
+	if opts.quietHours.hold(time.Now(), result, reportPath, duration) {
+		fmt.Println("Quiet hours: notifications held")
+	} else {
+		sendNotifications(cfg, result, reportPath, duration, opts.label, opts.verbose)
+	}
+
+	fmt.Printf("Audit complete in %.2f seconds\n", duration.Seconds())
+	fmt.Printf("Results: %d healthy, %d at risk, %d orphaned media, %d orphaned downloads, %d suspicious, %d corrupt\n",
+		result.Summary.HealthyCount,
+		result.Summary.AtRiskCount,
+		result.Summary.OrphanCount,
+		result.Summary.OrphanedDownloadCount,
+		result.Summary.SuspiciousCount,
+		result.Summary.CorruptCount,
+	)
+	opts.events.RunComplete(result, duration, reportPath)
+
+	return result
+}
+
+// newQBCollector returns a qBittorrent WebUI client for cfg.
+func newQBCollector(cfg *config.Config) *collectors.QBCollector {
+	qbc := collectors.NewQBCollector(cfg.Qbittorrent.URL, cfg.Qbittorrent.Username, cfg.Qbittorrent.Password)
+	qbc.SetTransport(cfg.HTTP.Transport)
+	return qbc
+}
+
+// jsonReportPath returns where the JSON report goes alongside a fixed
+// markdown report file.
+func jsonReportPath(reportFile string) string {
+	return utils.SiblingPath(reportFile, ".json")
+}
+
+// arrAnswered reports whether at least one Arr service is configured and
+// every configured one answered, so an untracked file really is untracked.
+func arrAnswered(statuses []analysis.ServiceStatus) bool {
+	answered := false
+	for _, svc := range statuses {
+		if !svc.Enabled {
+			continue
+		}
+		if !svc.OK {
+			return false
+		}
+		answered = true
+	}
+	return answered
+}
+
+// previousTrackedPaths loads the files Arr tracked according to the last
+// run's JSON report, the newest across every report_dir, and returns the
+// report it read ("" when there is none). An unreadable report is warned
+// about and skipped.
+func previousTrackedPaths(cfg *config.Config, reportFile string) ([]string, string) {
+	var path string
+	if reportFile != "" {
+		path = jsonReportPath(reportFile)
+		if _, err := os.Stat(path); err != nil {
+			return nil, ""
+		}
+	} else {
+		latest, err := reporting.LatestJSONReport(cfg.GetReportPaths()...)
+		if err != nil || latest == "" {
+			return nil, ""
+		}
+		path = latest
+	}
+	tracked, err := reporting.LoadTrackedPaths(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: not checking for files removed from Arr: %v\n", err)
+		return nil, ""
+	}
+	return tracked, path
+}
+
+// writeToDirs calls write for each report directory and returns the paths
+// written. A failing directory is warned about and doesn't stop the others.
+func writeToDirs(dirs []string, what string, write func(dir string) (string, error)) []string {
+	var written []string
+	for _, dir := range dirs {
+		path, err := write(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write %s to %s: %v\n", what, dir, err)
+			continue
+		}
+		written = append(written, path)
+	}
+	return written
+}
+
+// phaseContext bounds one collection phase by timeout; zero or negative
+// leaves it limited only by ctx.
+func phaseContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// resolveExcludedRootFolders fetches each Arr service's root folders and
+// returns the filesystem paths of those listed in exclude_root_folders.
+// Configured folders the service doesn't report are warned about and ignored.
+func resolveExcludedRootFolders(ctx context.Context, cfg *config.Config) []string {
+	excluded, _, _ := resolveRootFolders(ctx, cfg, false)
+	return excluded
+}
+
+// resolveRootFolders is resolveExcludedRootFolders that, with withUnmapped,
+// also asks every Arr service for its root folders and returns the unmapped
+// folders they report, as the services see them. checked is false when no
+// service answered. Root folders of services with api_root set are added to
+// cfg.PathMappings first, so exclusions and later lookups use them.
+func resolveRootFolders(ctx context.Context, cfg *config.Config, withUnmapped bool) (excluded, unmapped []string, checked bool) {
+	type rootFolderSource struct {
+		name  string
+		cfg   config.ArrConfig
+		fetch func(ctx context.Context) ([]models.RootFolder, error)
+	}
+
+	var sources []rootFolderSource
+	for _, svc := range configuredArrServices(cfg) {
+		if len(svc.cfg.ExcludeRootFolders) > 0 || svc.cfg.APIRoot != "" || withUnmapped {
+			sources = append(sources, rootFolderSource{svc.name, svc.cfg, svc.collector.FetchRootFolders})
+		}
+	}
+
+	for _, src := range sources {
+		folders, err := src.fetch(ctx)
+		if err != nil {
+			if len(src.cfg.ExcludeRootFolders) > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: failed to fetch %s root folders, not excluding any: %v\n", src.name, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: failed to fetch %s root folders: %v\n", src.name, err)
+			}
+			continue
+		}
+		checked = true
+		for _, f := range folders {
+			unmapped = append(unmapped, f.UnmappedFolders...)
+		}
+
+		known := make(map[string]bool, len(folders))
+		paths := make([]string, 0, len(folders))
+		for _, f := range folders {
+			known[filepath.Clean(f.Path)] = true
+			paths = append(paths, f.Path)
+		}
+
+		mappings, outside := src.cfg.RootFolderMappings(paths)
+		for _, folder := range outside {
+			fmt.Fprintf(os.Stderr, "Warning: %s root folder %s is outside api_root %s, not mapping it\n", src.name, folder, src.cfg.APIRoot)
+		}
+		cfg.AddPathMappings(mappings)
+
+		for _, exclude := range src.cfg.ExcludeRootFolders {
+			if !known[filepath.Clean(exclude)] {
+				fmt.Fprintf(os.Stderr, "Warning: %s has no root folder %s, ignoring exclusion\n", src.name, exclude)
+				continue
+			}
+			excluded = append(excluded, utils.NormalizePath(exclude, cfg.PathMappings))
+		}
+	}
+
+	return excluded, unmapped, checked
+}
+
+// newEngine configures an analysis engine from cfg, loading the baseline if
+// one is set.
+func newEngine(cfg *config.Config, auditPermissions bool, rules []analysis.ClassificationRule, verbose bool) *analysis.Engine {
 	engine := analysis.NewEngine(
 		cfg.Sonarr.GraceHours,
 		cfg.Radarr.GraceHours,
 		cfg.Qbittorrent.GraceHours,
 		cfg.Suspicious.Extensions,
 		cfg.Suspicious.FlagArchives,
-		cfg.Permissions.Enabled && !*skipPermissions,
+		auditPermissions,
 		cfg.Permissions.GroupGID,
 		cfg.Permissions.AllowedUIDs,
 		cfg.Permissions.SGIDPaths,
@@ -181,54 +1096,251 @@ func runScan(args []string) {
 		cfg.PathMappings,
 		cfg.Paths.TorrentRoot,
 	)
+	engine.SetExpectedModes(cfg.Permissions.FileMode, cfg.Permissions.DirMode)
+	engine.SetRules(rules)
+	engine.SetProtectedPaths(cfg.Analysis.ProtectedPaths)
+	engine.SetOrphanIgnoreExtensions(cfg.Analysis.OrphanIgnoreExtensions)
+	engine.SetExpectedUntrackedPaths(cfg.Analysis.ExpectedUntrackedPaths)
+	engine.SetSuspiciousSeverities(cfg.Suspicious.Severities)
+	engine.SetMaxExpectedHardlinks(cfg.Analysis.MaxExpectedHardlinks)
+	engine.SetIncludeUnknownTorrents(cfg.Qbittorrent.IncludeUnknownState)
+	engine.SetTorrentCategoryGraceHours(cfg.Qbittorrent.CategoryGraceHours)
+	engine.SetScanRoots(append(nonEmpty(cfg.Paths.MediaRoot, cfg.Paths.TorrentRoot), cfg.Paths.ExtraScanPaths...))
+	engine.SetReflinks(cfg.Analysis.Reflinks)
+	engine.SetWorkers(cfg.Performance.Workers)
+	engine.SetQueueSize(cfg.Performance.QueueSize)
 
-	result := engine.Analyze(mediaFiles, sonarrFiles, radarrFiles, torrents, permissions)
-	result.ConnectionStatus = connectionStatus
+	if cfg.Analysis.BaselineFile != "" {
+		baseline, err := analysis.LoadBaseline(cfg.Analysis.BaselineFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring baseline: %v\n", err)
+		} else {
+			engine.SetBaseline(baseline)
+			if verbose {
+				fmt.Printf("Loaded %d baseline entries\n", len(baseline.Entries))
+			}
+		}
+	}
+	return engine
+}
 
-	duration := time.Since(startTime)
-	result.Summary.Duration = duration
+// collectTorrents fetches torrents from the qBittorrent WebUI, or from the
+// BT_backup resume files in [qbittorrent].backup_dir when no URL is set or
+// the API fails. The returned warning is non-empty when the backup was used
+// as a fallback, since it is only as fresh as qBittorrent's last save.
+func collectTorrents(ctx context.Context, cfg *config.Config, verbose bool) ([]models.Torrent, *analysis.ServiceStatus, string) {
+	qb := cfg.Qbittorrent
+	if qb.URL == "" && qb.BackupDir == "" {
+		return nil, nil, ""
+	}
 
-	reportDir := cfg.GetReportPath()
+	var apiErr error
+	if qb.URL != "" {
+		if verbose {
+			fmt.Println("Collecting qBittorrent data...")
+		}
+		collector := newQBCollector(cfg)
+		torrents, err := collector.Collect(ctx)
+		if err == nil {
+			if verbose {
+				fmt.Printf("Found %d torrents\n", len(torrents))
+			}
+			return torrents, &analysis.ServiceStatus{Name: "qBittorrent", Enabled: true, OK: true, FailedFetches: collector.FailedFetches()}, ""
+		}
+		fmt.Fprintf(os.Stderr, "Warning: failed to collect qBittorrent data: %v\n", err)
+		if qb.BackupDir == "" {
+			return nil, &analysis.ServiceStatus{
+				Name:    "qBittorrent",
+				Enabled: true,
+				Error:   err.Error(),
+				Reason:  collectors.FailureReason(err),
+			}, ""
+		}
+		apiErr = err
+	}
 
-	// Generate Markdown report
-	mdFormatter := reporting.NewMarkdownFormatter()
-	reportContent := mdFormatter.Format(result, cfg, duration)
-	reportPath, err := mdFormatter.WriteToFile(reportContent, reportDir)
+	if verbose {
+		fmt.Printf("Reading qBittorrent resume data from %s...\n", qb.BackupDir)
+	}
+	status := &analysis.ServiceStatus{Name: "qBittorrent (backup)", Enabled: true, OK: true}
+	torrents, err := collectors.NewFastresumeCollector(qb.BackupDir).Collect(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
-	} else {
-		fmt.Printf("Report written to: %s\n", reportPath)
+		fmt.Fprintf(os.Stderr, "Warning: failed to read qBittorrent backup: %v\n", err)
+		status.OK = false
+		status.Error = err.Error()
+		if apiErr != nil {
+			status.Error = fmt.Sprintf("API: %v; backup: %v", apiErr, err)
+		}
+		return nil, status, ""
+	}
+	if verbose {
+		fmt.Printf("Found %d torrents\n", len(torrents))
 	}
 
-	// Generate JSON report
-	jsonFormatter := reporting.NewJSONFormatter()
-	jsonData, err := jsonFormatter.Format(result, cfg, duration)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to generate JSON report: %v\n", err)
-	} else {
-		jsonPath, err := jsonFormatter.WriteToFile(jsonData, reportDir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to write JSON report: %v\n", err)
+	var warning string
+	if apiErr != nil {
+		status.FailedFetches = []string{fmt.Sprintf("torrents from the WebUI API: %v", apiErr)}
+		warning = fmt.Sprintf("qBittorrent API failed (%v), so torrent data was read from %s. It reflects qBittorrent's last resume-data save and may be stale.", apiErr, qb.BackupDir)
+	}
+	return torrents, status, warning
+}
+
+// collectArrFiles fetches tracked files from every configured Arr service,
+// grouped by service kind.
+func collectArrFiles(ctx context.Context, cfg *config.Config, excludedRoots []string, verbose bool) (arrFiles arrFilesByKind, connectionStatus []analysis.ServiceStatus) {
+	arrFiles = make(arrFilesByKind)
+	cache := loadArrCache(cfg)
+	for _, svc := range configuredArrServices(cfg) {
+		tag := strings.ToUpper(svc.name)
+		status := analysis.ServiceStatus{Name: svc.name, Enabled: true}
+		if err := svc.collector.TestConnection(ctx); err != nil {
+			status.OK = false
+			status.Error = err.Error()
+			status.Reason = collectors.FailureReason(err)
+			fmt.Fprintf(os.Stderr, "[%s] Connection failed: %v\n", tag, err)
 		} else {
-			fmt.Printf("JSON report written to: %s\n", jsonPath)
+			status.OK = true
+			fmt.Printf("[%s] Connected successfully\n", tag)
+		}
+		connectionStatus = append(connectionStatus, status)
+		if verbose {
+			fmt.Printf("Collecting %s data...\n", svc.name)
+		}
+		files, snap, err := collectServiceFiles(ctx, cfg, svc, cache, verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to collect %s data: %v\n", svc.name, err)
+			// It answered but its files are missing or partial, so it
+			// counts as failed like an unreachable service.
+			if last := &connectionStatus[len(connectionStatus)-1]; last.OK {
+				last.OK = false
+				last.Error = fmt.Sprintf("collecting files failed: %v", err)
+				last.Reason = collectors.FailureReason(err)
+			}
+		} else if verbose {
+			fmt.Printf("Found %d %s files\n", len(files), svc.name)
+		}
+		if failed := svc.collector.FailedFetches(); len(failed) > 0 {
+			connectionStatus[len(connectionStatus)-1].FailedFetches = failed
 		}
+		if skipped := svc.collector.SkippedRequests(); skipped > 0 {
+			// Its file list is incomplete, so report it as failed, which also
+			// keeps its missing files from being judged removed from Arr.
+			last := &connectionStatus[len(connectionStatus)-1]
+			last.OK = false
+			last.Degraded = true
+			last.SkippedFetches = skipped
+			last.Reason = "Degraded"
+			last.Error = fmt.Sprintf("stopped after %d consecutive failed requests; skipped %d fetches", cfg.HTTP.ArrMaxFailures, skipped)
+			fmt.Fprintf(os.Stderr, "[%s] Degraded: %s\n", tag, last.Error)
+		}
+		if cache != nil && err == nil && svc.collector.SkippedRequests() == 0 && len(svc.collector.FailedFetches()) == 0 {
+			snap.Files = slices.Clone(files)
+			cache.Put(svc.name, snap)
+		}
+		files = excludeArrFiles(files, excludedRoots, cfg.PathMappings)
+		if svc.instance {
+			for i := range files {
+				files[i].Service = svc.name
+				files[i].GraceHours = svc.cfg.GraceHours
+			}
+		}
+		arrFiles[svc.kind] = append(arrFiles[svc.kind], files...)
 	}
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	return arrFiles, connectionStatus
+}
 
-	notifier := reporting.NewDiscordNotifier(cfg.Notifications.DiscordWebhook)
-	if err := notifier.Send(result, reportPath, duration); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
+// excludeArrFiles drops Arr-tracked files that live under an excluded root
+// folder (given as filesystem paths).
+func excludeArrFiles(files []models.ArrFile, excludedRoots []string, mappings map[string]string) []models.ArrFile {
+	if len(excludedRoots) == 0 {
+		return files
 	}
 
-	fmt.Printf("Audit complete in %.2f seconds\n", duration.Seconds())
-	fmt.Printf("Results: %d healthy, %d at risk, %d orphaned media, %d orphaned downloads, %d suspicious\n",
-		result.Summary.HealthyCount,
-		result.Summary.AtRiskCount,
-		result.Summary.OrphanCount,
-		result.Summary.OrphanedDownloadCount,
-		result.Summary.SuspiciousCount,
-	)
+	kept := files[:0]
+	for _, f := range files {
+		fsPath := utils.NormalizePath(f.Path, mappings)
+		excluded := false
+		for _, root := range excludedRoots {
+			if utils.IsUnderPath(fsPath, root) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func configuredNotifiers(cfg *config.Config, label string) []reporting.Notifier {
+	var notifiers []reporting.Notifier
+	if cfg.Notifications.DiscordWebhook != "" || len(cfg.Notifications.DiscordWebhooks) > 0 {
+		discord := reporting.NewDiscordNotifier(cfg.Notifications.DiscordWebhook)
+		for _, wh := range cfg.Notifications.DiscordWebhooks {
+			discord.AddWebhook(wh.URL, wh.MinSeverity)
+		}
+		discord.SetAttachReport(cfg.Notifications.DiscordAttachReport)
+		discord.SetUseEmoji(cfg.Outputs.EmojiEnabled())
+		discord.SetThresholds(cfg.Notifications)
+		discord.SetRootCAs(cfg.HTTP.RootCAs)
+		discord.SetLabel(label)
+		notifiers = append(notifiers, discord)
+	}
+	return notifiers
+}
+
+func sendNotifications(cfg *config.Config, result *analysis.AnalysisResult, reportPath string, duration time.Duration, label string, verbose bool) {
+	notifiers := configuredNotifiers(cfg, label)
+	if len(notifiers) == 0 {
+		return
+	}
+	if !reporting.ShouldNotify(result, cfg.Notifications) {
+		if verbose {
+			fmt.Println("Skipping notifications: no findings above the configured threshold")
+		}
+		return
+	}
+	timeout := time.Duration(cfg.Notifications.TimeoutSeconds) * time.Second
+	err := reporting.SendAll(notifiers, result, reportPath, duration, timeout)
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", e)
+		}
+	}
+}
+
+func compileRules(cfg *config.Config) ([]analysis.ClassificationRule, error) {
+	var rules []analysis.ClassificationRule
+	for i, rc := range cfg.Analysis.Rules {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("analysis.rules[%d]", i)
+		}
+		rule, err := analysis.CompileRule(name, rc.When, rc.Classification)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
 
-	if result.Summary.OrphanCount > 0 || result.Summary.AtRiskCount > 0 || result.Summary.OrphanedDownloadCount > 0 {
-		os.Exit(2)
+// auditExitCode returns 2 when the audit found anything needing attention and
+// 0 otherwise. Orphan and at-risk counts below their warn thresholds don't
+// count.
+func auditExitCode(result *analysis.AnalysisResult, thresholds config.NotificationConfig) int {
+	s := result.Summary
+	orphans := reporting.CountSeverity(s.OrphanCount, thresholds.OrphanWarnThreshold, thresholds.OrphanCriticalThreshold, "error")
+	atRisk := reporting.CountSeverity(s.AtRiskCount, thresholds.AtRiskWarnThreshold, thresholds.AtRiskCriticalThreshold, "warning")
+	needsAttention := func(level string) bool { return level == "warning" || level == "error" }
+	if needsAttention(orphans) || needsAttention(atRisk) || s.OrphanedDownloadCount > 0 || s.RemovedFromArrCount > 0 || s.CorruptCount > 0 {
+		return 2
 	}
+	return 0
 }
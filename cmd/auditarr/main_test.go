@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/config"
+)
+
+func TestWorseExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, want int
+	}{
+		{0, 0, 0},
+		{0, 2, 2},
+		{2, 0, 2},
+		{2, 1, 1},
+		{1, 2, 1},
+		{0, 1, 1},
+		{1, 0, 1},
+		// Any other failure code ranks with 1 and the first one is kept.
+		{3, 1, 3},
+		{2, 3, 3},
+	} {
+		if got := worseExitCode(tc.a, tc.b); got != tc.want {
+			t.Errorf("worseExitCode(%d, %d) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestConfigListExpand(t *testing.T) {
+	dir := t.TempDir()
+	stacks := filepath.Join(dir, "stacks")
+	if err := os.Mkdir(stacks, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"home.toml", "stacks/a.toml", "stacks/b.toml", "stacks/notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	home := filepath.Join(dir, "home.toml")
+	a, b := filepath.Join(stacks, "a.toml"), filepath.Join(stacks, "b.toml")
+
+	for _, tc := range []struct {
+		name    string
+		list    configList
+		want    []string
+		wantErr string
+	}{
+		{"default search", nil, []string{""}, ""},
+		{"single path", configList{home}, []string{home}, ""},
+		{"stdin", configList{"-"}, []string{"-"}, ""},
+		{"glob", configList{filepath.Join(stacks, "*.toml")}, []string{a, b}, ""},
+		{"directory", configList{stacks}, []string{a, b}, ""},
+		{"order kept, duplicates dropped", configList{b, home, stacks}, []string{b, home, a}, ""},
+		{"glob without matches", configList{filepath.Join(dir, "*.yaml")}, nil, "no config files match"},
+		{"directory without configs", configList{t.TempDir()}, nil, "no *.toml config files"},
+		{"invalid glob", configList{"[bad"}, nil, "invalid config glob"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.list.expand()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expand() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tc.want) {
+				t.Errorf("expand() = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckBatchFlags(t *testing.T) {
+	paths := []string{"/etc/auditarr/a.toml", "/etc/auditarr/b.toml"}
+	for _, tc := range []struct {
+		name    string
+		paths   []string
+		opts    scanOptions
+		wantErr string
+	}{
+		{"plain batch", paths, scanOptions{verbose: true, label: "nightly"}, ""},
+		{"report file", paths, scanOptions{reportFile: "/tmp/latest.md"}, "--report-file"},
+		{"raw dump", paths, scanOptions{dumpRaw: "/tmp/raw"}, "--dump-raw"},
+		{"permission dump", paths, scanOptions{dumpPermissions: "/tmp/perms.json"}, "--dump-permissions"},
+		{"stdin", append([]string{"-"}, paths...), scanOptions{}, "--config -"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBatchFlags(tc.paths, tc.opts)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkBatchFlags: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("checkBatchFlags error = %v, want one naming %s", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// phaseTestConfig loads a config scanning one media file, with Sonarr at
+// sonarrURL and reports written under a temp dir.
+func phaseTestConfig(t *testing.T, sonarrURL string, args ...string) (*config.Config, scanOptions) {
+	t.Helper()
+	media := t.TempDir()
+	if err := os.WriteFile(filepath.Join(media, "movie.mkv"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	extra := fmt.Sprintf("[sonarr]\nurl = %q\napi_key = \"key\"\n[outputs]\nreport_dir = %q\n", sonarrURL, t.TempDir())
+	opts := watchOptions(t, append([]string{"--quiet"}, args...)...)
+	cfg, err := loadScanConfig(writeTestConfig(t, media, extra), opts)
+	if err != nil {
+		t.Fatalf("loadScanConfig: %v", err)
+	}
+	cfg.HTTP.Transport = &http.Transport{}
+	return cfg, opts
+}
+
+func TestRunAudit_ServicesTimeoutKeepsFilesystemResult(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/system/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v3/", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg, opts := phaseTestConfig(t, srv.URL, "--services-timeout", "200ms")
+	start := time.Now()
+	result := runAudit(context.Background(), cfg, opts)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("runAudit took %s despite --services-timeout", elapsed)
+	}
+	if result.Summary.TotalFiles != 1 || result.FilesystemFailed {
+		t.Errorf("filesystem result = %d files (failed %t), want the one media file", result.Summary.TotalFiles, result.FilesystemFailed)
+	}
+	if failed := result.FailedServices(); len(failed) != 1 || !strings.HasPrefix(failed[0], "Sonarr") {
+		t.Errorf("FailedServices = %q, want Sonarr timed out", failed)
+	}
+	if problems := result.IncompleteCollection(); !slices.ContainsFunc(problems, func(p string) bool {
+		return strings.Contains(p, "deadline exceeded")
+	}) {
+		t.Errorf("IncompleteCollection = %q, want the timed-out Sonarr collection", problems)
+	}
+}
+
+func TestRunAudit_FSTimeoutWarns(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/system/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v3/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg, opts := phaseTestConfig(t, srv.URL, "--fs-timeout", "1ns")
+	result := runAudit(context.Background(), cfg, opts)
+	if !result.FilesystemFailed {
+		t.Fatal("FilesystemFailed = false, want the timed-out walk reported")
+	}
+	if !slices.ContainsFunc(result.Warnings, func(w string) bool {
+		return strings.Contains(w, "Filesystem collection failed") && strings.Contains(w, "deadline exceeded")
+	}) {
+		t.Errorf("Warnings = %q, want the filesystem timeout", result.Warnings)
+	}
+	if failed := result.FailedServices(); len(failed) != 0 {
+		t.Errorf("FailedServices = %q, want Sonarr unaffected by the filesystem timeout", failed)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/system/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{
+		Sonarr: config.ArrConfig{URL: srv.URL, APIKey: "key"},
+		Radarr: config.ArrConfig{URL: srv.URL, APIKey: "wrong"},
+	}
+	cfg.Qbittorrent.BackupDir = filepath.Join(t.TempDir(), "missing")
+	cfg.HTTP.Transport = &http.Transport{}
+
+	statuses := checkHealth(context.Background(), cfg)
+	if len(statuses) != 3 {
+		t.Fatalf("statuses = %+v, want Sonarr, Radarr and the qBittorrent backup dir", statuses)
+	}
+	if s := statuses[0]; s.Name != "Sonarr" || !s.OK || !s.Enabled {
+		t.Errorf("Sonarr = %+v, want OK", s)
+	}
+	if s := statuses[1]; s.Name != "Radarr" || s.OK || s.Reason != "Auth failed" {
+		t.Errorf("Radarr = %+v, want an auth failure", s)
+	}
+	if s := statuses[2]; s.Name != "qBittorrent (backup)" || s.OK || !strings.Contains(s.Error, "backup directory unreadable") {
+		t.Errorf("qBittorrent backup = %+v, want the missing directory", s)
+	}
+
+	if statuses := checkHealth(context.Background(), &config.Config{}); len(statuses) != 0 {
+		t.Errorf("statuses with nothing configured = %+v, want none", statuses)
+	}
+}
+
+func TestRunAudit_DumpPermissionsWithoutAudit(t *testing.T) {
+	media := t.TempDir()
+	// World-writable, so an audit would flag it.
+	file := filepath.Join(media, "movie.mkv")
+	if err := os.WriteFile(file, []byte("x"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(file, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	extra := fmt.Sprintf("[permissions]\nenabled = true\n[outputs]\nreport_dir = %q\n", t.TempDir())
+	path := writeTestConfig(t, media, extra)
+	dump := filepath.Join(t.TempDir(), "permissions.json")
+
+	opts := watchOptions(t, "--quiet", "--skip-permissions", "--dump-permissions", dump)
+	cfg, err := loadScanConfig(path, opts)
+	if err != nil {
+		t.Fatalf("loadScanConfig: %v", err)
+	}
+	result := runAudit(context.Background(), cfg, opts)
+	if len(result.PermissionIssues) != 0 {
+		t.Errorf("PermissionIssues = %+v, want none with --skip-permissions", result.PermissionIssues)
+	}
+	data, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatalf("permission dump not written: %v", err)
+	}
+	if !strings.Contains(string(data), file) {
+		t.Errorf("dump = %s, want %s collected", data, file)
+	}
+
+	opts = watchOptions(t, "--quiet")
+	if result := runAudit(context.Background(), cfg, opts); len(result.PermissionIssues) == 0 {
+		t.Error("PermissionIssues is empty without --skip-permissions, want the world-writable file flagged")
+	}
+}
+
+func TestPreviousTrackedPaths_NewestAcrossReportDirs(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	write := func(path, tracked string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(`{"tracked_paths": ["`+tracked+`"]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(first, "audit-report-2026-01-01-00-00-00.json"), "/media/old.mkv")
+	newest := filepath.Join(second, "audit-report-2026-01-02-00-00-00.json")
+	write(newest, "/media/new.mkv")
+
+	cfg := &config.Config{}
+	cfg.Outputs.ReportDir = []string{first, second}
+	tracked, from := previousTrackedPaths(cfg, "")
+	if from != newest || !slices.Equal(tracked, []string{"/media/new.mkv"}) {
+		t.Errorf("previousTrackedPaths = %q from %q, want the newest report %q", tracked, from, newest)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/collectors"
+)
+
+// runApplyPermissions audits permissions under the media and torrent roots
+// and corrects them in place. It is the only command that modifies files, so
+// it does nothing without --yes.
+func runApplyPermissions(args []string) {
+	fs := flag.NewFlagSet("apply-permissions", flag.ExitOnError)
+	configPath := configFlag(fs)
+	yes := fs.Bool("yes", false, "Apply the ownership and mode changes (required unless --dry-run)")
+	dryRun := fs.Bool("dry-run", false, "Print the changes that would be made without applying them")
+	ownerUID := fs.Int("owner-uid", -1, "Owner to give files with a wrong_owner issue (default: leave owners unchanged)")
+	verbose := fs.Bool("verbose", false, "Enable verbose output")
+	_ = fs.Parse(args)
+
+	if !*yes && !*dryRun {
+		fmt.Fprintln(os.Stderr, "apply-permissions changes ownership and modes under media_root and torrent_root; pass --yes to apply or --dry-run to preview")
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(*configPath, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if !cfg.Permissions.Enabled {
+		fmt.Fprintln(os.Stderr, "permissions.enabled must be set: the expected group and modes come from [permissions]")
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	fsCollector := collectors.NewFilesystemCollector(cfg.Paths.MediaRoot, cfg.Paths.TorrentRoot, nil)
+	fsCollector.SetFollowSymlinks(cfg.Paths.FollowSymlinks)
+	fsCollector.SetIgnoreMarkers(cfg.Paths.IgnoreMarkers)
+	fsCollector.SetWalkRetries(cfg.Paths.WalkRetries)
+	fsCollector.SetVerbose(*verbose)
+	fsCollector.SetCollectPermissions(cfg.Permissions.SkipPaths)
+	if _, err := fsCollector.Collect(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to collect permissions: %v\n", err)
+		os.Exit(1)
+	}
+	permissions := fsCollector.Permissions()
+
+	engine := newEngine(cfg, true, nil, *verbose)
+	result := engine.Analyze(nil, nil, nil, nil, permissions)
+	fixes := engine.PlanPermissionFixes(result.PermissionIssues, permissions, *ownerUID)
+	if len(fixes) == 0 {
+		fmt.Printf("Checked %d paths; nothing to fix\n", len(permissions))
+		return
+	}
+
+	failed := 0
+	for _, fix := range fixes {
+		if *dryRun {
+			fmt.Printf("would %s: %s\n", fix, fix.Path)
+			continue
+		}
+		if err := applyPermissionFix(fix); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fix %s: %v\n", fix.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", fix, fix.Path)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d of %d paths would change; rerun with --yes to apply\n", len(fixes), len(permissions))
+		return
+	}
+	fmt.Printf("Fixed %d of %d paths needing changes\n", len(fixes)-failed, len(fixes))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// applyPermissionFix changes ownership before the mode, since chown clears
+// the setgid bit on files. Symlinks are never followed.
+func applyPermissionFix(fix analysis.PermissionFix) error {
+	info, err := os.Lstat(fix.Path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("path is now a symlink, skipping")
+	}
+	if fix.NeedsChown() {
+		if err := os.Lchown(fix.Path, fix.UID, fix.GID); err != nil {
+			return err
+		}
+	}
+	if fix.NeedsChmod() {
+		if err := os.Chmod(fix.Path, goFileMode(fix.Mode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goFileMode converts Unix permission bits, including setuid, setgid and
+// sticky, to an os.FileMode.
+func goFileMode(mode uint32) os.FileMode {
+	m := os.FileMode(mode & 0777)
+	if mode&04000 != 0 {
+		m |= os.ModeSetuid
+	}
+	if mode&02000 != 0 {
+		m |= os.ModeSetgid
+	}
+	if mode&01000 != 0 {
+		m |= os.ModeSticky
+	}
+	return m
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/reporting"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	configPath := configFlag(fs)
+	interval := fs.Duration("interval", 24*time.Hour, "Time between audits")
+	metricsFile := fs.String("metrics-file", "", "Write Prometheus metrics for node_exporter's textfile collector to this path after each audit")
+	eventsFile := fs.String("events-file", "", "Append run_started, collector_finished and run_complete events as NDJSON to this file")
+	staleAfter := fs.Duration("stale-after", 0, "Warn when no audit has succeeded for this long (0 = three intervals)")
+	opts := bindScanFlags(fs)
+	_ = fs.Parse(args)
+	validateGroupBy(opts.groupBy)
+	validateFingerprint(opts.fingerprint)
+
+	if *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "--interval must be positive")
+		os.Exit(1)
+	}
+	if *staleAfter < 0 {
+		fmt.Fprintln(os.Stderr, "--stale-after must not be negative")
+		os.Exit(1)
+	}
+	if *staleAfter == 0 {
+		*staleAfter = 3 * *interval
+	}
+
+	cfg, err := loadScanConfig(*configPath, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	rules, err := compileRules(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	opts.rules = rules
+
+	if *eventsFile != "" {
+		events, err := reporting.OpenEventLog(*eventsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer events.Close()
+		opts.events = events
+	}
+
+	// systemd restarts the unit if it goes longer than the watchdog timeout
+	// without a ping. A single audit can outlast that, so the audit pings
+	// as it makes progress (files walked, HTTP requests answered, phases
+	// finished) and the loop pings while it sleeps; an audit that hangs
+	// stops pinging and gets restarted.
+	var beat <-chan time.Time
+	if wd, ok := utils.SdWatchdogInterval(); ok {
+		opts.watchdog = utils.NewWatchdog(wd, func() { sdNotify("WATCHDOG=1") })
+		cfg.HTTP.Transport = heartbeatTransport{base: cfg.HTTP.Transport, watchdog: opts.watchdog}
+		ticker := time.NewTicker(wd / 2)
+		defer ticker.Stop()
+		beat = ticker.C
+	}
+
+	if q := cfg.Notifications.QuietHours; q.Enabled() {
+		opts.quietHours = &quietHours{cfg: q}
+	}
+
+	fmt.Printf("Watching with an audit every %s\n", *interval)
+
+	status := reporting.WatchStatus{Started: time.Now()}
+	ready := false
+	for {
+		result := runAudit(ctx, cfg, *opts)
+		if ctx.Err() != nil {
+			break
+		}
+
+		now := time.Now()
+		status.LastRun = now
+		status.LastRunOK = !result.FilesystemFailed && len(result.FailedServices()) == 0
+		if status.LastRunOK {
+			status.LastSuccess = now
+		} else if since := status.SinceLastSuccess(now); since > *staleAfter {
+			fmt.Fprintf(os.Stderr, "Warning: no audit has succeeded in %s; reports are stale\n", since.Truncate(time.Second))
+		}
+		if *metricsFile != "" {
+			if err := reporting.WriteWatchMetrics(*metricsFile, status, now); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+
+		if !ready {
+			sdNotify("READY=1")
+			ready = true
+		}
+		opts.watchdog.Beat()
+
+		next := time.After(*interval)
+		if catchUp, ok := opts.quietHours.catchUpBefore(now.Add(*interval)); ok {
+			if sleepUntil(ctx, time.After(time.Until(catchUp)), beat, opts.watchdog) {
+				opts.quietHours.flush(cfg, opts.label, opts.verbose)
+			}
+		}
+		if !sleepUntil(ctx, next, beat, opts.watchdog) {
+			break
+		}
+	}
+
+	sdNotify("STOPPING=1")
+	fmt.Println("Watch stopped")
+}
+
+// sleepUntil waits for wake, beating the watchdog on every tick of beat. It
+// returns false when ctx is cancelled first.
+func sleepUntil(ctx context.Context, wake, beat <-chan time.Time, watchdog *utils.Watchdog) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-wake:
+			return true
+		case <-beat:
+			watchdog.Beat()
+		}
+	}
+}
+
+// heartbeatTransport beats the watchdog after every request, so a long
+// network phase counts as progress while a hung request does not.
+type heartbeatTransport struct {
+	base     http.RoundTripper
+	watchdog *utils.Watchdog
+}
+
+func (t heartbeatTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	t.watchdog.Beat()
+	return resp, err
+}
+
+func sdNotify(state string) {
+	if _, err := utils.SdNotify(state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to notify systemd (%s): %v\n", state, err)
+	}
+}
+
+// quietHours holds watch notifications during [notifications].quiet_hours.
+// With catch_up, the latest held run is kept and sent once the window ends,
+// unless a later run outside the window notifies first. A nil *quietHours
+// holds nothing.
+type quietHours struct {
+	cfg  config.QuietHoursConfig
+	held *heldNotification
+}
+
+type heldNotification struct {
+	result     *analysis.AnalysisResult
+	reportPath string
+	duration   time.Duration
+}
+
+// hold reports whether a run finished at now falls in quiet hours, keeping it
+// for the catch-up if so. A run outside quiet hours drops any held run, since
+// its own notification supersedes it.
+func (q *quietHours) hold(now time.Time, result *analysis.AnalysisResult, reportPath string, duration time.Duration) bool {
+	if q == nil {
+		return false
+	}
+	if !q.cfg.Active(now) {
+		q.held = nil
+		return false
+	}
+	if q.cfg.CatchUp {
+		q.held = &heldNotification{result: result, reportPath: reportPath, duration: duration}
+	}
+	return true
+}
+
+// catchUpBefore returns when the held run should be sent, if that is before
+// the next audit at deadline.
+func (q *quietHours) catchUpBefore(deadline time.Time) (time.Time, bool) {
+	if q == nil || q.held == nil {
+		return time.Time{}, false
+	}
+	end := q.cfg.NextEnd(time.Now())
+	return end, end.Before(deadline)
+}
+
+// flush sends the held run as the quiet-hours catch-up.
+func (q *quietHours) flush(cfg *config.Config, label string, verbose bool) {
+	held := q.held
+	q.held = nil
+	if held == nil {
+		return
+	}
+	if label != "" {
+		label += " "
+	}
+	fmt.Println("Quiet hours over: sending the held notification")
+	sendNotifications(cfg, held.result, held.reportPath, held.duration, label+"(quiet-hours catch-up)", verbose)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestConfig writes a minimal config scanning mediaRoot and returns its
+// path.
+func writeTestConfig(t *testing.T, mediaRoot, extra string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[paths]\nmedia_root = \"" + mediaRoot + "\"\n" + extra
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// watchOptions parses args with the flags runWatch binds.
+func watchOptions(t *testing.T, args ...string) scanOptions {
+	t.Helper()
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	opts := bindScanFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return *opts
+}
+
+func TestLoadScanConfig_WatchAppliesArrFlags(t *testing.T) {
+	path := writeTestConfig(t, t.TempDir(), "")
+
+	cfg, err := loadScanConfig(path, watchOptions(t))
+	if err != nil {
+		t.Fatalf("loadScanConfig: %v", err)
+	}
+	if cfg.HTTP.ArrMaxFailures != 5 {
+		t.Errorf("ArrMaxFailures = %d, want the default breaker of 5", cfg.HTTP.ArrMaxFailures)
+	}
+
+	cfg, err = loadScanConfig(path, watchOptions(t, "--arr-max-failures", "2", "--arr-timeout-per-request", "5s"))
+	if err != nil {
+		t.Fatalf("loadScanConfig: %v", err)
+	}
+	if cfg.HTTP.ArrMaxFailures != 2 || cfg.HTTP.ArrRequestTimeout != 5*time.Second {
+		t.Errorf("got breaker %d and timeout %s, want 2 and 5s", cfg.HTTP.ArrMaxFailures, cfg.HTTP.ArrRequestTimeout)
+	}
+}
+
+func TestLoadScanConfig_WatchChecksReportFile(t *testing.T) {
+	media := t.TempDir()
+	report := filepath.Join(media, "latest.md")
+
+	cfg, err := loadScanConfig(writeTestConfig(t, media, ""), watchOptions(t, "--report-file", report))
+	if err != nil {
+		t.Fatalf("loadScanConfig: %v", err)
+	}
+	for _, want := range []string{report, filepath.Join(media, "latest.json")} {
+		if !slices.Contains(cfg.Permissions.SkipPaths, want) {
+			t.Errorf("skip_paths = %q, want it to include %s", cfg.Permissions.SkipPaths, want)
+		}
+	}
+
+	strict := writeTestConfig(t, media, "[outputs]\nreport_dir_overlap = \"error\"\n")
+	if _, err := loadScanConfig(strict, watchOptions(t, "--report-file", report)); err == nil || !strings.Contains(err.Error(), "--report-file") {
+		t.Errorf("err = %v, want a --report-file overlap error", err)
+	}
+}
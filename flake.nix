@@ -20,7 +20,7 @@
             src = ./.;
             
             # Hash of go modules (computed by nix)
-            vendorHash = "sha256-pbA/AlBz3cQYRTMnQ/qBPcinYOKokrBLNhkbRTq54gE=";
+            vendorHash = "sha256-M9WhH7B4+K08sg65UItW75AS/LE3038Y+co96rFWhGo=";
             
             meta = with pkgs.lib; {
               description = "Non-destructive audit tool for Arr media libraries";
@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// BaselineEntry is an acknowledged finding. A baseline file may list plain
+// path strings or objects carrying the extra metadata.
+type BaselineEntry struct {
+	Path      string    `json:"path"`
+	Reason    string    `json:"reason,omitempty"`
+	AddedAt   time.Time `json:"added_at,omitzero"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+func (be *BaselineEntry) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*be = BaselineEntry{Path: path}
+		return nil
+	}
+
+	type entry BaselineEntry
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	*be = BaselineEntry(e)
+	return nil
+}
+
+// Baseline is a set of known-acceptable paths excluded from orphan, at-risk
+// and suspicious findings.
+type Baseline struct {
+	Entries []BaselineEntry
+	paths   map[string]struct{}
+}
+
+func NewBaseline(entries []BaselineEntry) *Baseline {
+	return &Baseline{Entries: entries}
+}
+
+// LoadBaseline reads a baseline file. A missing file yields an empty baseline
+// so that a configured-but-not-yet-created file is not an error.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewBaseline(nil), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	var entries []BaselineEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file %s: %w", path, err)
+	}
+
+	return NewBaseline(entries), nil
+}
+
+func (be BaselineEntry) expired(now time.Time) bool {
+	return !be.ExpiresAt.IsZero() && now.After(be.ExpiresAt)
+}
+
+// Add acknowledges a path, replacing any existing entry for it. Entries that
+// have already expired are dropped at the same time.
+func (b *Baseline) Add(entry BaselineEntry) {
+	now := time.Now()
+	kept := b.Entries[:0]
+	for _, existing := range b.Entries {
+		if existing.Path == entry.Path || existing.expired(now) {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	b.Entries = append(kept, entry)
+}
+
+// Save writes the baseline atomically so a concurrent scan never reads a
+// partially written file.
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b.Entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	return nil
+}
+
+func (b *Baseline) index(normalize func(string) string) {
+	now := time.Now()
+	b.paths = make(map[string]struct{}, len(b.Entries))
+	for _, entry := range b.Entries {
+		if entry.expired(now) {
+			continue
+		}
+		b.paths[normalize(entry.Path)] = struct{}{}
+	}
+}
+
+func (b *Baseline) contains(key string) bool {
+	if b == nil {
+		return false
+	}
+	_, ok := b.paths[key]
+	return ok
+}
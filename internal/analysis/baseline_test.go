@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestLoadBaseline_AcceptsPathsAndObjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	data := `["/media/movies/Keep.mkv", {"path": "/media/tv/Show/S01E01.mkv", "reason": "archive copy"}]`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline: %v", err)
+	}
+	if len(b.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(b.Entries))
+	}
+	if b.Entries[1].Reason != "archive copy" {
+		t.Errorf("reason = %q, want %q", b.Entries[1].Reason, "archive copy")
+	}
+}
+
+func TestLoadBaseline_MissingFileIsEmpty(t *testing.T) {
+	b, err := LoadBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadBaseline: %v", err)
+	}
+	if len(b.Entries) != 0 {
+		t.Errorf("got %d entries, want 0", len(b.Entries))
+	}
+}
+
+func TestAnalyze_BaselineSuppressesOrphans(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	e := &Engine{}
+	e.SetBaseline(NewBaseline([]BaselineEntry{{Path: "/media/movies/Keep.mkv"}}))
+
+	result := e.Analyze([]models.MediaFile{
+		{Path: "/media/movies/Keep.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/media/movies/Stray.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+	}, nil, nil, nil, nil)
+
+	if result.Summary.OrphanCount != 1 {
+		t.Errorf("OrphanCount = %d, want 1", result.Summary.OrphanCount)
+	}
+	if result.Summary.BaselineSuppressed != 1 {
+		t.Errorf("BaselineSuppressed = %d, want 1", result.Summary.BaselineSuppressed)
+	}
+}
+
+func TestBaseline_ExpiredEntriesLapse(t *testing.T) {
+	e := &Engine{}
+	e.SetBaseline(NewBaseline([]BaselineEntry{
+		{Path: "/media/movies/Old.mkv", ExpiresAt: time.Now().Add(-time.Hour)},
+	}))
+
+	if e.baseline.contains(e.normalizePath("/media/movies/Old.mkv")) {
+		t.Error("expired baseline entry still suppresses its path")
+	}
+}
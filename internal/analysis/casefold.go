@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// CaseDuplicate is a set of sibling directories or files whose names differ
+// only by case, e.g. "Show" and "show". Arr, the download client and a
+// case-insensitive client mount can each resolve these differently, so they
+// usually point to a path-mapping or import bug.
+type CaseDuplicate struct {
+	Parent string
+	// Names are the distinct spellings found, sorted.
+	Names []string
+	IsDir bool
+}
+
+// findCaseDuplicates checks every scanned file and each of its ancestor
+// directories for siblings that collide when case-folded. Only names seen in
+// the scan are compared; nothing extra is read from disk.
+func (e *Engine) findCaseDuplicates(files []models.MediaFile) []CaseDuplicate {
+	type key struct {
+		parent string
+		folded string
+		isDir  bool
+	}
+	spellings := make(map[key]map[string]bool)
+	add := func(p string, isDir bool) {
+		parent, name := filepath.Dir(p), filepath.Base(p)
+		k := key{parent, strings.ToLower(name), isDir}
+		if spellings[k] == nil {
+			spellings[k] = make(map[string]bool)
+		}
+		spellings[k][name] = true
+	}
+
+	seenDirs := make(map[string]bool)
+	for _, f := range files {
+		if shouldSkip(f.Path, e.skipPaths) {
+			continue
+		}
+		add(f.Path, false)
+		for dir := filepath.Dir(f.Path); !seenDirs[dir] && filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+			seenDirs[dir] = true
+			add(dir, true)
+		}
+	}
+
+	var result []CaseDuplicate
+	for k, names := range spellings {
+		if len(names) < 2 {
+			continue
+		}
+		dup := CaseDuplicate{Parent: k.parent, IsDir: k.isDir}
+		for n := range names {
+			dup.Names = append(dup.Names, n)
+		}
+		sort.Strings(dup.Names)
+		result = append(result, dup)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Parent != result[j].Parent {
+			return result[i].Parent < result[j].Parent
+		}
+		return result[i].Names[0] < result[j].Names[0]
+	})
+	return result
+}
@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// classifyChunkSize is how many files a worker classifies per job. Chunks keep
+// channel traffic low relative to the cheap per-file work.
+const classifyChunkSize = 1024
+
+// fileOutcome is the result of classifying a single scanned file. Outcomes
+// are computed independently and merged in input order by Analyze.
+type fileOutcome struct {
+	// counted is false for files under a skip path, which are ignored entirely.
+	counted    bool
+	size       int64
+	blockSize  int64
+	suppressed bool
+	mismatch   *models.SizeMismatch
+	classified *models.ClassifiedMedia
+	suspicious []models.SuspiciousFile
+}
+
+type classifyChunk struct {
+	start    int
+	outcomes []fileOutcome
+}
+
+// classifyAll classifies mediaFiles across a worker pool. The returned slice
+// is in the same order as mediaFiles regardless of scheduling, so reports
+// stay stable. Workers only read shared state (lookups, rules, baseline).
+func (e *Engine) classifyAll(mediaFiles []models.MediaFile, arrLookup arrLookupIndex, torrentIdx map[string][]string) []fileOutcome {
+	workers := e.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := (len(mediaFiles) + classifyChunkSize - 1) / classifyChunkSize
+	if workers > chunks {
+		workers = chunks
+	}
+
+	queue := e.queueSize
+	if queue <= 0 {
+		queue = workers
+	}
+	jobs := make(chan int, queue)
+	results := make(chan classifyChunk, queue)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for start := range jobs {
+				end := min(start+classifyChunkSize, len(mediaFiles))
+				outcomes := make([]fileOutcome, 0, end-start)
+				for _, media := range mediaFiles[start:end] {
+					outcomes = append(outcomes, e.classifyFile(media, arrLookup, torrentIdx))
+				}
+				results <- classifyChunk{start: start, outcomes: outcomes}
+			}
+		}()
+	}
+
+	go func() {
+		for start := 0; start < len(mediaFiles); start += classifyChunkSize {
+			jobs <- start
+		}
+		close(jobs)
+	}()
+
+	collected := make([]classifyChunk, 0, chunks)
+	for i := 0; i < chunks; i++ {
+		collected = append(collected, <-results)
+	}
+	sort.Slice(collected, func(i, j int) bool {
+		return collected[i].start < collected[j].start
+	})
+
+	all := make([]fileOutcome, 0, len(mediaFiles))
+	for _, c := range collected {
+		all = append(all, c.outcomes...)
+	}
+	return all
+}
+
+func (e *Engine) classifyFile(media models.MediaFile, arrLookup arrLookupIndex, torrentIdx map[string][]string) fileOutcome {
+	if shouldSkip(media.Path, e.skipPaths) {
+		return fileOutcome{}
+	}
+	out := fileOutcome{counted: true, size: media.Size, blockSize: media.BlockSize}
+
+	lookupKey := e.normalizePath(media.Path)
+	arrFile := arrLookup.find(lookupKey, media.Path)
+
+	// Protected paths are excluded from suspicious checks too, so this
+	// comes before checkSuspicious.
+	if e.isProtected(media.Path) {
+		out.classified = &models.ClassifiedMedia{
+			File:           media,
+			KnownToArr:     arrFile != nil && arrFile.IsKnown(),
+			Classification: models.MediaHealthy,
+			Code:           models.ReasonProtected,
+			Reason:         "Under a protected path",
+		}
+		return out
+	}
+	out.suspicious = e.checkSuspicious(media)
+
+	graceHours := e.getGraceHours(arrFile, media.Source)
+
+	classification, shouldInclude, rule := ClassifyByRules(e.rules, media, arrFile, graceHours)
+	if rule == nil {
+		switch media.Source {
+		case models.MediaSourceExtra:
+			classification, shouldInclude = ClassifyExtraFile(media)
+		case models.MediaSourceTorrent:
+			inActiveTorrent := e.belongsToActiveTorrent(media.Path, torrentIdx)
+			classification, shouldInclude = ClassifyTorrentFile(media, arrFile, graceHours, inActiveTorrent)
+		default:
+			classification, shouldInclude = ClassifyMedia(media, arrFile, graceHours)
+		}
+	}
+
+	if !shouldInclude {
+		return out
+	}
+
+	if classification == models.MediaOrphan && utils.IsSubtitleFile(media.Path) {
+		return out
+	}
+
+	if classification == models.MediaOrphan && rule == nil && e.isExpectedUntracked(media.Path) {
+		classification = models.MediaExpectedUntracked
+	} else if classification == models.MediaOrphan && rule == nil && e.previouslyTracked[lookupKey] {
+		classification = models.MediaRemovedFromArr
+	} else if classification == models.MediaOrphan && rule == nil && e.orphanIgnoreExts[models.Ext(media.Path)] {
+		classification = models.MediaOtherFile
+	}
+
+	switch classification {
+	case models.MediaOrphan, models.MediaAtRisk, models.MediaRemovedFromArr:
+		if e.baseline.contains(lookupKey) {
+			out.suppressed = true
+			return out
+		}
+	}
+
+	arrSource := ""
+	if arrFile != nil && arrFile.Service != "" {
+		arrSource = arrFile.Service
+	} else if arrFile != nil && arrFile.SeriesID > 0 {
+		arrSource = "sonarr"
+	} else if arrFile != nil && arrFile.MovieID > 0 {
+		arrSource = "radarr"
+	}
+
+	if media.Source == models.MediaSourceLibrary && sizeMismatch(media, arrFile) {
+		out.mismatch = &models.SizeMismatch{
+			Path:      media.Path,
+			ArrSource: arrSource,
+			DiskSize:  media.Size,
+			ArrSize:   arrFile.Size,
+		}
+	}
+
+	code, reason := getReason(classification, media, arrFile)
+	if rule != nil {
+		code = models.ReasonCustomRule
+		reason = fmt.Sprintf("Matched custom rule %q", rule.Name)
+	}
+
+	out.classified = &models.ClassifiedMedia{
+		File:           media,
+		KnownToArr:     arrFile != nil && arrFile.IsKnown(),
+		ArrSource:      arrSource,
+		Classification: classification,
+		Code:           code,
+		Reason:         reason,
+	}
+	return out
+}
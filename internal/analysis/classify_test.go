@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func syntheticLibrary(n int) ([]models.MediaFile, []models.ArrFile, []models.Torrent) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	media := make([]models.MediaFile, 0, n)
+	var arr []models.ArrFile
+	var torrentFiles []string
+	for i := 0; i < n; i++ {
+		switch i % 4 {
+		case 0, 1:
+			path := fmt.Sprintf("/mnt/media/tv/Show %d/Season 01/Show.S01E%03d.mkv", i/100, i%100)
+			media = append(media, models.MediaFile{Path: path, Size: 1 << 30, ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: i%4 == 0})
+			arr = append(arr, models.ArrFile{Path: path, SeriesID: i/100 + 1, Size: 1 << 30})
+		case 2:
+			media = append(media, models.MediaFile{Path: fmt.Sprintf("/mnt/media/movies/Untracked %d.mkv", i), ModTime: old, Source: models.MediaSourceLibrary})
+		case 3:
+			name := fmt.Sprintf("Release.%d.mkv", i)
+			media = append(media, models.MediaFile{Path: "/mnt/torrents/tv/" + name, ModTime: old, Source: models.MediaSourceTorrent})
+			if i%8 == 3 {
+				torrentFiles = append(torrentFiles, name)
+			}
+		}
+	}
+	torrents := []models.Torrent{{SavePath: "/data/tv", Files: torrentFiles}}
+	return media, arr, torrents
+}
+
+func TestAnalyze_ParallelMatchesSerial(t *testing.T) {
+	media, arr, torrents := syntheticLibrary(10_000)
+
+	serial := &Engine{torrentRoot: "/mnt/torrents", workers: 1}
+	parallel := &Engine{torrentRoot: "/mnt/torrents", workers: 8, queueSize: 64}
+
+	want := serial.Analyze(media, arr, nil, torrents, nil)
+	got := parallel.Analyze(media, arr, nil, torrents, nil)
+
+	if !reflect.DeepEqual(want.ClassifiedMedia, got.ClassifiedMedia) {
+		t.Error("parallel classification differs from serial (order or content)")
+	}
+	if want.Summary != got.Summary {
+		t.Errorf("summary differs:\nserial   %+v\nparallel %+v", want.Summary, got.Summary)
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	media, arr, torrents := syntheticLibrary(200_000)
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			e := &Engine{torrentRoot: "/mnt/torrents", workers: workers}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				e.Analyze(media, arr, nil, torrents, nil)
+			}
+		})
+	}
+}
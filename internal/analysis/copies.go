@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// CopiedImport is an at-risk library file with a same-name, same-size file
+// under the torrent root on a different inode: the import copied the
+// download instead of hardlinking it, so the data is stored twice.
+type CopiedImport struct {
+	Path string
+	Size int64
+	// TorrentCopies are the torrent-root paths holding the duplicate data.
+	TorrentCopies []string
+	// WastedBytes is the space the copies take beyond a single file.
+	WastedBytes int64
+}
+
+// findCopiedImports matches at-risk library files against scanned torrent
+// files by lowercased name and exact size.
+func findCopiedImports(classified []models.ClassifiedMedia, mediaFiles []models.MediaFile) []CopiedImport {
+	type key struct {
+		name string
+		size int64
+	}
+	torrents := make(map[key][]models.MediaFile)
+	for _, f := range mediaFiles {
+		if f.Source != models.MediaSourceTorrent || f.Size == 0 {
+			continue
+		}
+		k := key{strings.ToLower(filepath.Base(f.Path)), f.Size}
+		torrents[k] = append(torrents[k], f)
+	}
+	if len(torrents) == 0 {
+		return nil
+	}
+
+	var result []CopiedImport
+	for _, cm := range classified {
+		if cm.Classification != models.MediaAtRisk {
+			continue
+		}
+		lib := cm.File
+		var copies []string
+		for _, t := range torrents[key{strings.ToLower(filepath.Base(lib.Path)), lib.Size}] {
+			if t.Inode != 0 && t.Device == lib.Device && t.Inode == lib.Inode {
+				continue
+			}
+			copies = append(copies, t.Path)
+		}
+		if len(copies) == 0 {
+			continue
+		}
+		sort.Strings(copies)
+		result = append(result, CopiedImport{
+			Path:          lib.Path,
+			Size:          lib.Size,
+			TorrentCopies: copies,
+			WastedBytes:   lib.Size * int64(len(copies)),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].WastedBytes != result[j].WastedBytes {
+			return result[i].WastedBytes > result[j].WastedBytes
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
@@ -2,7 +2,6 @@ package analysis
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -11,16 +10,38 @@ import (
 
 	"github.com/jdpx/auditarr/internal/models"
 	"github.com/jdpx/auditarr/internal/utils"
+	"golang.org/x/text/unicode/norm"
 )
 
 type AnalysisResult struct {
-	ClassifiedMedia     []models.ClassifiedMedia
-	SuspiciousFiles     []models.SuspiciousFile
-	UnlinkedTorrents    []models.Torrent
+	ClassifiedMedia  []models.ClassifiedMedia
+	SuspiciousFiles  []models.SuspiciousFile
+	UnlinkedTorrents []models.Torrent
+	// OutOfScopeTorrents are completed torrents saved outside every scanned
+	// root, which the unlinked check cannot evaluate.
+	OutOfScopeTorrents  []models.Torrent
 	PermissionIssues    []models.PermissionIssue
 	OrphanedDirectories []OrphanedDirectory
+	CorruptFiles        []models.CorruptFile
+	UnimportedDownloads []models.MediaFile
+	SizeMismatches      []models.SizeMismatch
+	ArrReconciliation   []ArrReconciliation
+	CaseDuplicates      []CaseDuplicate
+	CopiedImports       []CopiedImport
 	Summary             SummaryStats
 	ConnectionStatus    []ServiceStatus
+	// PartialSeasonPacks are torrent seasons with both imported and
+	// leftover episodes.
+	PartialSeasonPacks []SeasonPack
+	// Warnings are run-level problems that undermine the accuracy of the
+	// whole report, shown prominently ahead of the findings.
+	Warnings []string
+	// ArrUnmappedChecked is set when orphans were cross-checked against the
+	// Arr services' unmapped folders.
+	ArrUnmappedChecked bool
+	// FilesystemFailed is set when the filesystem walk failed, so the
+	// scanned files are missing or incomplete.
+	FilesystemFailed bool
 }
 
 type OrphanedDirectory struct {
@@ -36,6 +57,54 @@ type ServiceStatus struct {
 	Enabled bool
 	OK      bool
 	Error   string
+	// Reason categorizes a failure, e.g. "Auth failed" or "Unreachable".
+	Reason string
+	// Degraded is set when the circuit breaker stopped requests to the
+	// service mid-run; SkippedFetches counts the requests it refused.
+	Degraded       bool
+	SkippedFetches int
+	// FailedFetches describes per-item requests that failed and were
+	// skipped, so the service's data is incomplete even though it answered.
+	FailedFetches []string
+}
+
+// FailedServices lists the enabled services that could not be reached, with
+// the failure reason when known, e.g. "Sonarr (Auth failed)".
+func (r *AnalysisResult) FailedServices() []string {
+	var failed []string
+	for _, svc := range r.ConnectionStatus {
+		if !svc.Enabled || svc.OK {
+			continue
+		}
+		if svc.Reason != "" {
+			failed = append(failed, fmt.Sprintf("%s (%s)", svc.Name, svc.Reason))
+		} else {
+			failed = append(failed, svc.Name)
+		}
+	}
+	return failed
+}
+
+// IncompleteCollection lists every way the audit's input is incomplete: a
+// failed filesystem walk, enabled services that failed, and skipped
+// per-item fetches. scan --strict fails the run when it is non-empty.
+func (r *AnalysisResult) IncompleteCollection() []string {
+	var problems []string
+	if r.FilesystemFailed {
+		problems = append(problems, "filesystem walk failed")
+	}
+	for _, svc := range r.ConnectionStatus {
+		if !svc.Enabled {
+			continue
+		}
+		if !svc.OK {
+			problems = append(problems, fmt.Sprintf("%s: %s", svc.Name, svc.Error))
+		}
+		for _, f := range svc.FailedFetches {
+			problems = append(problems, fmt.Sprintf("%s: failed to fetch %s", svc.Name, f))
+		}
+	}
+	return problems
 }
 
 type SummaryStats struct {
@@ -46,19 +115,41 @@ type SummaryStats struct {
 	OrphanedDownloadCount int
 	HiddenFileCount       int
 	LostAndFoundCount     int
-	SuspiciousCount       int
-	PermissionErrors      int
-	PermissionWarnings    int
-	TotalLogicalSize      int64
-	TotalBlockSize        int64
-	Duration              time.Duration
+	OrphanedSidecarCount  int
+	OtherFileCount        int
+	// ExpectedUntrackedCount counts untracked files under
+	// expected_untracked_paths; they are not orphans.
+	ExpectedUntrackedCount int
+	RemovedFromArrCount    int
+	SuspiciousCount        int
+	CorruptCount           int
+	UnimportedCount        int
+	UnimportedSize         int64
+	SizeMismatchCount      int
+	CaseDuplicateCount     int
+	CopiedImportCount      int
+	CopiedImportWaste      int64
+	ArrConfirmedOrphans    int
+	OutOfScopeTorrentCount int
+	PartialSeasonPackCount int
+	VerifiedCount          int
+	PermissionErrors       int
+	PermissionWarnings     int
+	BaselineSuppressed     int
+	TotalLogicalSize       int64
+	TotalBlockSize         int64
+	Duration               time.Duration
 }
 
 type Engine struct {
 	sonarrGraceHours      int
 	radarrGraceHours      int
 	qbittorrentGraceHours int
+	categoryGraceHours    map[string]int
+	includeUnknownState   bool
 	suspiciousExtensions  []string
+	suspiciousSeverities  map[string]string
+	maxHardlinks          int
 	flagArchives          bool
 	permissionsEnabled    bool
 	expectedGroupGID      int
@@ -66,8 +157,22 @@ type Engine struct {
 	sgidPaths             []string
 	skipPaths             []string
 	nonstandardSeverity   string
+	expectedFileMode      uint32
+	expectedDirMode       uint32
 	pathMappings          map[string]string
 	torrentRoot           string
+	scanRoots             []string
+	baseline              *Baseline
+	rules                 []ClassificationRule
+	workers               int
+	queueSize             int
+	reflinks              string
+	sharesExtents         func(a, b string) bool
+	protectedPaths        []string
+	expectedUntracked     []string
+	arrUnmapped           []string
+	orphanIgnoreExts      map[string]bool
+	previouslyTracked     map[string]bool
 }
 
 func NewEngine(
@@ -100,6 +205,89 @@ func NewEngine(
 	}
 }
 
+// SetExpectedModes configures the permission bits files and directories are
+// expected to carry. Deviations are reported as nonstandard_permissions at the
+// configured nonstandard severity. A zero mode disables the check.
+func (e *Engine) SetExpectedModes(fileMode, dirMode uint32) {
+	e.expectedFileMode = fileMode
+	e.expectedDirMode = dirMode
+}
+
+// SetBaseline supplies acknowledged paths that are suppressed from orphan,
+// at-risk and suspicious findings.
+func (e *Engine) SetBaseline(b *Baseline) {
+	e.baseline = b
+	if b != nil {
+		b.index(e.normalizePath)
+	}
+}
+
+// SetRules installs custom classification rules evaluated before the
+// built-in logic.
+func (e *Engine) SetRules(rules []ClassificationRule) {
+	e.rules = rules
+}
+
+// SetIncludeUnknownTorrents makes torrents in StateUnknown eligible for
+// unlinked torrent analysis. They are skipped by default, since an
+// unrecognized state may be one that is still downloading.
+func (e *Engine) SetIncludeUnknownTorrents(include bool) {
+	e.includeUnknownState = include
+}
+
+// SetTorrentCategoryGraceHours gives torrents in the named qBittorrent
+// categories their own grace window in unlinked torrent analysis, e.g. for
+// manual imports that take longer than automated ones. Other torrents use
+// the qBittorrent grace hours.
+func (e *Engine) SetTorrentCategoryGraceHours(hours map[string]int) {
+	e.categoryGraceHours = hours
+}
+
+// SetScanRoots supplies the directories the filesystem walk covers. A
+// completed torrent whose save path, after path mapping, is under none of
+// them is reported as out of scope rather than unlinked, since its files were
+// never scanned. Without roots every torrent is in scope.
+func (e *Engine) SetScanRoots(roots []string) {
+	e.scanRoots = roots
+}
+
+// SetOrphanIgnoreExtensions makes untracked library files with one of exts
+// "other files" rather than orphans.
+func (e *Engine) SetOrphanIgnoreExtensions(exts []string) {
+	e.orphanIgnoreExts = make(map[string]bool, len(exts))
+	for _, ext := range exts {
+		e.orphanIgnoreExts[models.NormalizeExtension(ext)] = true
+	}
+}
+
+// SetPreviouslyTracked supplies the files Arr tracked in the previous run.
+// Orphans among them are classified as removed from Arr.
+func (e *Engine) SetPreviouslyTracked(paths []string) {
+	e.previouslyTracked = make(map[string]bool, len(paths))
+	for _, p := range paths {
+		e.previouslyTracked[e.normalizePath(p)] = true
+	}
+}
+
+// SetWorkers sets how many goroutines classify files. Zero or negative uses
+// GOMAXPROCS.
+func (e *Engine) SetWorkers(n int) {
+	e.workers = n
+}
+
+// SetReflinks makes files whose data is shared by reflink count as linked:
+// ReflinksDetect checks extents, ReflinksAssume trusts every library file.
+// Any other value relies on hardlinks alone.
+func (e *Engine) SetReflinks(mode string) {
+	e.reflinks = mode
+}
+
+// SetQueueSize buffers the classification job and result channels. Zero or
+// negative sizes them to the worker count.
+func (e *Engine) SetQueueSize(n int) {
+	e.queueSize = n
+}
+
 func (e *Engine) Analyze(
 	mediaFiles []models.MediaFile,
 	sonarrFiles []models.ArrFile,
@@ -110,58 +298,45 @@ func (e *Engine) Analyze(
 	result := &AnalysisResult{}
 
 	arrLookup := e.buildArrLookup(sonarrFiles, radarrFiles)
+	if n := arrLookup.collisions(); n > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"%d path(s) are claimed by more than one Arr record after path mapping and case-folding. "+
+				"Each file is matched to the record whose path matches exactly; check [path_mappings] if this is unexpected.", n))
+	}
 	torrentFileIndex := e.buildTorrentFileIndex(torrents)
 
-	for _, media := range mediaFiles {
-		if shouldSkip(media.Path, e.skipPaths) {
+	result.CaseDuplicates = e.findCaseDuplicates(mediaFiles)
+	result.Summary.CaseDuplicateCount = len(result.CaseDuplicates)
+
+	var sidecars []models.MediaFile
+	mediaFiles, sidecars = splitSidecars(mediaFiles)
+	mediaFiles = e.markReflinks(mediaFiles)
+
+	for _, out := range e.classifyAll(mediaFiles, arrLookup, torrentFileIndex) {
+		if !out.counted {
 			continue
 		}
 
 		// Track disk usage stats for all files
-		result.Summary.TotalLogicalSize += media.Size
-		result.Summary.TotalBlockSize += media.BlockSize
+		result.Summary.TotalLogicalSize += out.size
+		result.Summary.TotalBlockSize += out.blockSize
 
-		lookupKey := e.normalizePath(media.Path)
-		arrFile := arrLookup[lookupKey]
-		graceHours := e.getGraceHours(arrFile, media.Source)
+		result.SuspiciousFiles = append(result.SuspiciousFiles, out.suspicious...)
 
-		var classification models.MediaClassification
-		var shouldInclude bool
-
-		switch media.Source {
-		case models.MediaSourceExtra:
-			classification, shouldInclude = ClassifyExtraFile(media)
-		case models.MediaSourceTorrent:
-			inActiveTorrent := e.belongsToActiveTorrent(media.Path, torrentFileIndex)
-			classification, shouldInclude = ClassifyTorrentFile(media, arrFile, graceHours, inActiveTorrent)
-		default:
-			classification, shouldInclude = ClassifyMedia(media, arrFile, graceHours)
-		}
-
-		if !shouldInclude {
+		if out.suppressed {
+			result.Summary.BaselineSuppressed++
 			continue
 		}
-
-		if classification == models.MediaOrphan && utils.IsSubtitleFile(media.Path) {
-			continue
+		if out.mismatch != nil {
+			result.SizeMismatches = append(result.SizeMismatches, *out.mismatch)
 		}
-
-		arrSource := ""
-		if arrFile != nil && arrFile.SeriesID > 0 {
-			arrSource = "sonarr"
-		} else if arrFile != nil && arrFile.MovieID > 0 {
-			arrSource = "radarr"
+		if out.classified == nil {
+			continue
 		}
 
-		result.ClassifiedMedia = append(result.ClassifiedMedia, models.ClassifiedMedia{
-			File:           media,
-			KnownToArr:     arrFile != nil && arrFile.IsKnown(),
-			ArrSource:      arrSource,
-			Classification: classification,
-			Reason:         getReason(classification, media, arrFile),
-		})
+		result.ClassifiedMedia = append(result.ClassifiedMedia, *out.classified)
 
-		switch classification {
+		switch out.classified.Classification {
 		case models.MediaHealthy:
 			result.Summary.HealthyCount++
 		case models.MediaAtRisk:
@@ -174,21 +349,94 @@ func (e *Engine) Analyze(
 			result.Summary.HiddenFileCount++
 		case models.MediaLostAndFound:
 			result.Summary.LostAndFoundCount++
+		case models.MediaOtherFile:
+			result.Summary.OtherFileCount++
+		case models.MediaExpectedUntracked:
+			result.Summary.ExpectedUntrackedCount++
+		case models.MediaRemovedFromArr:
+			result.Summary.RemovedFromArrCount++
+		}
+		result.Summary.TotalFiles++
+	}
+
+	for _, sc := range findOrphanedSidecars(sidecars, mediaFiles) {
+		if shouldSkip(sc.Path, e.skipPaths) || e.isProtected(sc.Path) {
+			continue
 		}
+		if e.baseline.contains(e.normalizePath(sc.Path)) {
+			result.Summary.BaselineSuppressed++
+			continue
+		}
+		result.ClassifiedMedia = append(result.ClassifiedMedia, models.ClassifiedMedia{
+			File:           sc,
+			Classification: models.MediaOrphanedSidecar,
+			Code:           models.ReasonSidecarNoVideo,
+			Reason:         sidecarReason(sc.Path),
+		})
+		result.Summary.OrphanedSidecarCount++
 		result.Summary.TotalFiles++
 	}
 
+	if e.baseline != nil {
+		var kept []models.SuspiciousFile
+		for _, sf := range result.SuspiciousFiles {
+			if e.baseline.contains(e.normalizePath(sf.Path)) {
+				result.Summary.BaselineSuppressed++
+				continue
+			}
+			kept = append(kept, sf)
+		}
+		result.SuspiciousFiles = kept
+	}
+	result.Summary.SuspiciousCount = len(result.SuspiciousFiles)
+
+	result.Summary.SizeMismatchCount = len(result.SizeMismatches)
+
+	scanned := make(map[string]bool, len(mediaFiles))
+	for _, media := range mediaFiles {
+		scanned[e.normalizePath(media.Path)] = true
+	}
+	result.ArrReconciliation = reconcileArr(sonarrFiles, radarrFiles, e.pathMappings, e.skipPaths, func(fsPath string) bool {
+		return scanned[e.normalizePath(fsPath)]
+	})
+
+	links := e.buildHardlinkIndex(mediaFiles)
+	result.UnimportedDownloads = e.findUnimportedDownloads(mediaFiles, links)
+	result.Summary.UnimportedCount = len(result.UnimportedDownloads)
+	for _, f := range result.UnimportedDownloads {
+		result.Summary.UnimportedSize += f.Size
+	}
+
+	result.CopiedImports = findCopiedImports(result.ClassifiedMedia, mediaFiles)
+	result.Summary.CopiedImportCount = len(result.CopiedImports)
+	for _, c := range result.CopiedImports {
+		result.Summary.CopiedImportWaste += c.WastedBytes
+	}
+
+	if e.arrUnmapped != nil {
+		result.ArrUnmappedChecked = true
+		result.Summary.ArrConfirmedOrphans = e.confirmOrphans(result.ClassifiedMedia)
+	}
+
 	// Build directory-level orphan summary
 	result.OrphanedDirectories = e.buildOrphanedDirectories(result.ClassifiedMedia)
 
 	for _, t := range torrents {
-		if t.State == models.StateCompleted && !t.WithinGraceWindow(e.qbittorrentGraceHours) {
-			if !e.hasMatchingMediaFile(t, arrLookup) {
+		complete := t.IsComplete() || (e.includeUnknownState && t.State == models.StateUnknown)
+		if complete && !t.WithinGraceWindow(e.torrentGraceHours(t)) {
+			if !e.inScanRoots(t.SavePath) {
+				result.OutOfScopeTorrents = append(result.OutOfScopeTorrents, t)
+			} else if !e.hasMatchingMediaFile(t, arrLookup, links) {
 				result.UnlinkedTorrents = append(result.UnlinkedTorrents, t)
 			}
 		}
 	}
 
+	result.Summary.OutOfScopeTorrentCount = len(result.OutOfScopeTorrents)
+
+	result.PartialSeasonPacks = e.findPartialSeasonPacks(torrents, mediaFiles)
+	result.Summary.PartialSeasonPackCount = len(result.PartialSeasonPacks)
+
 	if e.permissionsEnabled {
 		for _, perm := range permissions {
 			if shouldSkip(perm.Path, e.skipPaths) {
@@ -197,10 +445,14 @@ func (e *Engine) Analyze(
 			issues := e.auditPermissions(perm)
 			result.PermissionIssues = append(result.PermissionIssues, issues...)
 			for _, issue := range issues {
-				if issue.Severity == "error" {
+				switch issue.Severity {
+				case "error":
 					result.Summary.PermissionErrors++
-				} else {
+				case "warning":
 					result.Summary.PermissionWarnings++
+				case "info":
+					// Deliberately uncounted: info issues are listed in
+					// the report but never raise the notification level.
 				}
 			}
 		}
@@ -209,6 +461,97 @@ func (e *Engine) Analyze(
 	return result
 }
 
+// sizeMismatchTolerance is the fraction by which the on-disk size may differ
+// from Arr's recorded size before it is reported. Arr only refreshes sizes on
+// a disk rescan, so tiny drifts (e.g. tag rewrites) are not worth flagging.
+const sizeMismatchTolerance = 0.01
+
+func sizeMismatch(media models.MediaFile, arrFile *models.ArrFile) bool {
+	if !arrFile.IsKnown() || arrFile.Size <= 0 {
+		return false
+	}
+	diff := media.Size - arrFile.Size
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff) > float64(arrFile.Size)*sizeMismatchTolerance
+}
+
+// splitSidecars separates sidecar files from the media to classify.
+func splitSidecars(files []models.MediaFile) (media, sidecars []models.MediaFile) {
+	media = make([]models.MediaFile, 0, len(files))
+	for _, f := range files {
+		if f.IsSidecar {
+			sidecars = append(sidecars, f)
+		} else {
+			media = append(media, f)
+		}
+	}
+	return media, sidecars
+}
+
+type inodeKey struct {
+	device uint64
+	inode  uint64
+}
+
+// hardlinkIndex matches scanned downloads to library files by device and
+// inode, which survive the rename Arr applies on import where names don't.
+type hardlinkIndex struct {
+	library map[inodeKey]struct{}
+	// downloads holds torrent-root files by normalized path.
+	downloads map[string]models.MediaFile
+}
+
+func (e *Engine) buildHardlinkIndex(mediaFiles []models.MediaFile) hardlinkIndex {
+	idx := hardlinkIndex{
+		library:   make(map[inodeKey]struct{}),
+		downloads: make(map[string]models.MediaFile),
+	}
+	for _, f := range mediaFiles {
+		switch {
+		case f.Source == models.MediaSourceLibrary && f.Inode != 0:
+			idx.library[inodeKey{f.Device, f.Inode}] = struct{}{}
+		case f.Source == models.MediaSourceTorrent:
+			idx.downloads[e.normalizePath(f.Path)] = f
+		}
+	}
+	return idx
+}
+
+// linked reports whether f shares its inode, or its data by reflink, with a
+// library file.
+func (idx hardlinkIndex) linked(f models.MediaFile) bool {
+	if f.IsReflinked {
+		return true
+	}
+	_, ok := idx.library[inodeKey{f.Device, f.Inode}]
+	return ok && f.Inode != 0
+}
+
+// findUnimportedDownloads returns torrent-root files whose inode is not
+// shared with any file under the media root: data that was downloaded but
+// never hardlinked into the library, regardless of what Arr knows about.
+func (e *Engine) findUnimportedDownloads(mediaFiles []models.MediaFile, links hardlinkIndex) []models.MediaFile {
+	var unimported []models.MediaFile
+	for _, f := range mediaFiles {
+		if f.Source != models.MediaSourceTorrent || f.IsHidden || f.IsReflinked || f.Inode == 0 {
+			continue
+		}
+		if shouldSkip(f.Path, e.skipPaths) || e.isProtected(f.Path) || f.WithinGraceWindow(e.qbittorrentGraceHours) {
+			continue
+		}
+		if !links.linked(f) {
+			unimported = append(unimported, f)
+		}
+	}
+
+	sort.Slice(unimported, func(i, j int) bool {
+		return unimported[i].Path < unimported[j].Path
+	})
+	return unimported
+}
+
 func (e *Engine) getGraceHours(arrFile *models.ArrFile, source models.MediaFileSource) int {
 	if arrFile == nil {
 		if source == models.MediaSourceTorrent {
@@ -216,6 +559,9 @@ func (e *Engine) getGraceHours(arrFile *models.ArrFile, source models.MediaFileS
 		}
 		return 0
 	}
+	if arrFile.GraceHours > 0 {
+		return arrFile.GraceHours
+	}
 	if arrFile.SeriesID > 0 {
 		return e.sonarrGraceHours
 	}
@@ -225,19 +571,58 @@ func (e *Engine) getGraceHours(arrFile *models.ArrFile, source models.MediaFileS
 	return 0
 }
 
-func (e *Engine) buildArrLookup(sonarrFiles, radarrFiles []models.ArrFile) map[string]*models.ArrFile {
-	lookup := make(map[string]*models.ArrFile)
+// arrCandidate is an Arr record together with its path after mapping to the
+// filesystem, used to disambiguate records that share a lookup key.
+type arrCandidate struct {
+	mappedPath string
+	file       *models.ArrFile
+}
+
+// arrLookupIndex maps a normalized (mapped, cleaned, lowercased) path to every
+// Arr record that resolves to it. Keys can collide through case-folding or
+// overlapping path mappings, so all candidates are kept rather than letting
+// the last one win.
+type arrLookupIndex map[string][]arrCandidate
+
+func (l arrLookupIndex) add(key, mappedPath string, f *models.ArrFile) {
+	l[key] = append(l[key], arrCandidate{mappedPath: mappedPath, file: f})
+}
+
+// find returns the record for a scanned file, preferring the candidate whose
+// mapped path matches fsPath exactly (including case).
+func (l arrLookupIndex) find(key, fsPath string) *models.ArrFile {
+	candidates := l[key]
+	if len(candidates) == 0 {
+		return nil
+	}
+	clean := filepath.Clean(fsPath)
+	for _, c := range candidates {
+		if c.mappedPath == clean {
+			return c.file
+		}
+	}
+	return candidates[0].file
+}
+
+func (l arrLookupIndex) collisions() int {
+	n := 0
+	for _, candidates := range l {
+		if len(candidates) > 1 {
+			n++
+		}
+	}
+	return n
+}
+
+func (e *Engine) buildArrLookup(sonarrFiles, radarrFiles []models.ArrFile) arrLookupIndex {
+	lookup := make(arrLookupIndex)
 	for i := range sonarrFiles {
 		normalizedPath := utils.NormalizePath(sonarrFiles[i].Path, e.pathMappings)
-		lookup[e.normalizePath(normalizedPath)] = &sonarrFiles[i]
+		lookup.add(e.normalizePath(normalizedPath), normalizedPath, &sonarrFiles[i])
 	}
 	for i := range radarrFiles {
 		normalizedPath := utils.NormalizePath(radarrFiles[i].Path, e.pathMappings)
-		lookup[e.normalizePath(normalizedPath)] = &radarrFiles[i]
-		if i == 0 {
-			fmt.Fprintf(os.Stderr, "DEBUG: First Radarr path: orig=%s mapped=%s lookup=%s\n",
-				radarrFiles[i].Path, normalizedPath, e.normalizePath(normalizedPath))
-		}
+		lookup.add(e.normalizePath(normalizedPath), normalizedPath, &radarrFiles[i])
 	}
 	return lookup
 }
@@ -281,13 +666,21 @@ func (e *Engine) belongsToActiveTorrent(hostPath string, idx map[string][]string
 	return false
 }
 
-func (e *Engine) hasMatchingMediaFile(t models.Torrent, mediaLookup map[string]*models.ArrFile) bool {
+// hasMatchingMediaFile reports whether any of t's files made it into the
+// library. A scanned download sharing its inode with a library file matches
+// however Arr renamed it; unscanned files fall back to their link count, and
+// then to Arr tracking the download path itself.
+func (e *Engine) hasMatchingMediaFile(t models.Torrent, mediaLookup arrLookupIndex, links hardlinkIndex) bool {
 	for _, f := range t.Files {
 		fullPath := filepath.Join(t.SavePath, f)
 
 		// Apply path mapping FIRST before checking hardlinks
 		normalizedPath := utils.NormalizePath(fullPath, e.pathMappings)
 
+		if scanned, ok := links.downloads[e.normalizePath(normalizedPath)]; ok && links.linked(scanned) {
+			return true
+		}
+
 		hardlinked := isHardlinked(normalizedPath)
 		if hardlinked {
 			return true
@@ -300,6 +693,30 @@ func (e *Engine) hasMatchingMediaFile(t models.Torrent, mediaLookup map[string]*
 	return false
 }
 
+// torrentGraceHours returns the grace window for t's category, falling back
+// to the qBittorrent grace hours.
+func (e *Engine) torrentGraceHours(t models.Torrent) int {
+	if hours, ok := e.categoryGraceHours[t.Category]; ok && t.Category != "" {
+		return hours
+	}
+	return e.qbittorrentGraceHours
+}
+
+// inScanRoots reports whether savePath, as qBittorrent reports it, maps to a
+// directory under one of the scan roots.
+func (e *Engine) inScanRoots(savePath string) bool {
+	if len(e.scanRoots) == 0 {
+		return true
+	}
+	mapped := utils.NormalizePath(savePath, e.pathMappings)
+	for _, root := range e.scanRoots {
+		if utils.IsUnderPath(mapped, root) {
+			return true
+		}
+	}
+	return false
+}
+
 func isHardlinked(path string) bool {
 	var stat syscall.Stat_t
 	err := syscall.Stat(path, &stat)
@@ -309,8 +726,12 @@ func isHardlinked(path string) bool {
 	return stat.Nlink > 1
 }
 
+// normalizePath returns the key paths are matched on: cleaned, NFC-normalized
+// and lowercased. Arr and the filesystem can spell the same accented name in
+// different Unicode forms (macOS and some SMB shares produce NFD), which look
+// identical but compare unequal byte for byte.
 func (e *Engine) normalizePath(p string) string {
-	return strings.ToLower(filepath.Clean(p))
+	return strings.ToLower(norm.NFC.String(filepath.Clean(p)))
 }
 
 func shouldSkip(path string, skipPaths []string) bool {
@@ -322,22 +743,31 @@ func shouldSkip(path string, skipPaths []string) bool {
 	return false
 }
 
-func getReason(class models.MediaClassification, media models.MediaFile, arrFile *models.ArrFile) string {
+func getReason(class models.MediaClassification, media models.MediaFile, arrFile *models.ArrFile) (models.ReasonCode, string) {
 	switch class {
 	case models.MediaHealthy:
-		return "Tracked by Arr and hardlinked to torrent"
+		if media.IsReflinked && !media.IsHardlinked {
+			return models.ReasonTrackedReflinked, "Tracked by Arr and sharing its data with the download by reflink"
+		}
+		return models.ReasonTrackedHardlinked, "Tracked by Arr and hardlinked to torrent"
 	case models.MediaAtRisk:
-		return "Tracked by Arr but NOT hardlinked (no torrent protection)"
+		return models.ReasonNotHardlinked, "Tracked by Arr but NOT hardlinked (no torrent protection)"
 	case models.MediaOrphan:
-		return "Not tracked by Arr (outside grace window)"
+		return models.ReasonNotTracked, "Not tracked by Arr (outside grace window)"
 	case models.MediaOrphanedDownload:
-		return "Orphaned download: in torrent dir, not hardlinked, not tracked by Arr"
+		return models.ReasonDownloadNotLinked, "Orphaned download: in torrent dir, not hardlinked, not tracked by Arr"
 	case models.MediaHiddenFile:
-		return "Hidden file (dot-prefix): likely incomplete download fragment"
+		return models.ReasonHiddenFragment, "Hidden file (dot-prefix): likely incomplete download fragment"
 	case models.MediaLostAndFound:
-		return "Found in extra scan path (e.g. lost+found): filesystem recovery artifact"
+		return models.ReasonRecoveryArtifact, "Found in extra scan path (e.g. lost+found): filesystem recovery artifact"
+	case models.MediaOtherFile:
+		return models.ReasonIgnoredExtension, "Not tracked by Arr, but its extension is in orphan_ignore_extensions"
+	case models.MediaExpectedUntracked:
+		return models.ReasonExpectedUntracked, "Not tracked by Arr, but under expected_untracked_paths"
+	case models.MediaRemovedFromArr:
+		return models.ReasonRemovedFromArr, "Tracked by Arr in the previous run but no longer: deleted from Arr, left on disk"
 	default:
-		return "Unknown classification"
+		return models.ReasonUnknown, "Unknown classification"
 	}
 }
 
@@ -400,16 +830,16 @@ func (e *Engine) auditPermissions(file models.FilePermissions) []models.Permissi
 		if file.IsDirectory && file.OwnerUID == 0 {
 			issues = append(issues, models.PermissionIssue{
 				Path:     file.Path,
-				Issue:    "wrong_owner",
+				Issue:    models.ReasonWrongOwner,
 				Severity: "warning",
 				FixHint:  fmt.Sprintf("Directory owned by root (UID 0), expected one of: %v", e.allowedUIDs),
 			})
 		} else {
 			issues = append(issues, models.PermissionIssue{
 				Path:     file.Path,
-				Issue:    "wrong_owner",
+				Issue:    models.ReasonWrongOwner,
 				Severity: "error",
-				FixHint:  fmt.Sprintf("File owned by UID %d, expected one of: %v", file.OwnerUID, e.allowedUIDs),
+				FixHint:  fmt.Sprintf("File owned by %s, expected one of: %v", describeID("UID", file.OwnerUID, utils.UserName), e.allowedUIDs),
 			})
 		}
 	}
@@ -417,25 +847,42 @@ func (e *Engine) auditPermissions(file models.FilePermissions) []models.Permissi
 	if file.GroupGID != e.expectedGroupGID {
 		issues = append(issues, models.PermissionIssue{
 			Path:     file.Path,
-			Issue:    "wrong_group",
+			Issue:    models.ReasonWrongGroup,
 			Severity: "error",
-			FixHint:  fmt.Sprintf("File group is GID %d, expected %d", file.GroupGID, e.expectedGroupGID),
+			FixHint:  fmt.Sprintf("File group is %s, expected %s", describeID("GID", file.GroupGID, utils.GroupName), describeID("GID", e.expectedGroupGID, utils.GroupName)),
 		})
 	}
 
 	if !file.GroupWritable() {
 		issues = append(issues, models.PermissionIssue{
 			Path:     file.Path,
-			Issue:    "not_group_writable",
+			Issue:    models.ReasonNotGroupWritable,
 			Severity: "warning",
 			FixHint:  "Group cannot write to file",
 		})
 	}
 
+	expected := e.expectedFileMode
+	if file.IsDirectory {
+		expected = e.expectedDirMode
+	}
+	// Only flag a nonstandard mode when it is otherwise functional; a file
+	// the group cannot write is already reported above.
+	if expected != 0 && file.GroupWritable() && file.Mode&0777 != expected {
+		issues = append(issues, models.PermissionIssue{
+			Path:         file.Path,
+			CurrentMode:  file.Mode & 0777,
+			ExpectedMode: expected,
+			Issue:        models.ReasonNonstandardPermissions,
+			Severity:     e.nonstandardSeverity,
+			FixHint:      fmt.Sprintf("Mode is %04o, expected %04o", file.Mode&0777, expected),
+		})
+	}
+
 	if file.IsDirectory && e.shouldHaveSGID(file.Path) && !file.HasSGID() {
 		issues = append(issues, models.PermissionIssue{
 			Path:     file.Path,
-			Issue:    "missing_sgid",
+			Issue:    models.ReasonMissingSGID,
 			Severity: "warning",
 			FixHint:  "Directory missing SGID bit (new files won't inherit group)",
 		})
@@ -444,6 +891,15 @@ func (e *Engine) auditPermissions(file models.FilePermissions) []models.Permissi
 	return issues
 }
 
+// describeID formats an ID with its name when it has one, e.g. "GID 1000
+// (media)".
+func describeID(kind string, id int, name func(int) string) string {
+	if n := name(id); n != "" {
+		return fmt.Sprintf("%s %d (%s)", kind, id, n)
+	}
+	return fmt.Sprintf("%s %d", kind, id)
+}
+
 func (e *Engine) isValidOwner(uid int) bool {
 	for _, allowed := range e.allowedUIDs {
 		if uid == allowed {
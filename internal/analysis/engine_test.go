@@ -1,7 +1,9 @@
 package analysis
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jdpx/auditarr/internal/models"
 )
@@ -47,3 +49,665 @@ func TestClassifyTorrentFile_ActiveTorrentNotOrphaned(t *testing.T) {
 		t.Errorf("abandoned file classified %q (incl=%v), want orphaned_download", cls, incl)
 	}
 }
+
+func TestFindUnimportedDownloads(t *testing.T) {
+	e := &Engine{}
+	files := []models.MediaFile{
+		{Path: "/media/tv/Show.S01E01.mkv", Source: models.MediaSourceLibrary, Device: 1, Inode: 100},
+		{Path: "/torrents/Show.S01E01.mkv", Source: models.MediaSourceTorrent, Device: 1, Inode: 100},
+		{Path: "/torrents/Never.Imported.mkv", Source: models.MediaSourceTorrent, Device: 1, Inode: 200},
+		{Path: "/torrents/Other.Device.mkv", Source: models.MediaSourceTorrent, Device: 2, Inode: 100},
+	}
+
+	got := e.findUnimportedDownloads(files, e.buildHardlinkIndex(files))
+	if len(got) != 2 {
+		t.Fatalf("got %d unimported downloads, want 2: %+v", len(got), got)
+	}
+	if got[0].Path != "/torrents/Never.Imported.mkv" || got[1].Path != "/torrents/Other.Device.mkv" {
+		t.Errorf("unexpected unimported downloads: %s, %s", got[0].Path, got[1].Path)
+	}
+}
+
+func TestAnalyze_SizeMismatch(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/media/tv/Swapped.mkv", Size: 500, ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+		{Path: "/media/tv/Drifted.mkv", Size: 1005, ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+	}
+	arr := []models.ArrFile{
+		{Path: "/media/tv/Swapped.mkv", SeriesID: 1, Size: 1000},
+		{Path: "/media/tv/Drifted.mkv", SeriesID: 1, Size: 1000},
+	}
+
+	result := e.Analyze(media, arr, nil, nil, nil)
+	if len(result.SizeMismatches) != 1 {
+		t.Fatalf("got %d size mismatches, want 1: %+v", len(result.SizeMismatches), result.SizeMismatches)
+	}
+	if sm := result.SizeMismatches[0]; sm.Path != "/media/tv/Swapped.mkv" || sm.ArrSize != 1000 || sm.DiskSize != 500 {
+		t.Errorf("unexpected mismatch %+v", sm)
+	}
+}
+
+func TestAnalyze_ArrReconciliation(t *testing.T) {
+	e := &Engine{pathMappings: map[string]string{"/tv": "/mnt/media/tv"}}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/tv/Show/S01E01.mkv", ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+		{Path: "/mnt/media/movies/Film.mkv", ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+	}
+	sonarr := []models.ArrFile{
+		{Path: "/tv/Show/S01E01.mkv", SeriesID: 1, Size: 700},
+		{Path: "/tv/Show/S01E02.mkv", SeriesID: 1, Size: 800},
+	}
+	radarr := []models.ArrFile{
+		{Path: "/mnt/media/movies/Film.mkv", MovieID: 1, Size: 4000},
+		{Path: "/mnt/media/movies/Anime.mkv", MovieID: 2, Service: "radarr-anime"},
+	}
+
+	got := e.Analyze(media, sonarr, radarr, nil, nil).ArrReconciliation
+	want := []ArrReconciliation{
+		{Service: "Sonarr", Tracked: 2, Found: 1, TrackedBytes: 1500, Missing: []string{"/mnt/media/tv/Show/S01E02.mkv"}},
+		{Service: "Radarr", Tracked: 1, Found: 1, TrackedBytes: 4000},
+		{Service: "radarr-anime", Tracked: 1, Found: 0, Missing: []string{"/mnt/media/movies/Anime.mkv"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reconciliation = %+v, want %+v", got, want)
+	}
+}
+
+// Sonarr runs in a container that sees the library at /tv, and two series
+// folders differ only in case. Lowercased lookup keys collide; previously the
+// second record overwrote the first, so a file was matched to the wrong
+// record (here producing a bogus size mismatch).
+func TestAnalyze_ArrLookupCollision(t *testing.T) {
+	e := &Engine{pathMappings: map[string]string{"/tv": "/mnt/media/tv"}}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/tv/The Office (US)/S01E01.mkv", Size: 1000, ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+		{Path: "/mnt/media/tv/The office (US)/S01E01.mkv", Size: 4000, ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+	}
+	arr := []models.ArrFile{
+		{Path: "/tv/The Office (US)/S01E01.mkv", SeriesID: 1, Size: 1000},
+		{Path: "/tv/The office (US)/S01E01.mkv", SeriesID: 2, Size: 4000},
+	}
+
+	result := e.Analyze(media, arr, nil, nil, nil)
+	if len(result.SizeMismatches) != 0 {
+		t.Errorf("files were matched to the wrong Arr record: %+v", result.SizeMismatches)
+	}
+	if result.Summary.HealthyCount != 2 {
+		t.Errorf("HealthyCount = %d, want 2", result.Summary.HealthyCount)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected one collision warning, got %v", result.Warnings)
+	}
+}
+
+func TestAnalyze_OrphanedSidecars(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	dir := "/media/movies/Heat (1995)/"
+	media := []models.MediaFile{
+		{Path: dir + "Heat (1995).mkv", ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+		{Path: dir + "Heat (1995).en.forced.srt", ModTime: old, Source: models.MediaSourceLibrary, IsSidecar: true},
+		{Path: dir + "Heat (1995).nfo", ModTime: old, Source: models.MediaSourceLibrary, IsSidecar: true},
+		{Path: dir + "Heat (1995)-thumb.jpg", ModTime: old, Source: models.MediaSourceLibrary, IsSidecar: true},
+		{Path: dir + "poster.jpg", ModTime: old, Source: models.MediaSourceLibrary, IsSidecar: true},
+		{Path: dir + "movie.nfo", ModTime: old, Source: models.MediaSourceLibrary, IsSidecar: true},
+		{Path: dir + "Heat (1995) Bluray-720p.en.srt", ModTime: old, Source: models.MediaSourceLibrary, IsSidecar: true},
+	}
+	arr := []models.ArrFile{{Path: dir + "Heat (1995).mkv", MovieID: 1}}
+
+	result := e.Analyze(media, nil, arr, nil, nil)
+	var orphaned []string
+	for _, cm := range result.ClassifiedMedia {
+		if cm.Classification == models.MediaOrphanedSidecar {
+			orphaned = append(orphaned, cm.File.Path)
+		}
+	}
+	if len(orphaned) != 1 || orphaned[0] != dir+"Heat (1995) Bluray-720p.en.srt" {
+		t.Errorf("orphaned sidecars = %v, want only the subtitle from the replaced release", orphaned)
+	}
+	if result.Summary.OrphanCount != 0 {
+		t.Errorf("sidecars must not be classified as orphaned media, got %d", result.Summary.OrphanCount)
+	}
+}
+
+func TestAnalyze_ReasonCodes(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/media/tv/Tracked.mkv", ModTime: old, Source: models.MediaSourceLibrary, IsHardlinked: true},
+		{Path: "/media/tv/Copied.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/media/tv/Stray.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+	arr := []models.ArrFile{
+		{Path: "/media/tv/Tracked.mkv", SeriesID: 1},
+		{Path: "/media/tv/Copied.mkv", SeriesID: 1},
+	}
+
+	want := map[string]models.ReasonCode{
+		"/media/tv/Tracked.mkv": models.ReasonTrackedHardlinked,
+		"/media/tv/Copied.mkv":  models.ReasonNotHardlinked,
+		"/media/tv/Stray.mkv":   models.ReasonNotTracked,
+	}
+	for _, cm := range e.Analyze(media, arr, nil, nil, nil).ClassifiedMedia {
+		if cm.Code != want[cm.File.Path] {
+			t.Errorf("%s: code %q, want %q", cm.File.Path, cm.Code, want[cm.File.Path])
+		}
+		if cm.Reason == "" {
+			t.Errorf("%s: display reason is empty", cm.File.Path)
+		}
+	}
+}
+
+func TestAnalyze_ProtectedPaths(t *testing.T) {
+	e := &Engine{}
+	e.SetProtectedPaths([]string{"/media/archive", "/media/*/Extras"})
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/media/archive/Home Video.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/media/movies/Extras/Behind the Scenes.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/media/archive-old/Stray.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if result.Summary.HealthyCount != 2 || result.Summary.OrphanCount != 1 {
+		t.Fatalf("healthy=%d orphan=%d, want 2 and 1", result.Summary.HealthyCount, result.Summary.OrphanCount)
+	}
+	for _, cm := range result.ClassifiedMedia {
+		if cm.Classification == models.MediaOrphan && cm.File.Path != "/media/archive-old/Stray.mkv" {
+			t.Errorf("protected file %s was flagged", cm.File.Path)
+		}
+	}
+}
+
+func TestAnalyze_ProtectedPathsNotSuspicious(t *testing.T) {
+	e := &Engine{suspiciousExtensions: []string{".exe"}}
+	e.SetProtectedPaths([]string{"/media/archive"})
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/media/archive/setup.exe", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/media/movies/setup.exe", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if result.Summary.SuspiciousCount != 1 || result.SuspiciousFiles[0].Path != "/media/movies/setup.exe" {
+		t.Errorf("suspicious = %+v, want only the unprotected setup.exe", result.SuspiciousFiles)
+	}
+}
+
+func TestAnalyze_CaseDuplicates(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/tv/Show/S01E01.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/tv/show/S01E02.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Film/Film.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Film/film.MKV", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+
+	got := e.Analyze(media, nil, nil, nil, nil).CaseDuplicates
+	want := []CaseDuplicate{
+		{Parent: "/mnt/media/movies/Film", Names: []string{"Film.mkv", "film.MKV"}},
+		{Parent: "/mnt/media/tv", Names: []string{"Show", "show"}, IsDir: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("case duplicates = %+v, want %+v", got, want)
+	}
+}
+
+func TestAnalyze_CopiedImports(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/tv/Show/S01E01.mkv", Size: 1000, Device: 1, Inode: 10, ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/tv/Show/S01E02.mkv", Size: 2000, Device: 1, Inode: 11, ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/torrents/Show.S01/s01e01.mkv", Size: 1000, Device: 1, Inode: 20, ModTime: old, Source: models.MediaSourceTorrent},
+		{Path: "/mnt/torrents/Show.S01/S01E02.mkv", Size: 1999, Device: 1, Inode: 21, ModTime: old, Source: models.MediaSourceTorrent},
+	}
+	sonarr := []models.ArrFile{
+		{Path: "/mnt/media/tv/Show/S01E01.mkv", SeriesID: 1},
+		{Path: "/mnt/media/tv/Show/S01E02.mkv", SeriesID: 1},
+	}
+
+	result := e.Analyze(media, nil, sonarr, nil, nil)
+	want := []CopiedImport{{
+		Path:          "/mnt/media/tv/Show/S01E01.mkv",
+		Size:          1000,
+		TorrentCopies: []string{"/mnt/torrents/Show.S01/s01e01.mkv"},
+		WastedBytes:   1000,
+	}}
+	if !reflect.DeepEqual(result.CopiedImports, want) {
+		t.Errorf("copied imports = %+v, want %+v", result.CopiedImports, want)
+	}
+	if result.Summary.CopiedImportCount != 1 || result.Summary.CopiedImportWaste != 1000 {
+		t.Errorf("summary = %d files / %d bytes, want 1 / 1000", result.Summary.CopiedImportCount, result.Summary.CopiedImportWaste)
+	}
+}
+
+func TestAnalyze_ArrConfirmedOrphans(t *testing.T) {
+	e := &Engine{pathMappings: map[string]string{"/data/media": "/mnt/media"}}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/tv/Old Show/S01E01.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/tv/Show/extra.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if result.ArrUnmappedChecked || result.Summary.ArrConfirmedOrphans != 0 {
+		t.Fatalf("cross-check ran without unmapped folders: %+v", result.Summary)
+	}
+
+	e.SetArrUnmappedFolders([]string{"/data/media/tv/Old Show"})
+	result = e.Analyze(media, nil, nil, nil, nil)
+	if !result.ArrUnmappedChecked || result.Summary.ArrConfirmedOrphans != 1 {
+		t.Fatalf("checked=%t confirmed=%d, want true/1", result.ArrUnmappedChecked, result.Summary.ArrConfirmedOrphans)
+	}
+	for _, cm := range result.ClassifiedMedia {
+		want := cm.File.Path == "/mnt/media/tv/Old Show/S01E01.mkv"
+		if cm.ArrConfirmed != want {
+			t.Errorf("%s: ArrConfirmed = %t, want %t", cm.File.Path, cm.ArrConfirmed, want)
+		}
+	}
+}
+
+func TestAnalyze_OrphanIgnoreExtensions(t *testing.T) {
+	e := &Engine{}
+	e.SetOrphanIgnoreExtensions([]string{"TXT", ".sfv"})
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/movies/Film/Film.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Film/notes.txt", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Film/Film.sfv", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if result.Summary.OrphanCount != 1 || result.Summary.OtherFileCount != 2 {
+		t.Fatalf("orphans=%d other=%d, want 1 and 2", result.Summary.OrphanCount, result.Summary.OtherFileCount)
+	}
+	for _, cm := range result.ClassifiedMedia {
+		if cm.Classification == models.MediaOtherFile && cm.Code != models.ReasonIgnoredExtension {
+			t.Errorf("%s: reason code %s, want %s", cm.File.Path, cm.Code, models.ReasonIgnoredExtension)
+		}
+	}
+}
+
+func TestAnalyze_ExpectedUntrackedPaths(t *testing.T) {
+	e := &Engine{}
+	e.SetExpectedUntrackedPaths([]string{"/mnt/media/_manual"})
+	e.SetPreviouslyTracked([]string{"/mnt/media/_manual/Was In Arr.mkv"})
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/_manual/Home Video.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/_manual/Was In Arr.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/_manual/Tracked.mkv", ModTime: old, HardlinkCount: 1, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Stray.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+	radarr := []models.ArrFile{{Path: "/mnt/media/_manual/Tracked.mkv", MovieID: 1}}
+
+	result := e.Analyze(media, nil, radarr, nil, nil)
+	if result.Summary.ExpectedUntrackedCount != 2 || result.Summary.OrphanCount != 1 || result.Summary.AtRiskCount != 1 {
+		t.Fatalf("expected=%d orphans=%d at risk=%d, want 2, 1 and 1",
+			result.Summary.ExpectedUntrackedCount, result.Summary.OrphanCount, result.Summary.AtRiskCount)
+	}
+	for _, cm := range result.ClassifiedMedia {
+		if cm.Classification == models.MediaExpectedUntracked && cm.Code != models.ReasonExpectedUntracked {
+			t.Errorf("%s: reason code %s, want %s", cm.File.Path, cm.Code, models.ReasonExpectedUntracked)
+		}
+	}
+}
+
+func TestAnalyze_RemovedFromArr(t *testing.T) {
+	e := &Engine{}
+	e.SetPreviouslyTracked([]string{"/mnt/media/movies/Gone/Gone.mkv", "/mnt/media/movies/Kept/Kept.mkv"})
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/movies/Gone/Gone.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Kept/Kept.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Never/Never.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+	radarr := []models.ArrFile{{Path: "/mnt/media/movies/Kept/Kept.mkv", MovieID: 1}}
+
+	result := e.Analyze(media, nil, radarr, nil, nil)
+	if result.Summary.RemovedFromArrCount != 1 || result.Summary.OrphanCount != 1 {
+		t.Fatalf("removed=%d orphans=%d, want 1 and 1", result.Summary.RemovedFromArrCount, result.Summary.OrphanCount)
+	}
+	for _, cm := range result.ClassifiedMedia {
+		if cm.Classification == models.MediaRemovedFromArr && cm.File.Path != "/mnt/media/movies/Gone/Gone.mkv" {
+			t.Errorf("%s classified as removed from Arr", cm.File.Path)
+		}
+	}
+}
+
+func TestAnalyze_SuspiciousSeverities(t *testing.T) {
+	e := &Engine{suspiciousExtensions: []string{".exe", ".iso"}, flagArchives: true}
+	e.SetSuspiciousSeverities(map[string]string{"ISO": models.SuspiciousInfo})
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/movies/Film/Film.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Film/setup.exe", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/mnt/media/movies/Film/Film.iso", ModTime: old, Source: models.MediaSourceLibrary},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if result.Summary.SuspiciousCount != 2 {
+		t.Fatalf("SuspiciousCount = %d, want 2", result.Summary.SuspiciousCount)
+	}
+	got := map[string]string{}
+	for _, sf := range result.SuspiciousFiles {
+		got[sf.Path] = sf.Severity
+	}
+	want := map[string]string{
+		"/mnt/media/movies/Film/setup.exe": models.SuspiciousCritical,
+		"/mnt/media/movies/Film/Film.iso":  models.SuspiciousInfo,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("severities = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyze_ExcessiveHardlinks(t *testing.T) {
+	e := &Engine{}
+	e.SetMaxExpectedHardlinks(4)
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/mnt/media/tv/Show/ep1.mkv", ModTime: old, Source: models.MediaSourceLibrary, HardlinkCount: 4, IsHardlinked: true},
+		{Path: "/mnt/media/tv/Show/ep2.mkv", ModTime: old, Source: models.MediaSourceLibrary, HardlinkCount: 15, IsHardlinked: true},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if len(result.SuspiciousFiles) != 1 {
+		t.Fatalf("got %d suspicious files, want 1: %+v", len(result.SuspiciousFiles), result.SuspiciousFiles)
+	}
+	sf := result.SuspiciousFiles[0]
+	if sf.Path != "/mnt/media/tv/Show/ep2.mkv" || sf.Code != models.ReasonExcessiveHardlinks || sf.Severity != models.SuspiciousWarning {
+		t.Errorf("finding = %+v, want an excessive_hardlinks warning for ep2.mkv", sf)
+	}
+}
+
+func TestAnalyze_UnicodeNormalization(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	// The same title in NFD (e + combining acute) on disk and NFC (é) in Arr.
+	nfd := "/mnt/media/movies/Ame\u0301lie (2001)/Ame\u0301lie.mkv"
+	nfc := "/mnt/media/movies/Am\u00e9lie (2001)/Am\u00e9lie.mkv"
+	media := []models.MediaFile{{Path: nfd, ModTime: old, HardlinkCount: 2, IsHardlinked: true, Source: models.MediaSourceLibrary}}
+	radarr := []models.ArrFile{{Path: nfc, MovieID: 1}}
+
+	result := e.Analyze(media, nil, radarr, nil, nil)
+	if result.Summary.OrphanCount != 0 || result.Summary.HealthyCount != 1 {
+		t.Fatalf("orphans=%d healthy=%d, want the NFD file matched to its NFC Arr record",
+			result.Summary.OrphanCount, result.Summary.HealthyCount)
+	}
+}
+
+func TestAnalyze_UnknownStateTorrents(t *testing.T) {
+	torrents := []models.Torrent{
+		{Name: "Done", SavePath: "/data", State: models.StateCompleted, Files: []string{"Done.mkv"}},
+		{Name: "Mystery", SavePath: "/data", State: models.StateUnknown, Files: []string{"Mystery.mkv"}},
+	}
+
+	for include, want := range map[bool]int{false: 1, true: 2} {
+		e := &Engine{}
+		e.SetIncludeUnknownTorrents(include)
+		result := e.Analyze(nil, nil, nil, torrents, nil)
+		if len(result.UnlinkedTorrents) != want {
+			t.Errorf("include unknown %t: %d unlinked torrents, want %d", include, len(result.UnlinkedTorrents), want)
+		}
+	}
+}
+
+func TestAnalyze_TorrentCategoryGraceHours(t *testing.T) {
+	completed := time.Now().Add(-24 * time.Hour)
+	torrents := []models.Torrent{
+		{Name: "Auto", SavePath: "/data", Category: "tv", State: models.StateCompleted, CompletedOn: completed, Files: []string{"Auto.mkv"}},
+		{Name: "Manual", SavePath: "/data", Category: "manual", State: models.StateCompleted, CompletedOn: completed, Files: []string{"Manual.mkv"}},
+	}
+
+	e := &Engine{qbittorrentGraceHours: 12}
+	e.SetTorrentCategoryGraceHours(map[string]int{"manual": 72})
+	result := e.Analyze(nil, nil, nil, torrents, nil)
+	if len(result.UnlinkedTorrents) != 1 || result.UnlinkedTorrents[0].Name != "Auto" {
+		t.Errorf("unlinked = %v, want only Auto; Manual is within its category's grace", result.UnlinkedTorrents)
+	}
+}
+
+func TestAnalyze_OutOfScopeTorrents(t *testing.T) {
+	torrents := []models.Torrent{
+		{Name: "Scanned", SavePath: "/data/torrents/movies", State: models.StateCompleted, Files: []string{"Scanned.mkv"}},
+		{Name: "Elsewhere", SavePath: "/downloads/complete", State: models.StateCompleted, Files: []string{"Elsewhere.mkv"}},
+	}
+
+	e := &Engine{pathMappings: map[string]string{"/data/torrents": "/mnt/torrents"}}
+	e.SetScanRoots([]string{"/mnt/media", "/mnt/torrents"})
+	result := e.Analyze(nil, nil, nil, torrents, nil)
+	if len(result.UnlinkedTorrents) != 1 || result.UnlinkedTorrents[0].Name != "Scanned" {
+		t.Errorf("unlinked = %v, want only the torrent under a scan root", result.UnlinkedTorrents)
+	}
+	if len(result.OutOfScopeTorrents) != 1 || result.OutOfScopeTorrents[0].Name != "Elsewhere" || result.Summary.OutOfScopeTorrentCount != 1 {
+		t.Errorf("out of scope = %v, want the torrent saved outside the roots", result.OutOfScopeTorrents)
+	}
+}
+
+func TestAnalyze_RenamedHardlinkedTorrent(t *testing.T) {
+	// Sonarr renamed the episode on import; only the inode ties the two.
+	files := []models.MediaFile{
+		{Path: "/mnt/media/tv/Show/Season 01/Show - S01E01.mkv", Source: models.MediaSourceLibrary, Device: 1, Inode: 100, HardlinkCount: 2},
+		{Path: "/mnt/torrents/Show.S01E01.1080p/S01E01.1080p.mkv", Source: models.MediaSourceTorrent, Device: 1, Inode: 100, HardlinkCount: 2},
+		{Path: "/mnt/torrents/Other.S01E02/Other.S01E02.mkv", Source: models.MediaSourceTorrent, Device: 1, Inode: 200, HardlinkCount: 1},
+	}
+	torrents := []models.Torrent{
+		{Name: "Show.S01E01.1080p", SavePath: "/data/torrents/Show.S01E01.1080p", State: models.StateCompleted, Files: []string{"S01E01.1080p.mkv"}},
+		{Name: "Other.S01E02", SavePath: "/data/torrents/Other.S01E02", State: models.StateCompleted, Files: []string{"Other.S01E02.mkv"}},
+	}
+
+	e := &Engine{pathMappings: map[string]string{"/data/torrents": "/mnt/torrents"}}
+	result := e.Analyze(files, nil, nil, torrents, nil)
+	if len(result.UnlinkedTorrents) != 1 || result.UnlinkedTorrents[0].Name != "Other.S01E02" {
+		t.Errorf("unlinked = %v, want only Other.S01E02; the renamed import shares its inode", result.UnlinkedTorrents)
+	}
+}
+
+func TestAnalyze_RoutesUnmatchedFilesBySource(t *testing.T) {
+	e := &Engine{}
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/data/media/movies/Film.mkv", ModTime: old, Source: models.MediaSourceLibrary},
+		{Path: "/data/torrents/Film.mkv", ModTime: old, Source: models.MediaSourceTorrent},
+	}
+
+	result := e.Analyze(media, nil, nil, nil, nil)
+	if result.Summary.OrphanCount != 1 || result.Summary.OrphanedDownloadCount != 1 {
+		t.Fatalf("orphans=%d orphaned downloads=%d, want 1 and 1", result.Summary.OrphanCount, result.Summary.OrphanedDownloadCount)
+	}
+	for _, cm := range result.ClassifiedMedia {
+		want := models.MediaOrphan
+		if cm.File.Source == models.MediaSourceTorrent {
+			want = models.MediaOrphanedDownload
+		}
+		if cm.Classification != want {
+			t.Errorf("%s: classified %s, want %s", cm.File.Path, cm.Classification, want)
+		}
+	}
+}
+
+func TestAnalyze_Reflinks(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour)
+	media := []models.MediaFile{
+		{Path: "/media/tv/Cloned.mkv", Size: 100, ModTime: old, Source: models.MediaSourceLibrary, Inode: 1},
+		{Path: "/torrents/Cloned.mkv", Size: 100, ModTime: old, Source: models.MediaSourceTorrent, Inode: 2},
+		{Path: "/media/tv/Copied.mkv", Size: 100, ModTime: old, Source: models.MediaSourceLibrary, Inode: 3},
+		{Path: "/torrents/Copied.mkv", Size: 100, ModTime: old, Source: models.MediaSourceTorrent, Inode: 4},
+	}
+	arr := []models.ArrFile{
+		{Path: "/media/tv/Cloned.mkv", SeriesID: 1},
+		{Path: "/media/tv/Copied.mkv", SeriesID: 1},
+		{Path: "/torrents/Cloned.mkv", SeriesID: 1},
+		{Path: "/torrents/Copied.mkv", SeriesID: 1},
+	}
+	classes := func(result *AnalysisResult) map[string]models.ReasonCode {
+		got := make(map[string]models.ReasonCode)
+		for _, cm := range result.ClassifiedMedia {
+			got[cm.File.Path] = cm.Code
+		}
+		return got
+	}
+
+	e := &Engine{torrentRoot: "/torrents"}
+	e.SetReflinks(ReflinksDetect)
+	e.sharesExtents = func(a, b string) bool {
+		return a == "/media/tv/Cloned.mkv" && b == "/torrents/Cloned.mkv"
+	}
+	result := e.Analyze(media, arr, nil, nil, nil)
+	got := classes(result)
+	if got["/media/tv/Cloned.mkv"] != models.ReasonTrackedReflinked || got["/media/tv/Copied.mkv"] != models.ReasonNotHardlinked {
+		t.Errorf("detect: reasons = %v, want Cloned reflinked and Copied at risk", got)
+	}
+	if len(result.CopiedImports) != 1 || result.CopiedImports[0].Path != "/media/tv/Copied.mkv" {
+		t.Errorf("detect: copied imports = %+v, want only Copied.mkv", result.CopiedImports)
+	}
+	if len(result.UnimportedDownloads) != 1 || result.UnimportedDownloads[0].Path != "/torrents/Copied.mkv" {
+		t.Errorf("detect: unimported = %+v, want only the copied download", result.UnimportedDownloads)
+	}
+	if media[0].IsReflinked {
+		t.Error("Analyze modified the caller's media files")
+	}
+
+	e.SetReflinks(ReflinksAssume)
+	if result := e.Analyze(media, arr, nil, nil, nil); result.Summary.AtRiskCount != 0 {
+		t.Errorf("assume: %d at risk, want 0", result.Summary.AtRiskCount)
+	}
+}
+
+func TestAnalyze_PartialSeasonPacks(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour)
+	lib := func(path string, inode uint64) models.MediaFile {
+		return models.MediaFile{Path: path, Size: 100, ModTime: old, Source: models.MediaSourceLibrary, Inode: inode, IsHardlinked: true, HardlinkCount: 2}
+	}
+	dl := func(path string, inode uint64, linked bool) models.MediaFile {
+		f := models.MediaFile{Path: path, Size: 100, ModTime: old, Source: models.MediaSourceTorrent, Inode: inode, IsHardlinked: linked}
+		if linked {
+			f.HardlinkCount = 2
+		}
+		return f
+	}
+	media := []models.MediaFile{
+		lib("/media/tv/Show/Season 02/Show S02E01.mkv", 1),
+		lib("/media/tv/Show/Season 02/Show S02E02.mkv", 2),
+		dl("/mnt/torrents/Show.S02.1080p/Show.S02E01.mkv", 1, true),
+		dl("/mnt/torrents/Show.S02.1080p/Show.S02E02.mkv", 2, true),
+		dl("/mnt/torrents/Show.S02.1080p/Show.S02E03.mkv", 3, false),
+		lib("/media/tv/Done/Done S01E01.mkv", 4),
+		dl("/mnt/torrents/Done.S01/Done.S01E01.mkv", 4, true),
+		dl("/mnt/torrents/Done.S01/Done.S01E02.mkv", 5, false),
+		dl("/mnt/torrents/Done.S01/Done.S01E02.nfo", 6, false),
+	}
+	torrents := []models.Torrent{
+		{Name: "Show.S02.1080p", SavePath: "/data/torrents", State: models.StateSeeding, Files: []string{
+			"Show.S02.1080p/Show.S02E01.mkv", "Show.S02.1080p/Show.S02E02.mkv", "Show.S02.1080p/Show.S02E03.mkv",
+		}},
+		{Name: "Done.S01", SavePath: "/data/torrents", State: models.StateDownloading, Files: []string{
+			"Done.S01/Done.S01E01.mkv", "Done.S01/Done.S01E02.mkv",
+		}},
+	}
+
+	e := &Engine{torrentRoot: "/mnt/torrents", pathMappings: map[string]string{"/data/torrents": "/mnt/torrents"}}
+	result := e.Analyze(media, nil, nil, torrents, nil)
+	if len(result.PartialSeasonPacks) != 1 {
+		t.Fatalf("got %d partial packs, want 1 (incomplete torrents are skipped): %+v", len(result.PartialSeasonPacks), result.PartialSeasonPacks)
+	}
+	p := result.PartialSeasonPacks[0]
+	if p.Series != "Show" || p.Season != 2 || len(p.Imported) != 2 || len(p.Leftover) != 1 {
+		t.Errorf("pack = %+v, want Show season 2 with 2 imported and 1 leftover", p)
+	}
+	if p.Imported[0].LibraryPath != "/media/tv/Show/Season 02/Show S02E01.mkv" || p.Leftover[0] != "/mnt/torrents/Show.S02.1080p/Show.S02E03.mkv" {
+		t.Errorf("pack files = %+v", p)
+	}
+	if result.Summary.PartialSeasonPackCount != 1 {
+		t.Errorf("PartialSeasonPackCount = %d, want 1", result.Summary.PartialSeasonPackCount)
+	}
+}
+
+func TestAnalysisResult_IncompleteCollection(t *testing.T) {
+	complete := &AnalysisResult{ConnectionStatus: []ServiceStatus{
+		{Name: "Sonarr", Enabled: true, OK: true},
+		{Name: "Radarr", Enabled: false},
+	}}
+	if got := complete.IncompleteCollection(); len(got) != 0 {
+		t.Errorf("complete run: got %v, want none", got)
+	}
+
+	partial := &AnalysisResult{
+		FilesystemFailed: true,
+		ConnectionStatus: []ServiceStatus{
+			{Name: "Sonarr", Enabled: true, OK: true, FailedFetches: []string{"episode files for series 7: API returned status 500"}},
+			{Name: "Radarr", Enabled: true, Error: "connection refused"},
+		},
+	}
+	want := []string{
+		"filesystem walk failed",
+		"Sonarr: failed to fetch episode files for series 7: API returned status 500",
+		"Radarr: connection refused",
+	}
+	if got := partial.IncompleteCollection(); !reflect.DeepEqual(got, want) {
+		t.Errorf("partial run: got %q, want %q", got, want)
+	}
+}
+
+func TestAnalyze_NonstandardPermissions(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		perm           models.FilePermissions
+		fileMode       uint32
+		want           []models.ReasonCode
+		errs, warnings int
+	}{
+		{"expected file mode", models.FilePermissions{Path: "/media/a.mkv", Mode: 0664}, 0664, nil, 0, 0},
+		{"world-writable file", models.FilePermissions{Path: "/media/a.mkv", Mode: 0666}, 0664, []models.ReasonCode{models.ReasonNonstandardPermissions}, 0, 1},
+		{"only not group writable", models.FilePermissions{Path: "/media/a.mkv", Mode: 0644}, 0664, []models.ReasonCode{models.ReasonNotGroupWritable}, 0, 1},
+		{"expected dir mode", models.FilePermissions{Path: "/media/tv", Mode: 0775, IsDirectory: true}, 0664, nil, 0, 0},
+		{"world-writable dir", models.FilePermissions{Path: "/media/tv", Mode: 0777, IsDirectory: true}, 0664, []models.ReasonCode{models.ReasonNonstandardPermissions}, 0, 1},
+		{"check disabled", models.FilePermissions{Path: "/media/a.mkv", Mode: 0666}, 0, nil, 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &Engine{permissionsEnabled: true, allowedUIDs: []int{0}, nonstandardSeverity: "warning"}
+			e.SetExpectedModes(tc.fileMode, 0775)
+			result := e.Analyze(nil, nil, nil, nil, []models.FilePermissions{tc.perm})
+			var got []models.ReasonCode
+			for _, issue := range result.PermissionIssues {
+				got = append(got, issue.Issue)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("issues = %v, want %v", got, tc.want)
+			}
+			if result.Summary.PermissionErrors != tc.errs || result.Summary.PermissionWarnings != tc.warnings {
+				t.Errorf("counted %d errors and %d warnings, want %d and %d", result.Summary.PermissionErrors, result.Summary.PermissionWarnings, tc.errs, tc.warnings)
+			}
+		})
+	}
+}
+
+func TestAnalyze_NonstandardSeverity(t *testing.T) {
+	perm := models.FilePermissions{Path: "/media/a.mkv", Mode: 0666}
+	for _, tc := range []struct {
+		severity       string
+		errs, warnings int
+	}{
+		{"error", 1, 0},
+		{"warning", 0, 1},
+		// Info issues are listed but counted in neither total.
+		{"info", 0, 0},
+	} {
+		e := &Engine{permissionsEnabled: true, allowedUIDs: []int{0}, nonstandardSeverity: tc.severity}
+		e.SetExpectedModes(0664, 0775)
+		result := e.Analyze(nil, nil, nil, nil, []models.FilePermissions{perm})
+		if len(result.PermissionIssues) != 1 || result.PermissionIssues[0].Severity != tc.severity {
+			t.Errorf("%s: issues = %+v, want one at that severity", tc.severity, result.PermissionIssues)
+		}
+		if result.Summary.PermissionErrors != tc.errs || result.Summary.PermissionWarnings != tc.warnings {
+			t.Errorf("%s: counted %d errors and %d warnings, want %d and %d", tc.severity, result.Summary.PermissionErrors, result.Summary.PermissionWarnings, tc.errs, tc.warnings)
+		}
+	}
+}
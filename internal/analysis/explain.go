@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// ExplainCandidate is an Arr record considered while matching a file.
+type ExplainCandidate struct {
+	ArrPath    string
+	MappedPath string
+	File       models.ArrFile
+}
+
+// Explanation records each step of classifying a single file, for the
+// explain command.
+type Explanation struct {
+	Media     models.MediaFile
+	LookupKey string
+	// Skipped is set when the file is under a permissions skip path, which
+	// the audit ignores entirely.
+	Skipped bool
+	// Candidates are the Arr records whose mapped path has the same lookup
+	// key; Match is the one chosen.
+	Candidates []ExplainCandidate
+	Match      *models.ArrFile
+	// NearMisses are Arr records with the same file name under a different
+	// mapped path, usually a sign of a wrong or missing path mapping.
+	NearMisses      []ExplainCandidate
+	InActiveTorrent bool
+	GraceHours      int
+	WithinGrace     bool
+	RuleName        string
+	Suppressed      bool
+	// Sidecar is set for subtitle/metadata files, which are judged by
+	// whether a matching video sits beside them rather than by Arr.
+	Sidecar bool
+	// Classified is nil when the file is left out of the report (grace
+	// window, ignore rule, or an orphaned subtitle).
+	Classified *models.ClassifiedMedia
+}
+
+// Explain classifies media exactly as Analyze would and records why.
+// siblings are the other files collected from the same directory, used to
+// judge sidecars.
+func (e *Engine) Explain(media models.MediaFile, siblings []models.MediaFile, sonarrFiles, radarrFiles []models.ArrFile, torrents []models.Torrent) *Explanation {
+	arrLookup := e.buildArrLookup(sonarrFiles, radarrFiles)
+	torrentIdx := e.buildTorrentFileIndex(torrents)
+	media = e.markReflinks([]models.MediaFile{media})[0]
+
+	ex := &Explanation{
+		Media:     media,
+		LookupKey: e.normalizePath(media.Path),
+		Skipped:   shouldSkip(media.Path, e.skipPaths),
+	}
+	for _, c := range arrLookup[ex.LookupKey] {
+		ex.Candidates = append(ex.Candidates, ExplainCandidate{ArrPath: c.file.Path, MappedPath: c.mappedPath, File: *c.file})
+	}
+	ex.Match = arrLookup.find(ex.LookupKey, media.Path)
+
+	name := strings.ToLower(filepath.Base(media.Path))
+	for _, files := range [][]models.ArrFile{sonarrFiles, radarrFiles} {
+		for _, f := range files {
+			if strings.ToLower(filepath.Base(f.Path)) != name {
+				continue
+			}
+			mapped := utils.NormalizePath(f.Path, e.pathMappings)
+			if e.normalizePath(mapped) != ex.LookupKey {
+				ex.NearMisses = append(ex.NearMisses, ExplainCandidate{ArrPath: f.Path, MappedPath: mapped, File: f})
+			}
+		}
+	}
+
+	ex.GraceHours = e.getGraceHours(ex.Match, media.Source)
+	ex.WithinGrace = media.WithinGraceWindow(ex.GraceHours)
+	if media.Source == models.MediaSourceTorrent {
+		ex.InActiveTorrent = e.belongsToActiveTorrent(media.Path, torrentIdx)
+	}
+	if _, _, rule := ClassifyByRules(e.rules, media, ex.Match, ex.GraceHours); rule != nil {
+		ex.RuleName = rule.Name
+	}
+
+	if media.IsSidecar {
+		ex.Sidecar = true
+		ex.Suppressed = e.baseline.contains(ex.LookupKey)
+		if len(findOrphanedSidecars([]models.MediaFile{media}, siblings)) > 0 && !ex.Skipped && !ex.Suppressed {
+			ex.Classified = &models.ClassifiedMedia{
+				File:           media,
+				Classification: models.MediaOrphanedSidecar,
+				Code:           models.ReasonSidecarNoVideo,
+				Reason:         sidecarReason(media.Path),
+			}
+		}
+		return ex
+	}
+
+	out := e.classifyFile(media, arrLookup, torrentIdx)
+	ex.Suppressed = out.suppressed
+	ex.Classified = out.classified
+	return ex
+}
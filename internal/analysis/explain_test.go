@@ -0,0 +1,35 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestExplain_ReportsNearMissOnWrongMapping(t *testing.T) {
+	e := &Engine{pathMappings: map[string]string{"/data/media": "/mnt/media"}}
+	media := models.MediaFile{
+		Path:    "/srv/library/tv/Show/S01E01.mkv",
+		ModTime: time.Now().Add(-72 * time.Hour),
+		Source:  models.MediaSourceLibrary,
+	}
+	sonarr := []models.ArrFile{{Path: "/data/media/tv/Show/S01E01.mkv", SeriesID: 1}}
+
+	ex := e.Explain(media, nil, sonarr, nil, nil)
+	if ex.Match != nil {
+		t.Fatalf("unexpected match %+v", ex.Match)
+	}
+	if len(ex.NearMisses) != 1 || ex.NearMisses[0].MappedPath != "/mnt/media/tv/Show/S01E01.mkv" {
+		t.Errorf("near misses = %+v, want the mapped Sonarr record", ex.NearMisses)
+	}
+	if ex.Classified == nil || ex.Classified.Classification != models.MediaOrphan {
+		t.Errorf("classified = %+v, want orphan", ex.Classified)
+	}
+
+	e.pathMappings = map[string]string{"/data/media": "/srv/library"}
+	ex = e.Explain(media, nil, sonarr, nil, nil)
+	if ex.Match == nil || len(ex.NearMisses) != 0 || ex.Classified.Classification != models.MediaAtRisk {
+		t.Errorf("with the right mapping got match=%v nearMisses=%v classified=%+v", ex.Match, ex.NearMisses, ex.Classified)
+	}
+}
@@ -0,0 +1,381 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// This file implements the small, side-effect-free expression language used
+// by custom classification rules, e.g.
+//
+//	source == "torrent" && age_days > 30 && nlink == 1
+//
+// Expressions are type-checked at compile time, so a misconfigured rule fails
+// at startup rather than silently never matching.
+
+type exprType int
+
+const (
+	typeNumber exprType = iota
+	typeString
+	typeBool
+)
+
+func (t exprType) String() string {
+	switch t {
+	case typeNumber:
+		return "number"
+	case typeString:
+		return "string"
+	default:
+		return "bool"
+	}
+}
+
+// exprEnv is the data a rule's expression is evaluated against.
+type exprEnv struct {
+	media   *models.MediaFile
+	tracked bool
+	now     time.Time
+}
+
+type exprValue struct {
+	num float64
+	str string
+	b   bool
+}
+
+var exprVariables = map[string]struct {
+	typ exprType
+	get func(env exprEnv) exprValue
+}{
+	"size":       {typeNumber, func(env exprEnv) exprValue { return exprValue{num: float64(env.media.Size)} }},
+	"age_hours":  {typeNumber, func(env exprEnv) exprValue { return exprValue{num: env.now.Sub(env.media.ModTime).Hours()} }},
+	"age_days":   {typeNumber, func(env exprEnv) exprValue { return exprValue{num: env.now.Sub(env.media.ModTime).Hours() / 24} }},
+	"nlink":      {typeNumber, func(env exprEnv) exprValue { return exprValue{num: float64(env.media.HardlinkCount)} }},
+	"hardlinked": {typeBool, func(env exprEnv) exprValue { return exprValue{b: env.media.IsHardlinked} }},
+	"hidden":     {typeBool, func(env exprEnv) exprValue { return exprValue{b: env.media.IsHidden} }},
+	"tracked":    {typeBool, func(env exprEnv) exprValue { return exprValue{b: env.tracked} }},
+	"source":     {typeString, func(env exprEnv) exprValue { return exprValue{str: string(env.media.Source)} }},
+	"path":       {typeString, func(env exprEnv) exprValue { return exprValue{str: env.media.Path} }},
+	"name":       {typeString, func(env exprEnv) exprValue { return exprValue{str: filepath.Base(env.media.Path)} }},
+	"ext":        {typeString, func(env exprEnv) exprValue { return exprValue{str: env.media.Ext()} }},
+}
+
+var sizeSuffixes = map[string]float64{
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+	"TB": 1 << 40,
+}
+
+type exprNode interface {
+	typ() exprType
+	eval(env exprEnv) exprValue
+}
+
+type literalNode struct {
+	t exprType
+	v exprValue
+}
+
+func (n literalNode) typ() exprType          { return n.t }
+func (n literalNode) eval(exprEnv) exprValue { return n.v }
+
+type variableNode struct {
+	t   exprType
+	get func(env exprEnv) exprValue
+}
+
+func (n variableNode) typ() exprType              { return n.t }
+func (n variableNode) eval(env exprEnv) exprValue { return n.get(env) }
+
+type notNode struct{ x exprNode }
+
+func (n notNode) typ() exprType              { return typeBool }
+func (n notNode) eval(env exprEnv) exprValue { return exprValue{b: !n.x.eval(env).b} }
+
+type logicalNode struct {
+	op   string
+	l, r exprNode
+}
+
+func (n logicalNode) typ() exprType { return typeBool }
+func (n logicalNode) eval(env exprEnv) exprValue {
+	l := n.l.eval(env).b
+	if n.op == "&&" {
+		return exprValue{b: l && n.r.eval(env).b}
+	}
+	return exprValue{b: l || n.r.eval(env).b}
+}
+
+type compareNode struct {
+	op   string
+	l, r exprNode
+}
+
+func (n compareNode) typ() exprType { return typeBool }
+func (n compareNode) eval(env exprEnv) exprValue {
+	l, r := n.l.eval(env), n.r.eval(env)
+	switch n.l.typ() {
+	case typeNumber:
+		return exprValue{b: compareOrdered(n.op, l.num, r.num)}
+	case typeString:
+		if n.op == "~=" {
+			matched, _ := filepath.Match(r.str, l.str)
+			return exprValue{b: matched}
+		}
+		return exprValue{b: compareOrdered(n.op, l.str, r.str)}
+	default:
+		if n.op == "==" {
+			return exprValue{b: l.b == r.b}
+		}
+		return exprValue{b: l.b != r.b}
+	}
+}
+
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+// compileExpr parses and type-checks a rule expression. The result must be
+// boolean.
+func compileExpr(src string) (exprNode, error) {
+	tokens, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if node.typ() != typeBool {
+		return nil, fmt.Errorf("expression must be a condition, got %s", node.typ())
+	}
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+func tokenizeExpr(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			num, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", src[start:i])
+			}
+			unitStart := i
+			for i < len(src) && unicode.IsLetter(rune(src[i])) {
+				i++
+			}
+			if unit := strings.ToUpper(src[unitStart:i]); unit != "" {
+				mult, ok := sizeSuffixes[unit]
+				if !ok {
+					return nil, fmt.Errorf("unknown unit %q (use KB, MB, GB or TB)", src[unitStart:i])
+				}
+				num *= mult
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], num: num})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i]})
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "~=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peekOp(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseLogical("&&", p.parseNot)
+}
+
+func (p *exprParser) parseLogical(op string, next func() (exprNode, error)) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.peekOp(op); !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ() != typeBool || right.typ() != typeBool {
+			return nil, fmt.Errorf("%s requires conditions on both sides", op)
+		}
+		left = logicalNode{op: op, l: left, r: right}
+	}
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if _, ok := p.peekOp("!"); ok {
+		p.pos++
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if x.typ() != typeBool {
+			return nil, fmt.Errorf("! requires a condition, got %s", x.typ())
+		}
+		return notNode{x: x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.peekOp("==", "!=", "<=", ">=", "<", ">", "~=")
+	if !ok {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if left.typ() != right.typ() {
+		return nil, fmt.Errorf("cannot compare %s with %s", left.typ(), right.typ())
+	}
+	switch {
+	case op == "~=" && left.typ() != typeString:
+		return nil, fmt.Errorf("~= (glob match) requires strings")
+	case left.typ() == typeBool && op != "==" && op != "!=":
+		return nil, fmt.Errorf("%s is not valid for conditions", op)
+	}
+	return compareNode{op: op, l: left, r: right}, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokNumber:
+		return literalNode{t: typeNumber, v: exprValue{num: tok.num}}, nil
+	case tokString:
+		return literalNode{t: typeString, v: exprValue{str: tok.text}}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{t: typeBool, v: exprValue{b: true}}, nil
+		case "false":
+			return literalNode{t: typeBool, v: exprValue{b: false}}, nil
+		}
+		v, ok := exprVariables[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", tok.text)
+		}
+		return variableNode{t: v.typ, get: v.get}, nil
+	default:
+		if tok.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.peekOp(")"); !ok {
+				return nil, fmt.Errorf("missing closing parenthesis")
+			}
+			p.pos++
+			return node, nil
+		}
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+}
@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// PermissionFix is the ownership and mode change that resolves every
+// permission issue reported for one path. Fields left at their current value
+// are not changed.
+type PermissionFix struct {
+	Path        string
+	IsDirectory bool
+	Issues      []models.ReasonCode
+
+	FromUID, UID   int
+	FromGID, GID   int
+	FromMode, Mode uint32
+}
+
+// NeedsChown reports whether the fix changes the owner or group.
+func (f PermissionFix) NeedsChown() bool {
+	return f.UID != f.FromUID || f.GID != f.FromGID
+}
+
+// NeedsChmod reports whether the fix changes the mode.
+func (f PermissionFix) NeedsChmod() bool {
+	return f.Mode != f.FromMode
+}
+
+// String describes the change, e.g. "chown 1001:1000 (was 0:0), chmod 2775
+// (was 0755)".
+func (f PermissionFix) String() string {
+	var parts []string
+	if f.NeedsChown() {
+		parts = append(parts, fmt.Sprintf("chown %d:%d (was %d:%d)", f.UID, f.GID, f.FromUID, f.FromGID))
+	}
+	if f.NeedsChmod() {
+		parts = append(parts, fmt.Sprintf("chmod %04o (was %04o)", f.Mode, f.FromMode))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PlanPermissionFixes turns permission issues into one fix per path, using
+// the collected records for each path's current state. Wrong owners are only
+// fixed when ownerUID is non-negative, since the audit accepts several owners
+// and can't pick one. Issues with nothing to change, paths under skip_paths
+// and metadata files are left out, so planning again after applying yields
+// nothing for the issues that were fixed.
+func (e *Engine) PlanPermissionFixes(issues []models.PermissionIssue, permissions []models.FilePermissions, ownerUID int) []PermissionFix {
+	current := make(map[string]models.FilePermissions, len(permissions))
+	for _, p := range permissions {
+		current[p.Path] = p
+	}
+
+	fixes := make(map[string]*PermissionFix)
+	for _, issue := range issues {
+		perm, ok := current[issue.Path]
+		if !ok || shouldSkip(issue.Path, e.skipPaths) || IsMetadataFile(issue.Path) {
+			continue
+		}
+		fix := fixes[issue.Path]
+		if fix == nil {
+			mode := perm.Mode & 07777
+			fix = &PermissionFix{
+				Path:        perm.Path,
+				IsDirectory: perm.IsDirectory,
+				FromUID:     perm.OwnerUID,
+				UID:         perm.OwnerUID,
+				FromGID:     perm.GroupGID,
+				GID:         perm.GroupGID,
+				FromMode:    mode,
+				Mode:        mode,
+			}
+			fixes[issue.Path] = fix
+		}
+
+		switch issue.Issue {
+		case models.ReasonWrongOwner:
+			if ownerUID < 0 {
+				continue
+			}
+			fix.UID = ownerUID
+		case models.ReasonWrongGroup:
+			fix.GID = e.expectedGroupGID
+		case models.ReasonNotGroupWritable:
+			fix.Mode |= 0020
+		case models.ReasonNonstandardPermissions:
+			fix.Mode = fix.Mode&^0777 | issue.ExpectedMode&0777
+		case models.ReasonMissingSGID:
+			fix.Mode |= 02000
+		default:
+			continue
+		}
+		fix.Issues = append(fix.Issues, issue.Issue)
+	}
+
+	var planned []PermissionFix
+	for _, fix := range fixes {
+		if fix.NeedsChown() || fix.NeedsChmod() {
+			planned = append(planned, *fix)
+		}
+	}
+	sort.Slice(planned, func(i, j int) bool {
+		return planned[i].Path < planned[j].Path
+	})
+	return planned
+}
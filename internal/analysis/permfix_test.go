@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestPlanPermissionFixes(t *testing.T) {
+	e := &Engine{
+		permissionsEnabled: true,
+		expectedGroupGID:   1000,
+		allowedUIDs:        []int{1001},
+		sgidPaths:          []string{"/media"},
+		skipPaths:          []string{"/media/skip/"},
+		expectedFileMode:   0664,
+		expectedDirMode:    0775,
+	}
+	perms := []models.FilePermissions{
+		{Path: "/media/tv", Mode: 0755, OwnerUID: 1001, GroupGID: 0, IsDirectory: true},
+		{Path: "/media/tv/ep.mkv", Mode: 0600, OwnerUID: 0, GroupGID: 1000},
+		{Path: "/media/tv/poster.jpg", Mode: 0600, OwnerUID: 0, GroupGID: 0},
+		{Path: "/media/skip/ep.mkv", Mode: 0600, OwnerUID: 0, GroupGID: 0},
+		{Path: "/media/tv/ok.mkv", Mode: 0664, OwnerUID: 1001, GroupGID: 1000},
+	}
+	result := e.Analyze(nil, nil, nil, nil, perms)
+
+	fixes := e.PlanPermissionFixes(result.PermissionIssues, perms, -1)
+	if len(fixes) != 2 {
+		t.Fatalf("got %d fixes, want 2: %+v", len(fixes), fixes)
+	}
+	dir, file := fixes[0], fixes[1]
+	if dir.Path != "/media/tv" || dir.GID != 1000 || dir.Mode != 02775 {
+		t.Errorf("directory fix = %+v, want group 1000 and mode 2775", dir)
+	}
+	if file.Path != "/media/tv/ep.mkv" || file.UID != 0 || file.Mode != 0620 || file.NeedsChown() {
+		t.Errorf("file fix = %+v, want only g+w with the owner left alone", file)
+	}
+
+	if fixes := e.PlanPermissionFixes(result.PermissionIssues, perms, 1001); fixes[1].UID != 1001 {
+		t.Errorf("with an owner UID the file should be chowned to it, got %+v", fixes[1])
+	}
+
+	fixed := []models.FilePermissions{
+		{Path: "/media/tv", Mode: 02775, OwnerUID: 1001, GroupGID: 1000, IsDirectory: true},
+	}
+	if again := e.PlanPermissionFixes(e.Analyze(nil, nil, nil, nil, fixed).PermissionIssues, fixed, -1); len(again) != 0 {
+		t.Errorf("planning after the fix was applied should find nothing, got %+v", again)
+	}
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// SetProtectedPaths marks paths that are scanned but never flagged: files
+// under them are always reported healthy. Each entry is a directory prefix or
+// a glob; a glob protects a matching file and everything under a matching
+// directory.
+func (e *Engine) SetProtectedPaths(paths []string) {
+	e.protectedPaths = paths
+}
+
+// SetExpectedUntrackedPaths marks paths whose files are intentionally not in
+// Arr, e.g. a tree of manual imports. Files there that would be orphans are
+// classified as expected untracked instead, so they stay inventoried without
+// counting as orphans. Entries take the same form as protected paths.
+func (e *Engine) SetExpectedUntrackedPaths(paths []string) {
+	e.expectedUntracked = paths
+}
+
+func (e *Engine) isProtected(path string) bool {
+	return matchesPathPattern(path, e.protectedPaths)
+}
+
+func (e *Engine) isExpectedUntracked(path string) bool {
+	return matchesPathPattern(path, e.expectedUntracked)
+}
+
+// matchesPathPattern reports whether path is under one of patterns, each a
+// directory prefix or a glob matching the path or one of its parents.
+func matchesPathPattern(path string, patterns []string) bool {
+	for _, p := range patterns {
+		if !strings.ContainsAny(p, "*?[") {
+			if utils.IsUnderPath(path, p) {
+				return true
+			}
+			continue
+		}
+		for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+			if ok, _ := filepath.Match(p, dir); ok {
+				return true
+			}
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return false
+}
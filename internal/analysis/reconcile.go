@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"os"
+	"sort"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// ArrReconciliation compares what one Arr service tracks with what the scan
+// found on disk. A large Missing count usually means a mount is down or a
+// path mapping is wrong, rather than that files were actually deleted.
+type ArrReconciliation struct {
+	Service string
+	Tracked int
+	Found   int
+	// TrackedBytes totals the sizes Arr recorded for the tracked files, so
+	// library size per service can be compared with what is on disk.
+	TrackedBytes int64
+	// Missing holds the filesystem paths of tracked files that were not
+	// found, sorted.
+	Missing []string
+}
+
+// reconcileArr counts, per service, the tracked files whose mapped path is
+// (or is not) present according to exists. Files under skip paths are left
+// out since the scan ignores them too.
+func reconcileArr(sonarrFiles, radarrFiles []models.ArrFile, pathMappings map[string]string, skipPaths []string, exists func(fsPath string) bool) []ArrReconciliation {
+	var out []ArrReconciliation
+	index := make(map[string]int)
+	add := func(files []models.ArrFile, defaultService string) {
+		for _, f := range files {
+			fsPath := utils.NormalizePath(f.Path, pathMappings)
+			if shouldSkip(fsPath, skipPaths) {
+				continue
+			}
+			service := f.Service
+			if service == "" {
+				service = defaultService
+			}
+			i, ok := index[service]
+			if !ok {
+				i = len(out)
+				index[service] = i
+				out = append(out, ArrReconciliation{Service: service})
+			}
+			out[i].Tracked++
+			out[i].TrackedBytes += f.Size
+			if exists(fsPath) {
+				out[i].Found++
+			} else {
+				out[i].Missing = append(out[i].Missing, fsPath)
+			}
+		}
+	}
+	add(sonarrFiles, "Sonarr")
+	add(radarrFiles, "Radarr")
+	for i := range out {
+		sort.Strings(out[i].Missing)
+	}
+	return out
+}
+
+// ReconcileArrOnDisk stats every tracked file directly instead of relying on
+// a filesystem walk, for a quick Arr-only consistency check.
+func ReconcileArrOnDisk(sonarrFiles, radarrFiles []models.ArrFile, pathMappings map[string]string, skipPaths []string) []ArrReconciliation {
+	return reconcileArr(sonarrFiles, radarrFiles, pathMappings, skipPaths, func(fsPath string) bool {
+		_, err := os.Stat(fsPath)
+		return err == nil
+	})
+}
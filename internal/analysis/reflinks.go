@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// Reflink modes for SetReflinks.
+const (
+	// ReflinksDetect compares the extents of each unlinked library file with
+	// a same-name, same-size torrent file and treats matching pairs as
+	// sharing their data.
+	ReflinksDetect = "detect"
+	// ReflinksAssume treats every library file as sharing its data with the
+	// download, for libraries imported by reflink on filesystems without
+	// FIEMAP.
+	ReflinksAssume = "assume"
+)
+
+// markReflinks returns mediaFiles with IsReflinked set on the files whose
+// data is shared copy-on-write rather than hardlinked. The input slice is
+// left untouched.
+func (e *Engine) markReflinks(mediaFiles []models.MediaFile) []models.MediaFile {
+	if e.reflinks != ReflinksDetect && e.reflinks != ReflinksAssume {
+		return mediaFiles
+	}
+	marked := make([]models.MediaFile, len(mediaFiles))
+	copy(marked, mediaFiles)
+
+	if e.reflinks == ReflinksAssume {
+		for i := range marked {
+			if marked[i].Source == models.MediaSourceLibrary && !marked[i].IsHardlinked {
+				marked[i].IsReflinked = true
+			}
+		}
+		return marked
+	}
+
+	// Reflinked copies keep the download's name and size, so only those
+	// pairs are worth an extent comparison.
+	type key struct {
+		name string
+		size int64
+	}
+	torrents := make(map[key][]int)
+	for i, f := range marked {
+		if f.Source == models.MediaSourceTorrent && !f.IsHardlinked && f.Size > 0 {
+			k := key{strings.ToLower(filepath.Base(f.Path)), f.Size}
+			torrents[k] = append(torrents[k], i)
+		}
+	}
+	if len(torrents) == 0 {
+		return marked
+	}
+	sharesExtents := e.sharesExtents
+	if sharesExtents == nil {
+		sharesExtents = utils.SharesExtents
+	}
+	for i, f := range marked {
+		if f.Source != models.MediaSourceLibrary || f.IsHardlinked || f.Size == 0 {
+			continue
+		}
+		for _, j := range torrents[key{strings.ToLower(filepath.Base(f.Path)), f.Size}] {
+			if sharesExtents(f.Path, marked[j].Path) {
+				marked[i].IsReflinked = true
+				marked[j].IsReflinked = true
+				break
+			}
+		}
+	}
+	return marked
+}
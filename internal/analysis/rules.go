@@ -1,9 +1,66 @@
 package analysis
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/jdpx/auditarr/internal/models"
 )
 
+// ruleIgnore is a custom-rule outcome that drops the file from the report.
+const ruleIgnore models.MediaClassification = "ignore"
+
+// ClassificationRule is a user-defined rule evaluated ahead of the built-in
+// classification logic. The first rule whose condition matches wins.
+type ClassificationRule struct {
+	Name           string
+	Classification models.MediaClassification
+	cond           exprNode
+}
+
+func CompileRule(name, when, classification string) (ClassificationRule, error) {
+	cls := models.MediaClassification(classification)
+	switch cls {
+	case models.MediaHealthy, models.MediaAtRisk, models.MediaOrphan, models.MediaOrphanedDownload,
+		models.MediaHiddenFile, models.MediaLostAndFound, models.MediaOtherFile, models.MediaExpectedUntracked, ruleIgnore:
+	default:
+		return ClassificationRule{}, fmt.Errorf("rule %q: unknown classification %q", name, classification)
+	}
+
+	cond, err := compileExpr(when)
+	if err != nil {
+		return ClassificationRule{}, fmt.Errorf("rule %q: invalid condition: %w", name, err)
+	}
+
+	return ClassificationRule{Name: name, Classification: cls, cond: cond}, nil
+}
+
+// ClassifyByRules applies custom rules to a file, returning the rule that
+// matched or nil. Files within the grace window are never matched so rules
+// cannot defeat the import grace period.
+func ClassifyByRules(
+	rules []ClassificationRule,
+	media models.MediaFile,
+	arrFile *models.ArrFile,
+	graceHours int,
+) (models.MediaClassification, bool, *ClassificationRule) {
+	if len(rules) == 0 || media.WithinGraceWindow(graceHours) {
+		return "", false, nil
+	}
+
+	env := exprEnv{media: &media, tracked: arrFile.IsKnown(), now: time.Now()}
+	for i := range rules {
+		if rules[i].cond.eval(env).b {
+			if rules[i].Classification == ruleIgnore {
+				return "", false, &rules[i]
+			}
+			return rules[i].Classification, true, &rules[i]
+		}
+	}
+
+	return "", false, nil
+}
+
 func ClassifyMedia(
 	media models.MediaFile,
 	arrFile *models.ArrFile,
@@ -25,7 +82,7 @@ func ClassifyMedia(
 		return models.MediaOrphan, true
 	}
 
-	if media.IsHardlinked {
+	if media.IsHardlinked || media.IsReflinked {
 		return models.MediaHealthy, true
 	}
 
@@ -50,7 +107,7 @@ func ClassifyTorrentFile(
 		return "", false
 	}
 
-	if media.IsHardlinked {
+	if media.IsHardlinked || media.IsReflinked {
 		return models.MediaHealthy, true
 	}
 
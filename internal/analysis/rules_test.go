@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestCompileExpr_Evaluates(t *testing.T) {
+	media := models.MediaFile{
+		Path:          "/mnt/torrents/tv/Show.S01E01.mkv",
+		Size:          3 << 30,
+		ModTime:       time.Now().Add(-45 * 24 * time.Hour),
+		HardlinkCount: 1,
+		Source:        models.MediaSourceTorrent,
+	}
+	env := exprEnv{media: &media, now: time.Now()}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{`source == "torrent" && age_days > 30 && nlink == 1`, true},
+		{`source == 'library'`, false},
+		{`size > 2GB && size < 4GB`, true},
+		{`!hardlinked && !tracked`, true},
+		{`ext == ".mkv" || hidden`, true},
+		{`(age_days < 10 || nlink > 1) && true`, false},
+		{`name ~= "*.S01E*.mkv"`, true},
+	}
+	for _, c := range cases {
+		node, err := compileExpr(c.expr)
+		if err != nil {
+			t.Errorf("compileExpr(%q): %v", c.expr, err)
+			continue
+		}
+		if got := node.eval(env).b; got != c.want {
+			t.Errorf("%q = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestCompileExpr_RejectsInvalid(t *testing.T) {
+	for _, expr := range []string{
+		`size > "big"`,
+		`unknown_field == 1`,
+		`size`,
+		`age_days > 30 &&`,
+		`(nlink == 1`,
+		`size > 5XB`,
+		`hardlinked < true`,
+	} {
+		if _, err := compileExpr(expr); err == nil {
+			t.Errorf("compileExpr(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestClassifyByRules_FirstMatchWinsAndRespectsGrace(t *testing.T) {
+	stale, err := CompileRule("stale", `source == "torrent" && nlink == 1`, "orphaned_download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := CompileRule("archive", `path ~= "/mnt/torrents/archive/*"`, "ignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := []ClassificationRule{archive, stale}
+
+	old := models.MediaFile{Path: "/mnt/torrents/a.mkv", HardlinkCount: 1, Source: models.MediaSourceTorrent, ModTime: time.Now().Add(-72 * time.Hour)}
+	if cls, incl, rule := ClassifyByRules(rules, old, nil, 24); rule == nil || rule.Name != "stale" || cls != models.MediaOrphanedDownload || !incl {
+		t.Errorf("got (%q, %v, %v), want stale rule orphaned_download", cls, incl, rule)
+	}
+
+	archived := old
+	archived.Path = "/mnt/torrents/archive/b.mkv"
+	if _, incl, rule := ClassifyByRules(rules, archived, nil, 24); rule == nil || rule.Name != "archive" || incl {
+		t.Errorf("archive rule should match and exclude the file")
+	}
+
+	recent := old
+	recent.ModTime = time.Now()
+	if _, _, rule := ClassifyByRules(rules, recent, nil, 24); rule != nil {
+		t.Errorf("file within grace window matched rule %q", rule.Name)
+	}
+}
+
+func TestClassifyByRules_TrackedMeansKnownToArr(t *testing.T) {
+	untracked, err := CompileRule("untracked", `!tracked`, "orphan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := []ClassificationRule{untracked}
+	media := models.MediaFile{Path: "/mnt/media/tv/a.mkv", ModTime: time.Now().Add(-72 * time.Hour)}
+
+	for _, tc := range []struct {
+		name    string
+		arrFile *models.ArrFile
+		matches bool
+	}{
+		{"no Arr record", nil, true},
+		{"record without an item", &models.ArrFile{Path: "/tv/a.mkv"}, true},
+		{"episode file", &models.ArrFile{Path: "/tv/a.mkv", SeriesID: 1}, false},
+	} {
+		if _, _, rule := ClassifyByRules(rules, media, tc.arrFile, 24); (rule != nil) != tc.matches {
+			t.Errorf("%s: !tracked matched = %t, want %t", tc.name, rule != nil, tc.matches)
+		}
+	}
+}
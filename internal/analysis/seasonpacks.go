@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// SeasonPack is one season of a completed torrent whose episodes were only
+// partly imported: some share their data with the library, the rest are
+// still only in the download folder.
+type SeasonPack struct {
+	Torrent string
+	Series  string
+	Season  int
+	// Imported are the episodes linked into the library.
+	Imported []SeasonPackFile
+	// Leftover are the torrent paths of episodes with no library copy.
+	Leftover []string
+}
+
+// SeasonPackFile is an imported episode and the library file sharing its data.
+type SeasonPackFile struct {
+	TorrentPath string
+	LibraryPath string
+}
+
+var (
+	// episodeSeason matches S02E05 and 2x05 episode markers.
+	episodeSeason = regexp.MustCompile(`(?i)(?:\bs(\d{1,2})e\d{1,3}|\b(\d{1,2})x\d{2}\b)`)
+	// packSeason matches the season in a pack name such as Show.S02.1080p.
+	packSeason = regexp.MustCompile(`(?i)(?:^|[ ._\-\[(])s(\d{1,2})(?:[ ._\-\])e]|$)`)
+)
+
+// seasonOf returns the season number in name, or -1 when it has none.
+func seasonOf(name string, re *regexp.Regexp) int {
+	m := re.FindStringSubmatch(name)
+	if m == nil {
+		return -1
+	}
+	for _, g := range m[1:] {
+		if n, err := strconv.Atoi(g); err == nil {
+			return n
+		}
+	}
+	return -1
+}
+
+// seriesName turns a release name into a readable title: the part before
+// the season marker, with dots and underscores as spaces.
+func seriesName(torrentName string) string {
+	name := torrentName
+	if loc := packSeason.FindStringIndex(name); loc != nil && loc[0] > 0 {
+		name = name[:loc[0]]
+	}
+	name = strings.NewReplacer(".", " ", "_", " ").Replace(name)
+	return strings.TrimSpace(name)
+}
+
+// findPartialSeasonPacks groups the episodes of each completed multi-episode
+// torrent by season and reports the seasons where some but not all episodes
+// share their data (by inode or reflink) with a library file.
+func (e *Engine) findPartialSeasonPacks(torrents []models.Torrent, mediaFiles []models.MediaFile) []SeasonPack {
+	library := make(map[inodeKey]string)
+	scanned := make(map[string]models.MediaFile)
+	for _, f := range mediaFiles {
+		switch f.Source {
+		case models.MediaSourceLibrary:
+			if f.Inode != 0 {
+				library[inodeKey{f.Device, f.Inode}] = f.Path
+			}
+		case models.MediaSourceTorrent:
+			scanned[e.normalizePath(f.Path)] = f
+		}
+	}
+	if len(scanned) == 0 {
+		return nil
+	}
+
+	var packs []SeasonPack
+	for _, t := range torrents {
+		complete := t.IsComplete() || (e.includeUnknownState && t.State == models.StateUnknown)
+		if !complete || t.WithinGraceWindow(e.torrentGraceHours(t)) || len(t.Files) < 2 {
+			continue
+		}
+		bySeason := make(map[int]*SeasonPack)
+		for _, rel := range t.Files {
+			if !utils.IsMediaFile(rel) {
+				continue
+			}
+			season := seasonOf(filepath.Base(rel), episodeSeason)
+			if season < 0 {
+				continue
+			}
+			hostPath := utils.NormalizePath(filepath.Join(t.SavePath, rel), e.pathMappings)
+			f, ok := scanned[e.normalizePath(hostPath)]
+			if !ok || shouldSkip(f.Path, e.skipPaths) {
+				continue
+			}
+			pack := bySeason[season]
+			if pack == nil {
+				pack = &SeasonPack{Torrent: t.Name, Series: seriesName(t.Name), Season: season}
+				bySeason[season] = pack
+			}
+			libPath, linked := library[inodeKey{f.Device, f.Inode}]
+			switch {
+			case linked && f.Inode != 0:
+				pack.Imported = append(pack.Imported, SeasonPackFile{TorrentPath: f.Path, LibraryPath: libPath})
+			case f.IsReflinked:
+				pack.Imported = append(pack.Imported, SeasonPackFile{TorrentPath: f.Path})
+			default:
+				pack.Leftover = append(pack.Leftover, f.Path)
+			}
+		}
+		for _, pack := range bySeason {
+			if len(pack.Imported) == 0 || len(pack.Leftover) == 0 {
+				continue
+			}
+			sort.Slice(pack.Imported, func(i, j int) bool {
+				return pack.Imported[i].TorrentPath < pack.Imported[j].TorrentPath
+			})
+			sort.Strings(pack.Leftover)
+			packs = append(packs, *pack)
+		}
+	}
+
+	sort.Slice(packs, func(i, j int) bool {
+		if packs[i].Series != packs[j].Series {
+			return packs[i].Series < packs[j].Series
+		}
+		if packs[i].Season != packs[j].Season {
+			return packs[i].Season < packs[j].Season
+		}
+		return packs[i].Torrent < packs[j].Torrent
+	})
+	return packs
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// directoryMetadata are metadata files describing a whole folder rather than
+// one video; they have no stem to match and are never reported as sidecars.
+var directoryMetadata = map[string]bool{
+	"tvshow.nfo": true,
+	"movie.nfo":  true,
+	"season.nfo": true,
+}
+
+// isFileSidecar reports whether path is a per-video companion file: a
+// subtitle, an .nfo, or a "-thumb" image named after its video.
+func isFileSidecar(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if directoryMetadata[name] {
+		return false
+	}
+	if utils.IsSubtitleFile(path) {
+		return true
+	}
+	ext := models.Ext(path)
+	if ext == ".nfo" {
+		return true
+	}
+	stem := strings.TrimSuffix(name, ext)
+	return (ext == ".jpg" || ext == ".png") && strings.HasSuffix(stem, "-thumb")
+}
+
+// sidecarStem returns the part of a sidecar's name that should equal its
+// video's name without extension.
+func sidecarStem(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	stem := strings.TrimSuffix(name, models.Ext(name))
+	return strings.TrimSuffix(stem, "-thumb")
+}
+
+// findOrphanedSidecars returns library sidecars with no sibling video whose
+// name they match. A subtitle such as "Movie.en.forced.srt" belongs to
+// "Movie.mkv"; the stem must equal the video's or extend it after a dot.
+func findOrphanedSidecars(sidecars, mediaFiles []models.MediaFile) []models.MediaFile {
+	videos := make(map[string][]string)
+	for _, f := range mediaFiles {
+		if f.Source == models.MediaSourceLibrary && utils.IsMediaFile(f.Path) {
+			dir := filepath.Dir(f.Path)
+			name := strings.ToLower(filepath.Base(f.Path))
+			videos[dir] = append(videos[dir], strings.TrimSuffix(name, models.Ext(name)))
+		}
+	}
+
+	var orphaned []models.MediaFile
+	for _, sc := range sidecars {
+		if sc.Source != models.MediaSourceLibrary || !isFileSidecar(sc.Path) {
+			continue
+		}
+		stem := sidecarStem(sc.Path)
+		matched := false
+		for _, video := range videos[filepath.Dir(sc.Path)] {
+			if stem == video || strings.HasPrefix(stem, video+".") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			orphaned = append(orphaned, sc)
+		}
+	}
+	return orphaned
+}
+
+func sidecarReason(path string) string {
+	return fmt.Sprintf("No video named %q in the same directory", sidecarStem(path))
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// SetSuspiciousSeverities sets the severity of suspicious findings per
+// extension; extensions without one are critical.
+func (e *Engine) SetSuspiciousSeverities(severities map[string]string) {
+	e.suspiciousSeverities = make(map[string]string, len(severities))
+	for ext, severity := range severities {
+		e.suspiciousSeverities[models.NormalizeExtension(ext)] = severity
+	}
+}
+
+// SetMaxExpectedHardlinks flags files with more than n links as suspicious.
+// Zero disables the check.
+func (e *Engine) SetMaxExpectedHardlinks(n int) {
+	e.maxHardlinks = n
+}
+
+// checkSuspicious returns the suspicious findings for media: one for a
+// suspicious extension and one for a link count above the configured
+// maximum.
+func (e *Engine) checkSuspicious(media models.MediaFile) []models.SuspiciousFile {
+	var found []models.SuspiciousFile
+	if ok, code := models.IsSuspicious(media.Path, e.suspiciousExtensions, e.flagArchives); ok {
+		ext := models.Ext(media.Path)
+		severity := e.suspiciousSeverities[ext]
+		if severity == "" {
+			severity = models.SuspiciousCritical
+		}
+		reason := fmt.Sprintf("Suspicious extension %s", ext)
+		if code == models.ReasonDoubleExtension {
+			reason = fmt.Sprintf("Double extension ending in %s", ext)
+		}
+		found = append(found, models.SuspiciousFile{Path: media.Path, Code: code, Reason: reason, Severity: severity})
+	}
+	if e.maxHardlinks > 0 && media.HardlinkCount > e.maxHardlinks {
+		found = append(found, models.SuspiciousFile{
+			Path:     media.Path,
+			Code:     models.ReasonExcessiveHardlinks,
+			Reason:   fmt.Sprintf("Suspicious hardlink count: %d links, expected at most %d", media.HardlinkCount, e.maxHardlinks),
+			Severity: models.SuspiciousWarning,
+		})
+	}
+	return found
+}
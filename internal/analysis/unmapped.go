@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// SetArrUnmappedFolders supplies the unmapped folders Sonarr/Radarr report
+// in their root folders, using the Arr services' own paths. Orphans under
+// one are marked ArrConfirmed. Calling this at all, even with no folders,
+// turns the cross-check on.
+func (e *Engine) SetArrUnmappedFolders(paths []string) {
+	e.arrUnmapped = make([]string, 0, len(paths))
+	for _, p := range paths {
+		e.arrUnmapped = append(e.arrUnmapped, e.normalizePath(utils.NormalizePath(p, e.pathMappings)))
+	}
+}
+
+// confirmOrphans marks the orphans that fall under an Arr unmapped folder and
+// returns how many there were.
+func (e *Engine) confirmOrphans(classified []models.ClassifiedMedia) int {
+	confirmed := 0
+	for i := range classified {
+		cm := &classified[i]
+		if cm.Classification != models.MediaOrphan {
+			continue
+		}
+		path := e.normalizePath(cm.File.Path)
+		for _, dir := range e.arrUnmapped {
+			if utils.IsUnderPath(path, dir) {
+				cm.ArrConfirmed = true
+				confirmed++
+				break
+			}
+		}
+	}
+	return confirmed
+}
@@ -0,0 +1,62 @@
+package collectors
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// ArrCollector is implemented by every *arr service whose tracked files can
+// be audited: Sonarr, Radarr and their API-compatible forks.
+type ArrCollector interface {
+	Name() string
+	TestConnection(ctx context.Context) error
+	Collect(ctx context.Context) ([]models.ArrFile, error)
+	FetchRootFolders(ctx context.Context) ([]models.RootFolder, error)
+	// SetAuth adds basic auth and arbitrary headers to every request, for
+	// instances behind an authenticating reverse proxy.
+	SetAuth(username, password string, headers map[string]string)
+	// SetRootCAs replaces the roots used to verify the server's certificate.
+	SetRootCAs(pool *x509.CertPool)
+	// SetTransport sends requests through transport, typically one shared
+	// by every client so connections are pooled per host. It replaces any
+	// transport set by SetRootCAs.
+	SetTransport(transport http.RoundTripper)
+	// SetRequestTimeout bounds each HTTP request; zero keeps the default.
+	SetRequestTimeout(timeout time.Duration)
+	// SetCircuitBreaker stops issuing requests for the rest of the run once
+	// threshold consecutive requests have failed. Zero disables it.
+	SetCircuitBreaker(threshold int)
+	// SkippedRequests returns how many requests the circuit breaker refused.
+	SkippedRequests() int
+	// FailedFetches describes each per-item request (e.g. one series'
+	// episode files) that failed and was skipped, leaving Collect's result
+	// incomplete.
+	FailedFetches() []string
+}
+
+// ArrKinds lists the service kinds accepted by NewArrCollector.
+var ArrKinds = []string{"sonarr", "radarr", "whisparr", "lidarr"}
+
+// NewArrCollector returns a collector for the given service kind. Whisparr
+// shares Radarr's v3 movie API; Lidarr uses its own v1 artist/track API.
+func NewArrCollector(kind, baseURL, apiKey string) (ArrCollector, error) {
+	switch kind {
+	case "sonarr":
+		return NewSonarrCollector(baseURL, apiKey), nil
+	case "radarr":
+		return NewRadarrCollector(baseURL, apiKey), nil
+	case "whisparr":
+		rc := NewRadarrCollector(baseURL, apiKey)
+		rc.name = "whisparr"
+		return rc, nil
+	case "lidarr":
+		return NewLidarrCollector(baseURL, apiKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported arr kind %q", kind)
+	}
+}
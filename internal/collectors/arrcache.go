@@ -0,0 +1,123 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// IncrementalArrCollector is implemented by collectors that can refetch only
+// what changed since an earlier collection, per the service's history.
+type IncrementalArrCollector interface {
+	// CollectSince returns the service's tracked files, built from cached
+	// (the result of an earlier collection that started at since) plus
+	// fresh files for everything with history after since.
+	CollectSince(ctx context.Context, since time.Time, cached []models.ArrFile) ([]models.ArrFile, error)
+}
+
+// nonFileEvents are history event types that never add, move or remove a
+// file, so they don't require refetching the item's files.
+var nonFileEvents = map[string]bool{
+	"grabbed":         true,
+	"downloadFailed":  true,
+	"downloadIgnored": true,
+}
+
+func changesFiles(eventType string) bool {
+	return !nonFileEvents[eventType]
+}
+
+// fetchArrHistorySince returns the Sonarr/Radarr v3 history records dated
+// after since.
+func fetchArrHistorySince[T any](ctx context.Context, client *http.Client, baseURL, apiKey string, since time.Time) ([]T, error) {
+	endpoint := fmt.Sprintf("%s/api/v3/history/since?date=%s", baseURL, url.QueryEscape(since.UTC().Format(time.RFC3339)))
+	records, err := fetchArrList[T](ctx, client, endpoint, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch history: %w", err)
+	}
+	return records, nil
+}
+
+// ArrSnapshot is one service's tracked files as of a collection.
+type ArrSnapshot struct {
+	// URL is the service's base URL, so a snapshot of a different instance
+	// configured under the same name is never reused.
+	URL string `json:"url"`
+	// Watermark is when the collection that produced Files started; the
+	// next incremental collection asks for history since then.
+	Watermark time.Time `json:"watermark"`
+	// FullAt is when Files was last rebuilt by a full collection.
+	FullAt time.Time        `json:"full_at"`
+	Files  []models.ArrFile `json:"files"`
+}
+
+// ArrCache stores a snapshot per Arr service between runs so services that
+// support it can be collected incrementally.
+type ArrCache struct {
+	path     string
+	Services map[string]ArrSnapshot `json:"services"`
+}
+
+// NewArrCache returns an empty cache that will be written to path.
+func NewArrCache(path string) *ArrCache {
+	return &ArrCache{path: path, Services: make(map[string]ArrSnapshot)}
+}
+
+// LoadArrCache reads the cache at path. A missing file yields an empty
+// cache.
+func LoadArrCache(path string) (*ArrCache, error) {
+	cache := NewArrCache(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Arr cache: %w", err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse Arr cache %s: %w", path, err)
+	}
+	if cache.Services == nil {
+		cache.Services = make(map[string]ArrSnapshot)
+	}
+	return cache, nil
+}
+
+// Usable returns the snapshot for service when it was taken from url and its
+// last full collection is younger than maxAge. A maxAge of zero or less
+// never forces a full collection.
+func (c *ArrCache) Usable(service, url string, maxAge time.Duration, now time.Time) (ArrSnapshot, bool) {
+	snap, ok := c.Services[service]
+	if !ok || snap.URL != url || snap.Watermark.IsZero() {
+		return ArrSnapshot{}, false
+	}
+	if maxAge > 0 && now.Sub(snap.FullAt) >= maxAge {
+		return ArrSnapshot{}, false
+	}
+	return snap, true
+}
+
+// Put records service's snapshot; Save writes it out.
+func (c *ArrCache) Put(service string, snap ArrSnapshot) {
+	c.Services[service] = snap
+}
+
+// Save writes the cache, replacing the file atomically.
+func (c *ArrCache) Save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write Arr cache: %w", err)
+	}
+	return nil
+}
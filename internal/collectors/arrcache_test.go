@@ -0,0 +1,98 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestSonarrCollector_CollectSince(t *testing.T) {
+	since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var fetched []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/series", func(w http.ResponseWriter, r *http.Request) {
+		// Series 3 was deleted from Sonarr since the cached collection.
+		_ = json.NewEncoder(w).Encode([]sonarrSeries{{ID: 1}, {ID: 2}})
+	})
+	mux.HandleFunc("/api/v3/history/since", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("date"); got != since.Format(time.RFC3339) {
+			t.Errorf("history date = %q, want %q", got, since.Format(time.RFC3339))
+		}
+		_ = json.NewEncoder(w).Encode([]sonarrHistoryRecord{
+			{SeriesID: 2, EventType: "downloadFolderImported"},
+			{SeriesID: 1, EventType: "grabbed"},
+		})
+	})
+	mux.HandleFunc("/api/v3/episodefile", func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Query()["seriesId"]...)
+		_ = json.NewEncoder(w).Encode([]sonarrEpisodeFile{{ID: 21, SeriesID: 2, Path: "/tv/2/new.mkv"}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cached := []models.ArrFile{
+		{Path: "/tv/1/a.mkv", SeriesID: 1, EpisodeID: 11},
+		{Path: "/tv/2/old.mkv", SeriesID: 2, EpisodeID: 20},
+		{Path: "/tv/3/gone.mkv", SeriesID: 3, EpisodeID: 30},
+	}
+	files, err := NewSonarrCollector(srv.URL, "key").CollectSince(context.Background(), since, cached)
+	if err != nil {
+		t.Fatalf("CollectSince: %v", err)
+	}
+
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{"/tv/1/a.mkv", "/tv/2/new.mkv"}; !slices.Equal(paths, want) {
+		t.Errorf("files = %v, want %v", paths, want)
+	}
+	if !slices.Equal(fetched, []string{"2"}) {
+		t.Errorf("refetched series %v, want only 2 (grabs don't change files)", fetched)
+	}
+}
+
+func TestArrCache_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arr-cache.json")
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	cache, err := LoadArrCache(path)
+	if err != nil {
+		t.Fatalf("LoadArrCache(missing): %v", err)
+	}
+	cache.Put("Sonarr", ArrSnapshot{
+		URL:       "http://sonarr:8989",
+		Watermark: now.Add(-time.Hour),
+		FullAt:    now.Add(-2 * time.Hour),
+		Files:     []models.ArrFile{{Path: "/tv/a.mkv", SeriesID: 1}},
+	})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadArrCache(path)
+	if err != nil {
+		t.Fatalf("LoadArrCache: %v", err)
+	}
+	snap, ok := loaded.Usable("Sonarr", "http://sonarr:8989", 24*time.Hour, now)
+	if !ok || len(snap.Files) != 1 || !snap.Watermark.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("Usable = %+v, %t; want the saved snapshot", snap, ok)
+	}
+	if _, ok := loaded.Usable("Sonarr", "http://other:8989", 24*time.Hour, now); ok {
+		t.Error("snapshot from another URL is usable")
+	}
+	if _, ok := loaded.Usable("Sonarr", "http://sonarr:8989", time.Hour, now); ok {
+		t.Error("snapshot older than the full refresh age is usable")
+	}
+	if _, ok := loaded.Usable("Sonarr", "http://sonarr:8989", -1, now); !ok {
+		t.Error("negative full refresh age should never force a full collection")
+	}
+}
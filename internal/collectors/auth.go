@@ -0,0 +1,64 @@
+package collectors
+
+import (
+	"crypto/x509"
+	"net/http"
+
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// authTransport adds proxy credentials to every request, for Arr instances
+// behind a forward-auth proxy (Authelia, Authentik) that wants basic auth or
+// a header in addition to the API key.
+type authTransport struct {
+	base     http.RoundTripper
+	username string
+	password string
+	headers  map[string]string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.username != "" || t.password != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withAuth wraps client's transport with authTransport. It is a no-op when
+// no credentials or headers are configured.
+func withAuth(client *http.Client, username, password string, headers map[string]string) {
+	if username == "" && password == "" && len(headers) == 0 {
+		return
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &authTransport{base: base, username: username, password: password, headers: headers}
+}
+
+// withRootCAs makes client trust pool for TLS, keeping any auth wrapper in
+// place. It is a no-op when pool is nil.
+func withRootCAs(client *http.Client, pool *x509.CertPool) {
+	if pool == nil {
+		return
+	}
+	withTransport(client, utils.TransportWithRootCAs(pool))
+}
+
+// withTransport makes client send requests through transport, keeping any
+// auth wrapper in place. It is a no-op when transport is nil.
+func withTransport(client *http.Client, transport http.RoundTripper) {
+	if transport == nil {
+		return
+	}
+	if at, ok := client.Transport.(*authTransport); ok {
+		at.base = transport
+		return
+	}
+	client.Transport = transport
+}
@@ -0,0 +1,108 @@
+package collectors
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+func TestSetAuth_AppliesToEveryRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "auditarr" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Forward-Auth") != "token" || r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	sc := NewSonarrCollector(srv.URL, "key")
+	if err := sc.TestConnection(context.Background()); err == nil {
+		t.Fatal("expected the proxy to reject a request without credentials")
+	}
+
+	sc.SetAuth("auditarr", "secret", map[string]string{"X-Forward-Auth": "token"})
+	if err := sc.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection: %v", err)
+	}
+	if _, err := sc.FetchRootFolders(context.Background()); err != nil {
+		t.Fatalf("FetchRootFolders: %v", err)
+	}
+}
+
+func TestSetRootCAs_TrustsCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "auditarr" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	sc := NewSonarrCollector(srv.URL, "key")
+	sc.SetAuth("auditarr", "secret", nil)
+	if err := sc.TestConnection(context.Background()); err == nil {
+		t.Fatal("expected a certificate error without the custom CA")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	sc.SetRootCAs(pool)
+	if err := sc.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection with the custom CA: %v", err)
+	}
+}
+
+func TestSetTransport_SharesConnections(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "auditarr" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	shared := utils.NewTransport(utils.TransportOptions{MaxIdleConnsPerHost: 4})
+	for i := 0; i < 2; i++ {
+		sc := NewSonarrCollector(srv.URL, "key")
+		sc.SetAuth("auditarr", "secret", nil)
+		sc.SetTransport(shared)
+		for j := 0; j < 3; j++ {
+			if err := sc.TestConnection(context.Background()); err != nil {
+				t.Fatalf("TestConnection: %v", err)
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Errorf("opened %d connections for sequential requests over a shared transport, want 1", conns)
+	}
+}
@@ -0,0 +1,115 @@
+package collectors
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// decodeBencode parses a single bencoded value. Dictionaries decode to
+// map[string]any, lists to []any, integers to int64 and byte strings to
+// string. Trailing data after the value is an error.
+func decodeBencode(data []byte) (any, error) {
+	d := &bdecoder{data: data}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("bencode: trailing data at offset %d", d.pos)
+	}
+	return v, nil
+}
+
+type bdecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *bdecoder) value() (any, error) {
+	if d.pos >= len(d.data) {
+		return nil, fmt.Errorf("bencode: unexpected end of data")
+	}
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		return d.integer()
+	case c == 'l':
+		d.pos++
+		var list []any
+		for {
+			if d.pos >= len(d.data) {
+				return nil, fmt.Errorf("bencode: unterminated list")
+			}
+			if d.data[d.pos] == 'e' {
+				d.pos++
+				return list, nil
+			}
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+	case c == 'd':
+		d.pos++
+		dict := make(map[string]any)
+		for {
+			if d.pos >= len(d.data) {
+				return nil, fmt.Errorf("bencode: unterminated dictionary")
+			}
+			if d.data[d.pos] == 'e' {
+				d.pos++
+				return dict, nil
+			}
+			key, err := d.str()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = v
+		}
+	case c >= '0' && c <= '9':
+		return d.str()
+	default:
+		return nil, fmt.Errorf("bencode: unexpected byte %q at offset %d", c, d.pos)
+	}
+}
+
+func (d *bdecoder) integer() (int64, error) {
+	start := d.pos + 1
+	end := start
+	for end < len(d.data) && d.data[end] != 'e' {
+		end++
+	}
+	if end >= len(d.data) {
+		return 0, fmt.Errorf("bencode: unterminated integer at offset %d", d.pos)
+	}
+	n, err := strconv.ParseInt(string(d.data[start:end]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bencode: invalid integer at offset %d: %w", d.pos, err)
+	}
+	d.pos = end + 1
+	return n, nil
+}
+
+func (d *bdecoder) str() (string, error) {
+	colon := d.pos
+	for colon < len(d.data) && d.data[colon] != ':' {
+		colon++
+	}
+	if colon >= len(d.data) {
+		return "", fmt.Errorf("bencode: invalid string length at offset %d", d.pos)
+	}
+	n, err := strconv.Atoi(string(d.data[d.pos:colon]))
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("bencode: invalid string length at offset %d", d.pos)
+	}
+	start := colon + 1
+	if start+n > len(d.data) || start+n < start {
+		return "", fmt.Errorf("bencode: string at offset %d overruns data", d.pos)
+	}
+	d.pos = start + n
+	return string(d.data[start:d.pos]), nil
+}
@@ -0,0 +1,82 @@
+package collectors
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of sending a request once a service has
+// failed too many requests in a row.
+var ErrCircuitOpen = errors.New("circuit breaker open after repeated failures, request skipped")
+
+// breakerTransport stops sending requests to a service after threshold
+// consecutive failures (network errors or HTTP 5xx) and fails the rest of the
+// run's requests immediately, so a sick instance costs a few timeouts rather
+// than one per series or movie.
+type breakerTransport struct {
+	base      http.RoundTripper
+	threshold int
+
+	mu       sync.Mutex
+	failures int
+	skipped  int
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if t.failures >= t.threshold {
+		t.skipped++
+		t.mu.Unlock()
+		return nil, ErrCircuitOpen
+	}
+	t.mu.Unlock()
+
+	resp, err := t.base.RoundTrip(req)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// Cancellation is the caller giving up, not the service failing.
+	case err != nil, resp.StatusCode >= 500:
+		t.failures++
+	default:
+		t.failures = 0
+	}
+	return resp, err
+}
+
+// Skipped returns how many requests were refused while the circuit was open.
+func (t *breakerTransport) Skipped() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.skipped
+}
+
+// withCircuitBreaker wraps client's current transport, so it must be applied
+// after withAuth and withRootCAs or withTransport. A threshold of zero or less disables it.
+func withCircuitBreaker(client *http.Client, threshold int) *breakerTransport {
+	if threshold <= 0 {
+		return nil
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	bt := &breakerTransport{base: base, threshold: threshold}
+	client.Transport = bt
+	return bt
+}
+
+// withRequestTimeout bounds every request made by client. Zero or negative
+// keeps the client's default.
+func withRequestTimeout(client *http.Client, timeout time.Duration) {
+	if timeout > 0 {
+		client.Timeout = timeout
+	}
+}
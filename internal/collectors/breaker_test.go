@@ -0,0 +1,67 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSonarrCollector_CircuitBreakerSkipsAfterFailures(t *testing.T) {
+	var hits int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/series", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]sonarrSeries{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}})
+	})
+	mux.HandleFunc("/api/v3/episodefile", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Error(w, "database is locked", http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	sc := NewSonarrCollector(srv.URL, "key")
+	sc.SetCircuitBreaker(httpRetryAttempts)
+	if _, err := sc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	// The bulk request's retries open the circuit; every per-series fetch
+	// after that is refused without reaching the server.
+	if hits != httpRetryAttempts {
+		t.Errorf("server saw %d episodefile requests, want %d", hits, httpRetryAttempts)
+	}
+	if got := sc.SkippedRequests(); got != 4 {
+		t.Errorf("SkippedRequests = %d, want 4", got)
+	}
+}
+
+func TestBreakerTransport_SuccessResetsFailures(t *testing.T) {
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{}
+	bt := withCircuitBreaker(client, 2)
+	get := func() {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request refused: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	fail = false
+	get()
+	fail = true
+	get()
+	if bt.Skipped() != 0 {
+		t.Errorf("circuit opened although failures were not consecutive")
+	}
+}
@@ -0,0 +1,112 @@
+package collectors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// checkpointInterval is the minimum time between checkpoint writes, so a
+// library with thousands of small top-level directories isn't rewriting the
+// file after every one.
+const checkpointInterval = 10 * time.Second
+
+// Checkpoint records the files collected from each fully walked top-level
+// directory of a scan root. A resumed scan reuses those results and skips the
+// directories, so a scan that keeps getting killed still makes progress.
+type Checkpoint struct {
+	path      string
+	Completed map[string][]models.MediaFile `json:"completed"`
+	// Permissions holds each completed directory's permission records when
+	// the walk was collecting them.
+	Permissions map[string][]models.FilePermissions `json:"permissions,omitempty"`
+	lastWrite   time.Time
+	dirty       bool
+}
+
+// NewCheckpoint returns an empty checkpoint that will be written to path.
+func NewCheckpoint(path string) *Checkpoint {
+	return &Checkpoint{
+		path:        path,
+		Completed:   make(map[string][]models.MediaFile),
+		Permissions: make(map[string][]models.FilePermissions),
+	}
+}
+
+// LoadCheckpoint reads the checkpoint at path to resume from. A missing file
+// yields an empty checkpoint.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	cp := NewCheckpoint(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if cp.Completed == nil {
+		cp.Completed = make(map[string][]models.MediaFile)
+	}
+	if cp.Permissions == nil {
+		cp.Permissions = make(map[string][]models.FilePermissions)
+	}
+	return cp, nil
+}
+
+// Len returns how many directories are recorded as complete.
+func (cp *Checkpoint) Len() int {
+	return len(cp.Completed)
+}
+
+func (cp *Checkpoint) done(dir string) ([]models.MediaFile, []models.FilePermissions, bool) {
+	files, ok := cp.Completed[dir]
+	return files, cp.Permissions[dir], ok
+}
+
+// record marks dir as fully walked and writes the checkpoint if the last
+// write was long enough ago.
+func (cp *Checkpoint) record(dir string, files []models.MediaFile, perms []models.FilePermissions) error {
+	cp.Completed[dir] = append([]models.MediaFile(nil), files...)
+	if len(perms) > 0 {
+		cp.Permissions[dir] = append([]models.FilePermissions(nil), perms...)
+	}
+	cp.dirty = true
+	if time.Since(cp.lastWrite) < checkpointInterval {
+		return nil
+	}
+	return cp.Flush()
+}
+
+// Flush writes any unsaved progress. The file is replaced atomically so an
+// interrupted write never leaves a truncated checkpoint behind.
+func (cp *Checkpoint) Flush() error {
+	if !cp.dirty {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(cp.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	cp.lastWrite = time.Now()
+	cp.dirty = false
+	return nil
+}
+
+// Remove deletes the checkpoint file once a scan has completed.
+func (cp *Checkpoint) Remove() error {
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
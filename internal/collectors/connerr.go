@@ -0,0 +1,77 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// ConnectionErrorKind categorizes why a service could not be reached, so
+// reports can tell a wrong API key apart from a host that is down.
+type ConnectionErrorKind string
+
+const (
+	ConnUnreachable ConnectionErrorKind = "unreachable"
+	ConnAuth        ConnectionErrorKind = "auth"
+	ConnHTTPStatus  ConnectionErrorKind = "http_status"
+)
+
+type ConnectionError struct {
+	Kind ConnectionErrorKind
+	Err  error
+}
+
+func (e *ConnectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// unreachableError wraps a transport-level failure from http.Client.Do,
+// naming the most likely cause.
+func unreachableError(err error) error {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	cause := "connection failed"
+	switch {
+	case errors.As(err, &dnsErr):
+		cause = "DNS lookup failed"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		cause = "connection refused"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		cause = "timed out"
+	}
+	return &ConnectionError{Kind: ConnUnreachable, Err: fmt.Errorf("%s: %w", cause, err)}
+}
+
+// statusError categorizes a non-success HTTP response.
+func statusError(code int, authMsg string) error {
+	if code == http.StatusUnauthorized || code == http.StatusForbidden {
+		return &ConnectionError{Kind: ConnAuth, Err: errors.New(authMsg)}
+	}
+	return &ConnectionError{Kind: ConnHTTPStatus, Err: fmt.Errorf("API returned status %d", code)}
+}
+
+// FailureReason returns a short, human-readable category for a connection
+// error, e.g. "Auth failed" or "Unreachable".
+func FailureReason(err error) string {
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		return "Failed"
+	}
+	switch connErr.Kind {
+	case ConnUnreachable:
+		return "Unreachable"
+	case ConnAuth:
+		return "Auth failed"
+	case ConnHTTPStatus:
+		return "HTTP error"
+	default:
+		return "Failed"
+	}
+}
@@ -0,0 +1,43 @@
+package collectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTestConnection_FailureReasons(t *testing.T) {
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer authSrv.Close()
+
+	errSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer errSrv.Close()
+
+	// A closed server leaves a port nothing is listening on.
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	cases := []struct {
+		url  string
+		want string
+	}{
+		{authSrv.URL, "Auth failed"},
+		{errSrv.URL, "HTTP error"},
+		{downURL, "Unreachable"},
+	}
+	for _, c := range cases {
+		err := NewSonarrCollector(c.url, "key").TestConnection(context.Background())
+		if err == nil {
+			t.Fatalf("%s: expected an error", c.url)
+		}
+		if got := FailureReason(err); got != c.want {
+			t.Errorf("%s: FailureReason = %q, want %q (err: %v)", c.url, got, c.want, err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// FastresumeCollector reads torrents from qBittorrent's BT_backup directory
+// (<hash>.fastresume plus <hash>.torrent) instead of the WebUI, so an audit
+// can run while qBittorrent is down or unreachable. It produces the same
+// []models.Torrent as QBCollector.
+type FastresumeCollector struct {
+	dir string
+}
+
+func NewFastresumeCollector(dir string) *FastresumeCollector {
+	return &FastresumeCollector{dir: dir}
+}
+
+func (fc *FastresumeCollector) Name() string {
+	return "qbittorrent-backup"
+}
+
+func (fc *FastresumeCollector) TestConnection(ctx context.Context) error {
+	info, err := os.Stat(fc.dir)
+	if err != nil {
+		return fmt.Errorf("backup directory unreadable: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("backup directory %s is not a directory", fc.dir)
+	}
+	return nil
+}
+
+func (fc *FastresumeCollector) Collect(ctx context.Context) ([]models.Torrent, error) {
+	if fc.dir == "" {
+		return nil, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(fc.dir, "*.fastresume"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fastresume files: %w", err)
+	}
+	if matches == nil {
+		if _, err := os.Stat(fc.dir); err != nil {
+			return nil, fmt.Errorf("failed to read backup directory: %w", err)
+		}
+	}
+
+	var result []models.Torrent
+	for _, m := range matches {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		t, err := readFastresume(m)
+		if err != nil {
+			fmt.Printf("Warning: failed to read %s: %v\n", m, err)
+			continue
+		}
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// readFastresume builds a torrent from a .fastresume file and, when present,
+// the sibling .torrent file that carries the name, file list and privacy flag.
+func readFastresume(resumePath string) (models.Torrent, error) {
+	data, err := os.ReadFile(resumePath)
+	if err != nil {
+		return models.Torrent{}, err
+	}
+	v, err := decodeBencode(data)
+	if err != nil {
+		return models.Torrent{}, err
+	}
+	resume, ok := v.(map[string]any)
+	if !ok {
+		return models.Torrent{}, fmt.Errorf("not a bencoded dictionary")
+	}
+
+	hash := strings.TrimSuffix(filepath.Base(resumePath), ".fastresume")
+	t := models.Torrent{
+		Hash:     strings.ToLower(hash),
+		SavePath: bstring(resume, "qBt-savePath"),
+		Name:     bstring(resume, "qBt-name"),
+		Category: bstring(resume, "qBt-category"),
+	}
+	if t.SavePath == "" {
+		t.SavePath = bstring(resume, "save_path")
+	}
+
+	completed := bint(resume, "completed_time")
+	if completed > 0 {
+		t.CompletedOn = time.Unix(completed, 0)
+	}
+	t.State = fastresumeState(resume)
+
+	if trackers, ok := resume["trackers"].([]any); ok {
+		for _, tier := range trackers {
+			urls, _ := tier.([]any)
+			for _, u := range urls {
+				if s, ok := u.(string); ok {
+					t.Trackers = append(t.Trackers, s)
+				}
+			}
+		}
+	}
+
+	// libtorrent 2.x embeds the info dictionary in the resume data; older
+	// versions only keep it in the .torrent file.
+	info, _ := resume["info"].(map[string]any)
+	if info == nil {
+		if tdata, err := os.ReadFile(strings.TrimSuffix(resumePath, ".fastresume") + ".torrent"); err == nil {
+			if tv, err := decodeBencode(tdata); err == nil {
+				if meta, ok := tv.(map[string]any); ok {
+					info, _ = meta["info"].(map[string]any)
+					if len(t.Trackers) == 0 {
+						t.Trackers = metainfoTrackers(meta)
+					}
+				}
+			}
+		}
+	}
+	if info != nil {
+		if t.Name == "" {
+			t.Name = bstring(info, "name")
+		}
+		t.IsPrivate = bint(info, "private") == 1
+		t.Files, t.Size = infoFiles(info)
+	}
+	if t.Name == "" {
+		t.Name = bstring(resume, "name")
+	}
+
+	return t, nil
+}
+
+// fastresumeState maps resume data onto a torrent state. Resume files don't
+// record qBittorrent's live state, so completion is taken from the piece
+// bitfield (or completed_time) and a paused flag overrides downloading.
+func fastresumeState(resume map[string]any) models.TorrentState {
+	complete := bint(resume, "completed_time") > 0 || bint(resume, "seed_mode") == 1
+	if pieces := bstring(resume, "pieces"); !complete && pieces != "" {
+		complete = true
+		for i := 0; i < len(pieces); i++ {
+			if pieces[i]&1 == 0 {
+				complete = false
+				break
+			}
+		}
+	}
+
+	switch {
+	case complete:
+		return models.StateCompleted
+	case bint(resume, "paused") == 1:
+		return models.StatePaused
+	default:
+		return models.StateDownloading
+	}
+}
+
+// infoFiles lists file paths the way the WebUI reports them: the bare name
+// for single-file torrents, and name/sub/path for multi-file ones.
+func infoFiles(info map[string]any) ([]string, int64) {
+	name := bstring(info, "name")
+	files, ok := info["files"].([]any)
+	if !ok {
+		return []string{name}, bint(info, "length")
+	}
+
+	var paths []string
+	var size int64
+	for _, f := range files {
+		entry, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+		// BEP 47 padding files are never written to disk.
+		if strings.Contains(bstring(entry, "attr"), "p") {
+			continue
+		}
+		parts := []string{name}
+		segments, _ := entry["path"].([]any)
+		for _, s := range segments {
+			if str, ok := s.(string); ok {
+				parts = append(parts, str)
+			}
+		}
+		paths = append(paths, path.Join(parts...))
+		size += bint(entry, "length")
+	}
+	return paths, size
+}
+
+func metainfoTrackers(meta map[string]any) []string {
+	var urls []string
+	if tiers, ok := meta["announce-list"].([]any); ok {
+		for _, tier := range tiers {
+			list, _ := tier.([]any)
+			for _, u := range list {
+				if s, ok := u.(string); ok {
+					urls = append(urls, s)
+				}
+			}
+		}
+	}
+	if len(urls) == 0 {
+		if s := bstring(meta, "announce"); s != "" {
+			urls = append(urls, s)
+		}
+	}
+	return urls
+}
+
+func bstring(d map[string]any, key string) string {
+	s, _ := d[key].(string)
+	return s
+}
+
+func bint(d map[string]any, key string) int64 {
+	n, _ := d[key].(int64)
+	return n
+}
@@ -0,0 +1,125 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// bencode is a minimal encoder for building test fixtures.
+func bencode(v any) string {
+	switch x := v.(type) {
+	case int:
+		return fmt.Sprintf("i%de", x)
+	case string:
+		return fmt.Sprintf("%d:%s", len(x), x)
+	case []any:
+		var b strings.Builder
+		b.WriteString("l")
+		for _, e := range x {
+			b.WriteString(bencode(e))
+		}
+		b.WriteString("e")
+		return b.String()
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("d")
+		for _, k := range keys {
+			b.WriteString(bencode(k) + bencode(x[k]))
+		}
+		b.WriteString("e")
+		return b.String()
+	}
+	panic(fmt.Sprintf("unsupported type %T", v))
+}
+
+func TestFastresumeCollector_Collect(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, v any) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(bencode(v)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Completed multi-file torrent whose info lives in the .torrent file.
+	write("aaa.fastresume", map[string]any{
+		"qBt-savePath":   "/data/torrents/tv",
+		"qBt-category":   "tv-sonarr",
+		"save_path":      "/ignored",
+		"completed_time": 1700000000,
+		"pieces":         "\x01\x01",
+	})
+	write("aaa.torrent", map[string]any{
+		"announce": "https://tracker.example/announce",
+		"info": map[string]any{
+			"name":    "Show.S01",
+			"private": 1,
+			"files": []any{
+				map[string]any{"length": 100, "path": []any{"e01.mkv"}},
+				map[string]any{"length": 5, "path": []any{".pad", "5"}, "attr": "p"},
+				map[string]any{"length": 200, "path": []any{"Subs", "e01.srt"}},
+			},
+		},
+	})
+	// Paused, partially downloaded single-file torrent with embedded info.
+	write("bbb.fastresume", map[string]any{
+		"save_path": "/data/torrents/movies",
+		"paused":    1,
+		"pieces":    "\x01\x00",
+		"trackers":  []any{[]any{"udp://open.example:1337"}},
+		"info":      map[string]any{"name": "Movie.mkv", "length": 4096},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "broken.fastresume"), []byte("d3:foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	torrents, err := NewFastresumeCollector(dir).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(torrents) != 2 {
+		t.Fatalf("got %d torrents, want 2 (broken file skipped): %+v", len(torrents), torrents)
+	}
+
+	a, b := torrents[0], torrents[1]
+	if a.Hash != "aaa" || a.Name != "Show.S01" || a.SavePath != "/data/torrents/tv" || a.Category != "tv-sonarr" {
+		t.Errorf("unexpected torrent a: %+v", a)
+	}
+	if a.State != models.StateCompleted || a.CompletedOn.Unix() != 1700000000 || !a.IsPrivate {
+		t.Errorf("torrent a state = %s, completed %v, private %t", a.State, a.CompletedOn, a.IsPrivate)
+	}
+	if want := []string{"Show.S01/e01.mkv", "Show.S01/Subs/e01.srt"}; !reflect.DeepEqual(a.Files, want) || a.Size != 300 {
+		t.Errorf("torrent a files = %v (size %d), want %v (size 300)", a.Files, a.Size, want)
+	}
+	if !reflect.DeepEqual(a.Trackers, []string{"https://tracker.example/announce"}) {
+		t.Errorf("torrent a trackers = %v", a.Trackers)
+	}
+
+	if b.State != models.StatePaused || !b.CompletedOn.IsZero() {
+		t.Errorf("torrent b state = %s, completed %v; want paused, never completed", b.State, b.CompletedOn)
+	}
+	if !reflect.DeepEqual(b.Files, []string{"Movie.mkv"}) || b.Size != 4096 || b.SavePath != "/data/torrents/movies" {
+		t.Errorf("unexpected torrent b: %+v", b)
+	}
+	if !reflect.DeepEqual(b.Trackers, []string{"udp://open.example:1337"}) {
+		t.Errorf("torrent b trackers = %v", b.Trackers)
+	}
+}
+
+func TestFastresumeCollector_MissingDir(t *testing.T) {
+	if _, err := NewFastresumeCollector(filepath.Join(t.TempDir(), "nope")).Collect(context.Background()); err == nil {
+		t.Fatal("expected an error for a missing backup directory")
+	}
+}
@@ -0,0 +1,139 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// MediaVerifier probes media containers with ffprobe to find files that are
+// corrupt or truncated. It is expensive, so it only runs when explicitly
+// requested and probes at most sampleSize files with bounded concurrency.
+type MediaVerifier struct {
+	ffprobePath string
+	sampleSize  int
+	concurrency int
+	timeout     time.Duration
+}
+
+func NewMediaVerifier(sampleSize, concurrency int, timeout time.Duration) (*MediaVerifier, error) {
+	path, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe not found on PATH: %w", err)
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &MediaVerifier{
+		ffprobePath: path,
+		sampleSize:  sampleSize,
+		concurrency: concurrency,
+		timeout:     timeout,
+	}, nil
+}
+
+func (mv *MediaVerifier) Name() string {
+	return "ffprobe"
+}
+
+// Verify probes library video files and returns the ones ffprobe could not
+// read, along with how many files were probed. A sampleSize of zero or less
+// probes every candidate. When ctx is cancelled, probes that were cut short
+// are not counted.
+func (mv *MediaVerifier) Verify(ctx context.Context, files []models.MediaFile) ([]models.CorruptFile, int) {
+	var candidates []models.MediaFile
+	for _, f := range files {
+		if f.Source == models.MediaSourceLibrary && !f.IsHidden && utils.IsMediaFile(f.Path) {
+			candidates = append(candidates, f)
+		}
+	}
+
+	if mv.sampleSize > 0 && len(candidates) > mv.sampleSize {
+		rand.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		candidates = candidates[:mv.sampleSize]
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		corrupt []models.CorruptFile
+		probed  int
+	)
+	sem := make(chan struct{}, mv.concurrency)
+
+dispatch:
+	for _, f := range candidates {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(f models.MediaFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := mv.probe(ctx, f.Path)
+			if ctx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			probed++
+			if err != nil {
+				corrupt = append(corrupt, models.CorruptFile{
+					Path:   f.Path,
+					Size:   f.Size,
+					Code:   models.ReasonProbeFailed,
+					Reason: err.Error(),
+				})
+			}
+		}(f)
+	}
+
+	wg.Wait()
+	sort.Slice(corrupt, func(i, j int) bool {
+		return corrupt[i].Path < corrupt[j].Path
+	})
+	return corrupt, probed
+}
+
+func (mv *MediaVerifier) probe(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, mv.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, mv.ffprobePath, "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1", path)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("ffprobe timed out after %s", mv.timeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			msg = msg[:i]
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package collectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// fakeFFprobe writes a stand-in for ffprobe that fails for paths containing
+// "bad", hangs for paths containing "slow" and succeeds otherwise.
+func fakeFFprobe(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	script := `#!/bin/sh
+for last; do :; done
+case "$last" in
+*bad*) echo "moov atom not found" >&2; echo "second line" >&2; exit 1 ;;
+*slow*) exec sleep 10 ;;
+esac
+exit 0
+`
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func libraryFiles(names ...string) []models.MediaFile {
+	files := make([]models.MediaFile, len(names))
+	for i, name := range names {
+		files[i] = models.MediaFile{Path: "/media/" + name, Size: 100, Source: models.MediaSourceLibrary}
+	}
+	return files
+}
+
+func TestMediaVerifier_Verify(t *testing.T) {
+	mv := &MediaVerifier{ffprobePath: fakeFFprobe(t), concurrency: 2, timeout: 200 * time.Millisecond}
+	files := libraryFiles("good.mkv", "slow.mkv", "bad.mkv", "notes.txt")
+
+	corrupt, probed := mv.Verify(context.Background(), files)
+	if probed != 3 {
+		t.Errorf("probed = %d, want 3 (the .txt is not a candidate)", probed)
+	}
+	if len(corrupt) != 2 {
+		t.Fatalf("corrupt = %+v, want bad.mkv and slow.mkv", corrupt)
+	}
+	if corrupt[0].Path != "/media/bad.mkv" || corrupt[0].Reason != "moov atom not found" || corrupt[0].Code != models.ReasonProbeFailed {
+		t.Errorf("failed probe = %+v, want the first stderr line", corrupt[0])
+	}
+	if corrupt[1].Path != "/media/slow.mkv" || !strings.Contains(corrupt[1].Reason, "timed out") {
+		t.Errorf("hung probe = %+v, want a timeout", corrupt[1])
+	}
+}
+
+func TestMediaVerifier_VerifyCancelled(t *testing.T) {
+	mv := &MediaVerifier{ffprobePath: fakeFFprobe(t), concurrency: 1, timeout: time.Minute}
+	files := libraryFiles("good.mkv", "slow-1.mkv", "slow-2.mkv")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+
+	start := time.Now()
+	corrupt, probed := mv.Verify(ctx, files)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Verify took %s after cancellation", elapsed)
+	}
+	if probed != 1 {
+		t.Errorf("probed = %d, want 1 (only good.mkv finished)", probed)
+	}
+	if len(corrupt) != 0 {
+		t.Errorf("cancelled probes were reported as corrupt: %+v", corrupt)
+	}
+}
@@ -2,14 +2,19 @@ package collectors
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/jdpx/auditarr/internal/analysis"
 	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
 )
 
 type Collector interface {
@@ -21,8 +26,37 @@ type FilesystemCollector struct {
 	mediaRoot      string
 	torrentRoot    string
 	extraScanPaths []string
+	excludePaths   []string
+	followSymlinks bool
+	progress       *utils.Progress
+	heartbeat      func()
+	minFileAge     time.Duration
+	inFlight       int
+	maxDepth       int
+	checkpoint     *Checkpoint
+	collectPerms   bool
+	permSkipPaths  []string
+	permissions    []models.FilePermissions
+	statFailures   int
+	hardlinkWarn   string
+	snapshots      map[string]string
+	ignoreMarkers  []string
+	walkRetries    int
+	verbose        bool
 }
 
+// maxStatWarnings caps the per-file stat warnings one Collect prints; the
+// rest are only counted.
+const maxStatWarnings = 5
+
+// walkRetryDelay is the base of the linear backoff between re-reads of a
+// directory that failed with a transient error.
+var walkRetryDelay = 500 * time.Millisecond
+
+// minHardlinkSample is how many library and torrent files a scan needs before
+// a total absence of hardlinks is treated as a sign of unreliable link counts.
+const minHardlinkSample = 20
+
 func NewFilesystemCollector(mediaRoot, torrentRoot string, extraScanPaths []string) *FilesystemCollector {
 	return &FilesystemCollector{
 		mediaRoot:      mediaRoot,
@@ -31,12 +65,203 @@ func NewFilesystemCollector(mediaRoot, torrentRoot string, extraScanPaths []stri
 	}
 }
 
+// SetSnapshotRoot walks snapshot in place of the live root, e.g. a read-only
+// ZFS or btrfs snapshot of it, for a consistent view of a library that is
+// still being imported into. Files, permission records and exclusions use
+// the corresponding paths under live.
+func (fc *FilesystemCollector) SetSnapshotRoot(live, snapshot string) {
+	if fc.snapshots == nil {
+		fc.snapshots = make(map[string]string)
+	}
+	fc.snapshots[live] = snapshot
+}
+
+// SetExcludePaths configures directories that are not walked at all.
+func (fc *FilesystemCollector) SetExcludePaths(paths []string) {
+	fc.excludePaths = paths
+}
+
+// SetFollowSymlinks makes the walk descend into symlinked directories. Each
+// directory is visited at most once (by device and inode), which prevents
+// loops and avoids counting the same files twice.
+func (fc *FilesystemCollector) SetFollowSymlinks(follow bool) {
+	fc.followSymlinks = follow
+}
+
+// SetIgnoreMarkers skips any directory that directly contains a file with
+// one of names, such as .nomedia, so folders can be excluded in place
+// without touching the config.
+func (fc *FilesystemCollector) SetIgnoreMarkers(names []string) {
+	fc.ignoreMarkers = names
+}
+
+// hasIgnoreMarker reports whether dir contains one of the ignore markers.
+func (fc *FilesystemCollector) hasIgnoreMarker(dir string) bool {
+	for _, name := range fc.ignoreMarkers {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SetWalkRetries re-reads a directory up to n times when reading it fails
+// with a transient error, such as a stale NFS handle, instead of failing the
+// whole walk. Zero or negative fails on the first error.
+func (fc *FilesystemCollector) SetWalkRetries(n int) {
+	fc.walkRetries = n
+}
+
+// SetVerbose prints each walk retry.
+func (fc *FilesystemCollector) SetVerbose(verbose bool) {
+	fc.verbose = verbose
+}
+
+// SetProgress counts collected files against p while walking.
+func (fc *FilesystemCollector) SetProgress(p *utils.Progress) {
+	fc.progress = p
+}
+
+// SetHeartbeat calls beat for every file collected, e.g. to show a watchdog
+// that the walk is still making progress.
+func (fc *FilesystemCollector) SetHeartbeat(beat func()) {
+	fc.heartbeat = beat
+}
+
+// counted records n more collected files.
+func (fc *FilesystemCollector) counted(n int) {
+	fc.progress.Add(n)
+	if fc.heartbeat != nil {
+		fc.heartbeat()
+	}
+}
+
+// SetMinFileAge skips files modified less than age ago, which are likely
+// still being written (partial imports) and would report a wrong size.
+func (fc *FilesystemCollector) SetMinFileAge(age time.Duration) {
+	fc.minFileAge = age
+}
+
+// SetMaxDepth stops the walk more than depth levels below each root, like
+// find -maxdepth: 1 collects only files directly in the root. Zero means no
+// limit.
+func (fc *FilesystemCollector) SetMaxDepth(depth int) {
+	fc.maxDepth = depth
+}
+
+// SetCheckpoint records each fully walked top-level directory in cp and
+// reuses the files of directories cp already holds instead of walking them.
+func (fc *FilesystemCollector) SetCheckpoint(cp *Checkpoint) {
+	fc.checkpoint = cp
+}
+
+// SetCollectPermissions records the ownership and mode of every directory and
+// file under the media and torrent roots that the walk visits, reusing the
+// walk's stat instead of a second pass over the tree. Paths under skipPaths
+// are not recorded.
+func (fc *FilesystemCollector) SetCollectPermissions(skipPaths []string) {
+	fc.collectPerms = true
+	fc.permSkipPaths = skipPaths
+}
+
+// Permissions returns the records gathered by the last Collect when
+// SetCollectPermissions is on.
+func (fc *FilesystemCollector) Permissions() []models.FilePermissions {
+	return fc.permissions
+}
+
+func (fc *FilesystemCollector) recordPermissions(path string, isDir bool, stats fileStats, source models.MediaFileSource) {
+	if !fc.collectPerms || source == models.MediaSourceExtra || utils.ShouldSkipPath(path, fc.permSkipPaths) {
+		return
+	}
+	fc.permissions = append(fc.permissions, models.FilePermissions{
+		Path:        filepath.Clean(path),
+		Mode:        stats.mode,
+		OwnerUID:    stats.uid,
+		GroupGID:    stats.gid,
+		IsDirectory: isDir,
+	})
+}
+
+// pathDepth returns how many levels below root path is.
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// InFlightSkipped returns how many files the last Collect skipped because
+// they were modified within the minimum file age.
+func (fc *FilesystemCollector) InFlightSkipped() int {
+	return fc.inFlight
+}
+
+// HardlinkWarning returns a run-level warning when the last Collect found no
+// hardlinks at all or could not stat any file, or "" when link counts look
+// usable.
+func (fc *FilesystemCollector) HardlinkWarning() string {
+	return fc.hardlinkWarn
+}
+
+// hardlinkDetectionWarning checks the library and torrent files of a scan for
+// a pattern that only an unreliable link count produces: every file either
+// failed stat or reports a single link.
+func hardlinkDetectionWarning(files []models.MediaFile, statFailures int) string {
+	var sampled int
+	for _, f := range files {
+		if f.Source == models.MediaSourceExtra || f.IsSidecar {
+			continue
+		}
+		if f.HardlinkCount > 1 {
+			return ""
+		}
+		sampled++
+	}
+	if sampled < minHardlinkSample {
+		return ""
+	}
+	if statFailures >= sampled {
+		return fmt.Sprintf("Could not stat any of the %d scanned files, so hardlink counts are unknown and every file tracked by Arr is reported at risk. Hardlink detection is unreliable on this mount; check that auditarr can stat files under media_root and torrent_root.", sampled)
+	}
+	return fmt.Sprintf("None of the %d scanned files has more than one hardlink. Either nothing is hardlinked to a torrent, or this filesystem (some FUSE or network mounts) always reports a link count of 1; in that case hardlink detection is unreliable on this mount and the at-risk results are not meaningful.", sampled)
+}
+
+func (fc *FilesystemCollector) isExcluded(path string) bool {
+	for _, excluded := range fc.excludePaths {
+		if utils.IsUnderPath(path, excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedRoots returns the other configured roots strictly inside root. Each
+// is walked on its own, so the walk of root skips them and every file gets
+// the source of the most specific root containing it: with torrent_root under
+// media_root, downloads are torrent files (orphaned downloads when unmatched)
+// rather than library orphans, and aren't collected twice.
+func (fc *FilesystemCollector) nestedRoots(root string) []string {
+	var nested []string
+	for _, other := range append([]string{fc.mediaRoot, fc.torrentRoot}, fc.extraScanPaths...) {
+		if other != "" && filepath.Clean(other) != filepath.Clean(root) && utils.IsUnderPath(other, root) {
+			nested = append(nested, filepath.Clean(other))
+		}
+	}
+	return nested
+}
+
 func (fc *FilesystemCollector) Name() string {
 	return "filesystem"
 }
 
 func (fc *FilesystemCollector) Collect(ctx context.Context) ([]models.MediaFile, error) {
 	var allFiles []models.MediaFile
+	fc.inFlight = 0
+	fc.permissions = nil
+	fc.statFailures = 0
+	fc.hardlinkWarn = ""
 
 	if fc.mediaRoot != "" {
 		mediaFiles, err := fc.collectFromPath(ctx, fc.mediaRoot, models.MediaSourceLibrary)
@@ -65,22 +290,194 @@ func (fc *FilesystemCollector) Collect(ctx context.Context) ([]models.MediaFile,
 		}
 	}
 
+	if fc.statFailures > maxStatWarnings {
+		fmt.Fprintf(os.Stderr, "Warning: failed to get file stats for %d files (first %d shown)\n", fc.statFailures, maxStatWarnings)
+	}
+	fc.hardlinkWarn = hardlinkDetectionWarning(allFiles, fc.statFailures)
+
 	return allFiles, nil
 }
 
+// skipHiddenFile reports whether the walk ignores a file: for library and
+// torrent sources, hidden files other than .parts fragments. Extra scan paths
+// collect everything.
+func skipHiddenFile(path string, source models.MediaFileSource) bool {
+	isHidden := strings.HasPrefix(filepath.Base(path), ".")
+	return source != models.MediaSourceExtra && isHidden && models.Ext(path) != ".parts"
+}
+
+func newMediaFile(path string, info fs.FileInfo, source models.MediaFileSource) models.MediaFile {
+	stats, err := getFileStats(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to get file stats for %s: %v\n", path, err)
+		stats = fallbackStats(info)
+	}
+	return mediaFileWithStats(path, source, stats)
+}
+
+// fallbackStats fills in what info provides when the file can't be stat'ed
+// directly, assuming a single link.
+func fallbackStats(info fs.FileInfo) fileStats {
+	return fileStats{size: info.Size(), modTime: info.ModTime(), hardlinks: 1, blockSize: info.Size()}
+}
+
+func mediaFileWithStats(path string, source models.MediaFileSource, stats fileStats) models.MediaFile {
+	isHidden := strings.HasPrefix(filepath.Base(path), ".")
+
+	// Metadata files (but not for extra scan paths or hidden files) are
+	// kept only as sidecars, to detect ones whose video is gone.
+	isSidecar := !isHidden && source != models.MediaSourceExtra && analysis.IsMetadataFile(path)
+
+	return models.MediaFile{
+		Path:          path,
+		Size:          stats.size,
+		BlockSize:     stats.blockSize,
+		ModTime:       stats.modTime,
+		HardlinkCount: stats.hardlinks,
+		IsHardlinked:  stats.hardlinks > 1,
+		IsHidden:      isHidden,
+		Source:        source,
+		Device:        stats.device,
+		Inode:         stats.inode,
+		IsSidecar:     isSidecar,
+	}
+}
+
+// CollectFile collects a single file as the walk would, with its source
+// taken from whichever configured root contains it.
+func (fc *FilesystemCollector) CollectFile(path string) (models.MediaFile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return models.MediaFile{}, err
+	}
+
+	var source models.MediaFileSource
+	switch {
+	case fc.torrentRoot != "" && utils.IsUnderPath(path, fc.torrentRoot):
+		source = models.MediaSourceTorrent
+	case fc.mediaRoot != "" && utils.IsUnderPath(path, fc.mediaRoot):
+		source = models.MediaSourceLibrary
+	default:
+		for _, extra := range fc.extraScanPaths {
+			if utils.IsUnderPath(path, extra) {
+				source = models.MediaSourceExtra
+			}
+		}
+	}
+	if source == "" {
+		return models.MediaFile{}, fmt.Errorf("%s is not under media_root, torrent_root or extra_scan_paths", path)
+	}
+	if fc.isExcluded(path) {
+		return models.MediaFile{}, fmt.Errorf("%s is under an excluded Arr root folder", path)
+	}
+	if skipHiddenFile(path, source) {
+		return models.MediaFile{}, fmt.Errorf("%s is a hidden file, which the scan does not collect", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return models.MediaFile{}, err
+	}
+	if info.IsDir() {
+		return models.MediaFile{}, fmt.Errorf("%s is a directory", path)
+	}
+	return newMediaFile(path, info, source), nil
+}
+
+// isTransientFSError reports whether err is one a network mount returns
+// during a momentary hiccup, so the read is worth retrying.
+func isTransientFSError(err error) bool {
+	for _, errno := range []syscall.Errno{syscall.EINTR, syscall.EAGAIN, syscall.ESTALE, syscall.ETIMEDOUT, syscall.EIO} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDir re-reads dir, whose read failed with a transient error, with a
+// linear backoff and walks its entries with visit once a read succeeds. It
+// returns SkipDir so WalkDir drops the partial listing it already has.
+func (fc *FilesystemCollector) retryDir(ctx context.Context, dir string, err error, visit fs.WalkDirFunc) error {
+	for attempt := 1; attempt <= fc.walkRetries; attempt++ {
+		if fc.verbose {
+			fmt.Fprintf(os.Stderr, "Retrying %s after transient error (%d/%d): %v\n", dir, attempt, fc.walkRetries, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * walkRetryDelay):
+		}
+
+		var entries []os.DirEntry
+		entries, err = os.ReadDir(dir)
+		if err == nil {
+			for _, entry := range entries {
+				if err := filepath.WalkDir(filepath.Join(dir, entry.Name()), visit); err != nil {
+					return err
+				}
+			}
+			return filepath.SkipDir
+		}
+		if !isTransientFSError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: giving up after %d retries: %w", dir, fc.walkRetries, err)
+}
+
 func (fc *FilesystemCollector) collectFromPath(ctx context.Context, root string, source models.MediaFileSource) ([]models.MediaFile, error) {
 	var files []models.MediaFile
 
-	if _, err := os.Stat(root); os.IsNotExist(err) {
-		return files, fmt.Errorf("root does not exist: %s", root)
+	// The walk reads walkRoot; live maps what it finds back under root.
+	walkRoot := root
+	if snapshot, ok := fc.snapshots[root]; ok {
+		walkRoot = snapshot
+	}
+	live := func(path string) string {
+		if walkRoot == root {
+			return path
+		}
+		rel, err := filepath.Rel(walkRoot, path)
+		if err != nil {
+			return path
+		}
+		return filepath.Join(root, rel)
+	}
+
+	if _, err := os.Stat(walkRoot); os.IsNotExist(err) {
+		return files, fmt.Errorf("root does not exist: %s", walkRoot)
 	}
 
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+	visited := make(map[dirKey]struct{})
+	cutoff := time.Now().Add(-fc.minFileAge)
+	nested := fc.nestedRoots(root)
+
+	// top is the top-level directory being walked and topStart the index of
+	// its first file; WalkDir is lexical, so reaching the next top-level
+	// entry means the previous directory is complete.
+	var top string
+	var topStart, topPermStart int
+	finishTop := func() {
+		if fc.checkpoint == nil || top == "" {
+			return
+		}
+		if err := fc.checkpoint.record(top, files[topStart:], fc.permissions[topPermStart:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		top = ""
+	}
+
+	var visit fs.WalkDirFunc
+	visit = func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			if os.IsPermission(err) {
 				fmt.Fprintf(os.Stderr, "Warning: permission denied: %s\n", path)
 				return nil
 			}
+			if d != nil && d.IsDir() && fc.walkRetries > 0 && isTransientFSError(err) {
+				return fc.retryDir(ctx, path, err, visit)
+			}
 			return err
 		}
 
@@ -90,54 +487,136 @@ func (fc *FilesystemCollector) collectFromPath(ctx context.Context, root string,
 		default:
 		}
 
-		if d.IsDir() {
-			// Skip hidden directories (but not for extra scan paths like lost+found)
-			if source != models.MediaSourceExtra && strings.HasPrefix(d.Name(), ".") {
+		if fc.isExcluded(live(path)) {
+			if d.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		if d.IsDir() && slices.Contains(nested, filepath.Clean(live(path))) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() && fc.hasIgnoreMarker(path) {
+			return filepath.SkipDir
+		}
 
-		isHidden := strings.HasPrefix(filepath.Base(path), ".")
+		depth := pathDepth(walkRoot, path)
+		if fc.maxDepth > 0 && depth > fc.maxDepth {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 
-		// For library/torrent sources: skip hidden files unless they're .parts files
-		// For extra sources: collect everything
-		if source != models.MediaSourceExtra && isHidden && !strings.HasSuffix(path, ".parts") {
+		// A followed symlink's walk starts at "link/", which is the same
+		// top-level entry and must not close it.
+		if depth == 1 && fc.checkpoint != nil && filepath.Clean(path) != top {
+			finishTop()
+			if cached, perms, ok := fc.checkpoint.done(path); ok {
+				files = append(files, cached...)
+				if fc.collectPerms {
+					fc.permissions = append(fc.permissions, perms...)
+				}
+				fc.counted(len(cached))
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || (fc.followSymlinks && d.Type()&fs.ModeSymlink != 0) {
+				top, topStart, topPermStart = filepath.Clean(path), len(files), len(fc.permissions)
+			}
+		}
+
+		if fc.followSymlinks && d.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Stat(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to resolve symlink %s: %v\n", path, err)
+				return nil
+			}
+			if target.IsDir() {
+				if source != models.MediaSourceExtra && strings.HasPrefix(d.Name(), ".") {
+					return nil
+				}
+				if fc.maxDepth > 0 && depth >= fc.maxDepth {
+					return nil
+				}
+				// Walking "link/" makes WalkDir resolve the link for its root
+				// while keeping the symlinked path in everything it reports.
+				return filepath.WalkDir(path+string(filepath.Separator), visit)
+			}
+		}
+
+		// One stat per entry feeds the media file, the permission record and
+		// the symlink loop check.
+		if d.IsDir() {
+			if fc.collectPerms || fc.followSymlinks {
+				stats, err := getFileStats(path)
+				if err == nil {
+					fc.recordPermissions(live(path), true, stats, source)
+				}
+				if err == nil && fc.followSymlinks {
+					key := dirKey{stats.device, stats.inode}
+					if _, seen := visited[key]; seen {
+						return filepath.SkipDir
+					}
+					visited[key] = struct{}{}
+				}
+			}
+			// Skip hidden directories (but not for extra scan paths like lost+found)
+			if source != models.MediaSourceExtra && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if fc.maxDepth > 0 && depth >= fc.maxDepth {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Skip metadata files (but not for extra scan paths or hidden files)
-		if !isHidden && source != models.MediaSourceExtra && analysis.IsMetadataFile(path) {
+		if skipHiddenFile(path, source) {
+			if fc.collectPerms {
+				if stats, err := getFileStats(path); err == nil {
+					fc.recordPermissions(live(path), false, stats, source)
+				}
+			}
 			return nil
 		}
 
-		info, err := d.Info()
+		stats, err := getFileStats(path)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to get info for %s: %v\n", path, err)
+			info, infoErr := d.Info()
+			if infoErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to get info for %s: %v\n", path, infoErr)
+				return nil
+			}
+			if fc.statFailures++; fc.statFailures <= maxStatWarnings {
+				fmt.Fprintf(os.Stderr, "Warning: failed to get file stats for %s: %v\n", path, err)
+			}
+			stats = fallbackStats(info)
+		} else {
+			fc.recordPermissions(live(path), false, stats, source)
+		}
+
+		if fc.minFileAge > 0 && stats.modTime.After(cutoff) {
+			fc.inFlight++
 			return nil
 		}
 
-		hardlinkCount, blockSize, err := getFileStats(path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to get file stats for %s: %v\n", path, err)
-			hardlinkCount = 1
-			blockSize = info.Size()
-		}
-
-		files = append(files, models.MediaFile{
-			Path:          path,
-			Size:          info.Size(),
-			BlockSize:     blockSize,
-			ModTime:       info.ModTime(),
-			HardlinkCount: hardlinkCount,
-			IsHardlinked:  hardlinkCount > 1,
-			IsHidden:      isHidden,
-			Source:        source,
-		})
+		files = append(files, mediaFileWithStats(live(path), source, stats))
+		fc.counted(1)
 
 		return nil
-	})
+	}
 
+	err := filepath.WalkDir(walkRoot, visit)
+	if err == nil {
+		finishTop()
+	}
+	if fc.checkpoint != nil {
+		if ferr := fc.checkpoint.Flush(); ferr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", ferr)
+		}
+	}
 	if err != nil {
 		return files, fmt.Errorf("failed to walk root: %w", err)
 	}
@@ -145,11 +624,41 @@ func (fc *FilesystemCollector) collectFromPath(ctx context.Context, root string,
 	return files, nil
 }
 
-func getFileStats(path string) (hardlinks int, blockSize int64, err error) {
-	var stat syscall.Stat_t
-	err = syscall.Stat(path, &stat)
+type dirKey struct {
+	device uint64
+	inode  uint64
+}
+
+type fileStats struct {
+	hardlinks int
+	blockSize int64
+	device    uint64
+	inode     uint64
+	size      int64
+	modTime   time.Time
+	mode      uint32
+	uid       int
+	gid       int
+}
+
+func getFileStats(path string) (fileStats, error) {
+	info, err := os.Stat(path)
 	if err != nil {
-		return 0, 0, err
+		return fileStats{}, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStats{}, fmt.Errorf("no stat data for %s", path)
 	}
-	return int(stat.Nlink), stat.Blocks * 512, nil
+	return fileStats{
+		hardlinks: int(stat.Nlink),
+		blockSize: stat.Blocks * 512,
+		device:    uint64(stat.Dev),
+		inode:     stat.Ino,
+		size:      info.Size(),
+		modTime:   info.ModTime(),
+		mode:      uint32(stat.Mode),
+		uid:       int(stat.Uid),
+		gid:       int(stat.Gid),
+	}, nil
 }
@@ -0,0 +1,420 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestCollectFromPath_FollowSymlinks(t *testing.T) {
+	root := t.TempDir()
+	seasons := t.TempDir()
+
+	mustWrite := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustWrite(filepath.Join(root, "Show", "Show.S01E01.mkv"))
+	mustWrite(filepath.Join(seasons, "Show.S02E01.mkv"))
+
+	if err := os.Symlink(seasons, filepath.Join(root, "Show", "Season 2")); err != nil {
+		t.Fatal(err)
+	}
+	// A link back to an ancestor must not cause infinite recursion.
+	if err := os.Symlink(root, filepath.Join(root, "Show", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(follow bool) []string {
+		fc := NewFilesystemCollector(root, "", nil)
+		fc.SetFollowSymlinks(follow)
+		files, err := fc.collectFromPath(context.Background(), root, models.MediaSourceLibrary)
+		if err != nil {
+			t.Fatalf("collectFromPath: %v", err)
+		}
+		var paths []string
+		for _, f := range files {
+			rel, _ := filepath.Rel(root, f.Path)
+			paths = append(paths, rel)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	for _, p := range collect(false) {
+		if p == "Show/Season 2/Show.S02E01.mkv" {
+			t.Errorf("without follow_symlinks the walk descended into a symlinked directory")
+		}
+	}
+
+	got := collect(true)
+	want := []string{"Show/Season 2/Show.S02E01.mkv", "Show/Show.S01E01.mkv"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("with follow_symlinks got %v, want %v", got, want)
+	}
+}
+
+func TestCollectFromPath_MinFileAge(t *testing.T) {
+	root := t.TempDir()
+	settled := filepath.Join(root, "settled.mkv")
+	writing := filepath.Join(root, "writing.mkv")
+	for _, p := range []string{settled, writing} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(settled, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	fc := NewFilesystemCollector(root, "", nil)
+	fc.SetMinFileAge(time.Minute)
+	files, err := fc.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != settled {
+		t.Errorf("expected only %s, got %+v", settled, files)
+	}
+	if fc.InFlightSkipped() != 1 {
+		t.Errorf("InFlightSkipped = %d, want 1", fc.InFlightSkipped())
+	}
+}
+
+func TestCollectFromPath_MaxDepth(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{"top.mkv", "tv/Show/S01E01.mkv", "tv/Show/Season 1/S01E02.mkv"} {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for depth, want := range map[int]int{0: 3, 1: 1, 2: 1, 3: 2, 4: 3} {
+		fc := NewFilesystemCollector(root, "", nil)
+		fc.SetMaxDepth(depth)
+		files, err := fc.collectFromPath(context.Background(), root, models.MediaSourceLibrary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != want {
+			t.Errorf("max depth %d: collected %d files, want %d", depth, len(files), want)
+		}
+	}
+}
+
+func TestCollectFromPath_ResumesFromCheckpoint(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, rel := range []string{"a/one.mkv", "b/two.mkv", "top.mkv"} {
+		write(rel)
+	}
+
+	cpPath := filepath.Join(t.TempDir(), "checkpoint.json")
+	fc := NewFilesystemCollector(root, "", nil)
+	fc.SetCheckpoint(NewCheckpoint(cpPath))
+	fc.SetCollectPermissions(nil)
+	if _, err := fc.collectFromPath(context.Background(), root, models.MediaSourceLibrary); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := LoadCheckpoint(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Len() != 2 {
+		t.Fatalf("checkpoint holds %d directories, want 2 (a and b)", cp.Len())
+	}
+
+	// Files added to a completed directory aren't seen on resume, while its
+	// recorded files and those in unrecorded directories are.
+	write("a/new.mkv")
+	write("c/three.mkv")
+	delete(cp.Completed, filepath.Join(root, "b"))
+	fc = NewFilesystemCollector(root, "", nil)
+	fc.SetCheckpoint(cp)
+	fc.SetCollectPermissions(nil)
+	files, err := fc.collectFromPath(context.Background(), root, models.MediaSourceLibrary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f.Path)
+		got = append(got, rel)
+	}
+	sort.Strings(got)
+	want := []string{"a/one.mkv", "b/two.mkv", "c/three.mkv", "top.mkv"}
+	if len(got) != len(want) {
+		t.Fatalf("collected %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("collected %v, want %v", got, want)
+		}
+	}
+
+	// Permission records of the skipped directory come from the checkpoint.
+	perms := make(map[string]bool)
+	for _, p := range fc.Permissions() {
+		rel, _ := filepath.Rel(root, p.Path)
+		perms[rel] = true
+	}
+	for _, rel := range []string{"a", "a/one.mkv", "c/three.mkv"} {
+		if !perms[rel] {
+			t.Errorf("no permission record for %s after resume", rel)
+		}
+	}
+}
+
+func TestCollect_RecordsPermissionsDuringWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"Show/S01E01.mkv", "Show/.hidden", "skip/S01E02.mkv"} {
+		path := filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fc := NewFilesystemCollector(root, "", nil)
+	fc.SetCollectPermissions([]string{filepath.Join(root, "skip")})
+	if _, err := fc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	got := make(map[string]models.FilePermissions)
+	for _, p := range fc.Permissions() {
+		rel, _ := filepath.Rel(root, p.Path)
+		got[rel] = p
+	}
+	for _, want := range []string{".", "Show", "Show/S01E01.mkv", "Show/.hidden"} {
+		if _, ok := got[want]; !ok {
+			t.Errorf("no permission record for %q (got %v)", want, got)
+		}
+	}
+	if _, ok := got["skip/S01E02.mkv"]; ok {
+		t.Error("recorded a path under a permission skip path")
+	}
+	if f := got["Show/S01E01.mkv"]; f.IsDirectory || f.Mode&0o777 != 0o640 || f.OwnerUID != os.Getuid() {
+		t.Errorf("unexpected record %+v", f)
+	}
+	if !got["Show"].IsDirectory {
+		t.Error("directory recorded as a file")
+	}
+}
+
+func TestCollect_SnapshotRoot(t *testing.T) {
+	live := filepath.Join(t.TempDir(), "media")
+	snapshot := t.TempDir()
+	for _, rel := range []string{"tv/Show/S01E01.mkv", "excluded/Film.mkv"} {
+		path := filepath.Join(snapshot, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fc := NewFilesystemCollector(live, "", nil)
+	fc.SetSnapshotRoot(live, snapshot)
+	fc.SetExcludePaths([]string{filepath.Join(live, "excluded")})
+	fc.SetCollectPermissions(nil)
+	files, err := fc.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	want := filepath.Join(live, "tv/Show/S01E01.mkv")
+	if len(files) != 1 || files[0].Path != want {
+		t.Fatalf("collected %v, want only %s", files, want)
+	}
+	for _, p := range fc.Permissions() {
+		if !strings.HasPrefix(p.Path, live) {
+			t.Errorf("permission record %s is not under the live root", p.Path)
+		}
+	}
+}
+
+func TestCollect_NestedTorrentRoot(t *testing.T) {
+	media := t.TempDir()
+	torrents := filepath.Join(media, "torrents")
+	for _, rel := range []string{"movies/Film.mkv", "torrents/Film.mkv"} {
+		path := filepath.Join(media, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fc := NewFilesystemCollector(media, torrents, nil)
+	files, err := fc.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	got := make(map[string]models.MediaFileSource)
+	for _, f := range files {
+		rel, _ := filepath.Rel(media, f.Path)
+		got[rel] = f.Source
+	}
+	want := map[string]models.MediaFileSource{
+		"movies/Film.mkv":   models.MediaSourceLibrary,
+		"torrents/Film.mkv": models.MediaSourceTorrent,
+	}
+	if len(files) != len(want) {
+		t.Fatalf("collected %d files (%v), want each once", len(files), got)
+	}
+	for rel, source := range want {
+		if got[rel] != source {
+			t.Errorf("%s: source %q, want %q", rel, got[rel], source)
+		}
+	}
+}
+
+func TestCollect_IgnoreMarkers(t *testing.T) {
+	media := t.TempDir()
+	for _, rel := range []string{"tv/Show/E01.mkv", "tv/Samples/.nomedia", "tv/Samples/Clip/S.mkv", "movies/Film.mkv"} {
+		path := filepath.Join(media, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fc := NewFilesystemCollector(media, "", nil)
+	fc.SetIgnoreMarkers([]string{".auditarr-ignore", ".nomedia"})
+	files, err := fc.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(media, f.Path)
+		got = append(got, rel)
+	}
+	if len(got) != 2 || got[0] != "movies/Film.mkv" || got[1] != "tv/Show/E01.mkv" {
+		t.Errorf("collected %v, want the marked tv/Samples tree skipped", got)
+	}
+}
+
+func TestFilesystemCollector_IsExcluded(t *testing.T) {
+	fc := &FilesystemCollector{}
+	fc.SetExcludePaths([]string{"/mnt/media/tv", "/mnt/media/kids/"})
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"/mnt/media/tv", true},
+		{"/mnt/media/tv/Show/S01E01.mkv", true},
+		{"/mnt/media/tv2/Show/S01E01.mkv", false},
+		{"/mnt/media/tvshows", false},
+		{"/mnt/media/kids/Film.mkv", true},
+		{"/mnt/media/movies/Film.mkv", false},
+	} {
+		if got := fc.isExcluded(tc.path); got != tc.want {
+			t.Errorf("isExcluded(%q) = %t, want %t", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestRetryDir(t *testing.T) {
+	defer func(d time.Duration) { walkRetryDelay = d }(walkRetryDelay)
+	walkRetryDelay = time.Millisecond
+
+	dir := t.TempDir()
+	for _, rel := range []string{"a.mkv", "sub/b.mkv"} {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var visited []string
+	visit := func(path string, d fs.DirEntry, err error) error {
+		visited = append(visited, strings.TrimPrefix(path, dir))
+		return err
+	}
+	fc := NewFilesystemCollector(dir, "", nil)
+	fc.SetWalkRetries(2)
+	err := fc.retryDir(context.Background(), dir, &fs.PathError{Op: "readdirent", Path: dir, Err: syscall.ESTALE}, visit)
+	if err != filepath.SkipDir {
+		t.Fatalf("retryDir = %v, want SkipDir after walking the re-read entries", err)
+	}
+	if want := []string{"/a.mkv", "/sub", "/sub/b.mkv"}; !slices.Equal(visited, want) {
+		t.Errorf("visited %v, want %v", visited, want)
+	}
+
+	if err := fc.retryDir(context.Background(), filepath.Join(dir, "gone"), syscall.ESTALE, visit); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("retryDir on a missing dir = %v, want the non-transient error", err)
+	}
+	if isTransientFSError(os.ErrPermission) || !isTransientFSError(&fs.PathError{Err: syscall.EIO}) {
+		t.Error("isTransientFSError misclassified permission or EIO errors")
+	}
+}
+
+func TestHardlinkDetectionWarning(t *testing.T) {
+	files := func(n, links int) []models.MediaFile {
+		out := make([]models.MediaFile, n)
+		for i := range out {
+			out[i] = models.MediaFile{Source: models.MediaSourceLibrary, HardlinkCount: links}
+		}
+		return out
+	}
+
+	if w := hardlinkDetectionWarning(files(minHardlinkSample, 1), 0); w == "" {
+		t.Error("expected a warning when no file reports more than one link")
+	}
+	if w := hardlinkDetectionWarning(files(minHardlinkSample, 1), minHardlinkSample); !strings.Contains(w, "Could not stat") {
+		t.Errorf("expected a stat failure warning, got %q", w)
+	}
+	if w := hardlinkDetectionWarning(files(minHardlinkSample-1, 1), 0); w != "" {
+		t.Errorf("small scans should not warn, got %q", w)
+	}
+
+	mixed := append(files(minHardlinkSample, 1), models.MediaFile{Source: models.MediaSourceTorrent, HardlinkCount: 2})
+	if w := hardlinkDetectionWarning(mixed, 0); w != "" {
+		t.Errorf("a single hardlinked file means link counts work, got %q", w)
+	}
+
+	extra := append(files(minHardlinkSample, 1), models.MediaFile{Source: models.MediaSourceExtra, HardlinkCount: 2})
+	if w := hardlinkDetectionWarning(extra, 0); w == "" {
+		t.Error("extra scan paths should not count as evidence of working hardlinks")
+	}
+}
@@ -0,0 +1,58 @@
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) models.MediaFile {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return models.MediaFile{Path: path, Size: int64(len(data)), ModTime: time.Unix(1700000000, 0)}
+	}
+
+	big := make([]byte, 4*fingerprintBlock)
+	a := write("a.mkv", big)
+	mid := append([]byte(nil), big...)
+	mid[2*fingerprintBlock] = 1
+	b := write("b.mkv", mid)
+	head := append([]byte(nil), big...)
+	head[0] = 1
+	c := write("c.mkv", head)
+
+	fp := func(f models.MediaFile, mode string) string {
+		t.Helper()
+		s, err := Fingerprint(f, mode)
+		if err != nil {
+			t.Fatalf("Fingerprint(%s, %s): %v", f.Path, mode, err)
+		}
+		return s
+	}
+
+	if got := fp(a, FingerprintStat); got != "stat:262144-1700000000000000000" {
+		t.Errorf("stat fingerprint = %q", got)
+	}
+	if !strings.HasPrefix(fp(a, FingerprintSample), "sample:") {
+		t.Errorf("sample fingerprint lacks its mode prefix")
+	}
+	// Sampling only reads the ends: a change in the middle goes unnoticed
+	// there but not with a full hash.
+	if fp(a, FingerprintSample) != fp(b, FingerprintSample) {
+		t.Error("sample fingerprint changed for a middle-only edit")
+	}
+	if fp(a, FingerprintFull) == fp(b, FingerprintFull) {
+		t.Error("full fingerprint missed a middle-only edit")
+	}
+	if fp(a, FingerprintSample) == fp(c, FingerprintSample) {
+		t.Error("sample fingerprint missed a change in the first block")
+	}
+}
@@ -0,0 +1,57 @@
+package collectors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// MatchKnownBadHashes hashes every file flagged for its extension with
+// SHA-256 and escalates those whose hash is in knownBad to critical. Files
+// that can't be read keep their extension-based finding and are reported as
+// a warning. It returns how many files matched.
+func MatchKnownBadHashes(ctx context.Context, files []models.SuspiciousFile, knownBad map[string]bool) (int, error) {
+	matched := 0
+	for i := range files {
+		select {
+		case <-ctx.Done():
+			return matched, ctx.Err()
+		default:
+		}
+		if files[i].Code == models.ReasonExcessiveHardlinks {
+			continue
+		}
+		sum, err := sha256File(files[i].Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to hash %s: %v\n", files[i].Path, err)
+			continue
+		}
+		files[i].SHA256 = sum
+		if knownBad[sum] {
+			files[i].Code = models.ReasonKnownBadHash
+			files[i].Reason = "SHA-256 matches the known-bad hash list"
+			files[i].Severity = models.SuspiciousCritical
+			matched++
+		}
+	}
+	return matched, nil
+}
+
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,40 @@
+package collectors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestMatchKnownBadHashes(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.exe")
+	other := filepath.Join(dir, "other.exe")
+	if err := os.WriteFile(bad, []byte("payload"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("harmless"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("payload"))
+
+	files := []models.SuspiciousFile{
+		{Path: bad, Code: models.ReasonSuspiciousExtension, Severity: models.SuspiciousInfo},
+		{Path: other, Code: models.ReasonSuspiciousExtension, Severity: models.SuspiciousInfo},
+	}
+	matched, err := MatchKnownBadHashes(context.Background(), files, map[string]bool{hex.EncodeToString(sum[:]): true})
+	if err != nil || matched != 1 {
+		t.Fatalf("MatchKnownBadHashes = %d, %v; want 1 match", matched, err)
+	}
+	if files[0].Severity != models.SuspiciousCritical || files[0].Code != models.ReasonKnownBadHash {
+		t.Errorf("matching file was not escalated: %+v", files[0])
+	}
+	if files[1].Severity != models.SuspiciousInfo || files[1].SHA256 == "" {
+		t.Errorf("non-matching file should keep its severity and record its hash: %+v", files[1])
+	}
+}
@@ -0,0 +1,177 @@
+package collectors
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+type LidarrCollector struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	breaker *breakerTransport
+	failed  []string
+}
+
+func NewLidarrCollector(baseURL, apiKey string) *LidarrCollector {
+	return &LidarrCollector{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: baseURL,
+		apiKey:  apiKey,
+	}
+}
+
+// SetAuth sends basic-auth credentials and extra headers with every request.
+func (lc *LidarrCollector) SetAuth(username, password string, headers map[string]string) {
+	withAuth(lc.client, username, password, headers)
+}
+
+// SetRootCAs verifies the server's certificate against pool.
+func (lc *LidarrCollector) SetRootCAs(pool *x509.CertPool) {
+	withRootCAs(lc.client, pool)
+}
+
+// SetTransport sends requests through transport, e.g. the shared [http] one.
+func (lc *LidarrCollector) SetTransport(transport http.RoundTripper) {
+	withTransport(lc.client, transport)
+}
+
+// SetRequestTimeout bounds each request; zero keeps the 30s default.
+func (lc *LidarrCollector) SetRequestTimeout(timeout time.Duration) {
+	withRequestTimeout(lc.client, timeout)
+}
+
+// SetCircuitBreaker skips further requests after threshold consecutive
+// failures. Call it after SetAuth and SetRootCAs or SetTransport.
+func (lc *LidarrCollector) SetCircuitBreaker(threshold int) {
+	lc.breaker = withCircuitBreaker(lc.client, threshold)
+}
+
+// SkippedRequests returns how many requests the circuit breaker refused.
+func (lc *LidarrCollector) SkippedRequests() int {
+	return lc.breaker.Skipped()
+}
+
+// FailedFetches describes the per-item requests that failed and were skipped.
+func (lc *LidarrCollector) FailedFetches() []string {
+	return lc.failed
+}
+
+func (lc *LidarrCollector) Name() string {
+	return "lidarr"
+}
+
+func (lc *LidarrCollector) TestConnection(ctx context.Context) error {
+	if lc.baseURL == "" {
+		return fmt.Errorf("lidarr URL not configured")
+	}
+
+	url := fmt.Sprintf("%s/api/v1/system/status", lc.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Api-Key", lc.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := lc.client.Do(req)
+	if err != nil {
+		return unreachableError(err)
+	}
+	defer func() {
+		// Drain the body so the connection goes back to the pool.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, "authentication failed (invalid API key)")
+	}
+
+	return nil
+}
+
+func (lc *LidarrCollector) Collect(ctx context.Context) ([]models.ArrFile, error) {
+	if lc.baseURL == "" {
+		return nil, nil
+	}
+
+	var arrFiles []models.ArrFile
+
+	artists, err := lc.fetchArtists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artists: %w", err)
+	}
+
+	for _, artist := range artists {
+		select {
+		case <-ctx.Done():
+			return arrFiles, ctx.Err()
+		default:
+		}
+
+		trackFiles, err := lc.fetchTrackFiles(ctx, artist.ID)
+		if err != nil {
+			if !errors.Is(err, ErrCircuitOpen) {
+				fmt.Printf("Warning: failed to fetch track files for artist %d: %v\n", artist.ID, err)
+				lc.failed = append(lc.failed, fmt.Sprintf("track files for artist %d: %v", artist.ID, err))
+			}
+			continue
+		}
+
+		for _, tf := range trackFiles {
+			arrFiles = append(arrFiles, models.ArrFile{
+				Path:       tf.Path,
+				ArtistID:   artist.ID,
+				Monitored:  artist.Monitored,
+				ImportDate: tf.DateAdded,
+				Size:       tf.Size,
+			})
+		}
+	}
+
+	return arrFiles, nil
+}
+
+func (lc *LidarrCollector) fetchArtists(ctx context.Context) ([]lidarrArtist, error) {
+	url := fmt.Sprintf("%s/api/v1/artist", lc.baseURL)
+	return fetchArrList[lidarrArtist](ctx, lc.client, url, lc.apiKey)
+}
+
+func (lc *LidarrCollector) fetchTrackFiles(ctx context.Context, artistID int) ([]lidarrTrackFile, error) {
+	url := fmt.Sprintf("%s/api/v1/trackfile?artistId=%d", lc.baseURL, artistID)
+	return fetchArrList[lidarrTrackFile](ctx, lc.client, url, lc.apiKey)
+}
+
+func (lc *LidarrCollector) FetchRootFolders(ctx context.Context) ([]models.RootFolder, error) {
+	url := fmt.Sprintf("%s/api/v1/rootfolder", lc.baseURL)
+	folders, err := fetchArrList[arrRootFolder](ctx, lc.client, url, lc.apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return toRootFolders(folders), nil
+}
+
+type lidarrArtist struct {
+	ID         int    `json:"id"`
+	ArtistName string `json:"artistName"`
+	Monitored  bool   `json:"monitored"`
+}
+
+type lidarrTrackFile struct {
+	ID        int       `json:"id"`
+	ArtistID  int       `json:"artistId"`
+	Path      string    `json:"path"`
+	DateAdded time.Time `json:"dateAdded"`
+	Size      int64     `json:"size"`
+}
@@ -0,0 +1,69 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLidarrCollector_Collect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/system/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v1/artist", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]lidarrArtist{{ID: 1, Monitored: true}, {ID: 2}})
+	})
+	mux.HandleFunc("/api/v1/trackfile", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("artistId") {
+		case "1":
+			_ = json.NewEncoder(w).Encode([]lidarrTrackFile{{ID: 10, ArtistID: 1, Path: "/music/a/01.flac", Size: 30}})
+		default:
+			http.Error(w, "database locked", http.StatusInternalServerError)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	if err := NewLidarrCollector(srv.URL, "wrong").TestConnection(context.Background()); err == nil {
+		t.Error("TestConnection with a bad API key succeeded")
+	}
+
+	lc := NewLidarrCollector(srv.URL, "key")
+	if err := lc.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection: %v", err)
+	}
+	files, err := lc.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "/music/a/01.flac" || files[0].ArtistID != 1 || !files[0].Monitored || files[0].Size != 30 {
+		t.Fatalf("files = %+v, want artist 1's track", files)
+	}
+	if failed := lc.FailedFetches(); len(failed) != 1 || !strings.Contains(failed[0], "artist 2") {
+		t.Errorf("FailedFetches = %q, want artist 2's track files", failed)
+	}
+}
+
+func TestNewArrCollector(t *testing.T) {
+	for _, kind := range ArrKinds {
+		c, err := NewArrCollector(kind, "http://localhost", "key")
+		if err != nil {
+			t.Errorf("NewArrCollector(%q): %v", kind, err)
+			continue
+		}
+		if c.Name() != kind {
+			t.Errorf("NewArrCollector(%q).Name() = %q", kind, c.Name())
+		}
+	}
+	if _, err := NewArrCollector("readarr", "http://localhost", "key"); err == nil {
+		t.Error("NewArrCollector accepted an unsupported kind")
+	}
+}
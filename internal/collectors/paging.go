@@ -0,0 +1,112 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// arrPageSize is the page size requested from Sonarr/Radarr list endpoints.
+const arrPageSize = 1000
+
+// arrPage is the paging envelope Sonarr/Radarr v3 return from paginated
+// endpoints.
+type arrPage[T any] struct {
+	Page         int `json:"page"`
+	PageSize     int `json:"pageSize"`
+	TotalRecords int `json:"totalRecords"`
+	Records      []T `json:"records"`
+}
+
+type arrRootFolder struct {
+	ID              int    `json:"id"`
+	Path            string `json:"path"`
+	UnmappedFolders []struct {
+		Path string `json:"path"`
+	} `json:"unmappedFolders"`
+}
+
+func toRootFolders(folders []arrRootFolder) []models.RootFolder {
+	result := make([]models.RootFolder, 0, len(folders))
+	for _, f := range folders {
+		rf := models.RootFolder{Path: f.Path}
+		for _, u := range f.UnmappedFolders {
+			rf.UnmappedFolders = append(rf.UnmappedFolders, u.Path)
+		}
+		result = append(result, rf)
+	}
+	return result
+}
+
+// fetchArrList GETs an Arr list endpoint, following pagination until every
+// record has been retrieved. Endpoints that ignore page/pageSize and return a
+// plain JSON array are handled in a single request.
+func fetchArrList[T any](ctx context.Context, client *http.Client, endpoint, apiKey string) ([]T, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []T
+	for page := 1; ; page++ {
+		q := base.Query()
+		q.Set("page", strconv.Itoa(page))
+		q.Set("pageSize", strconv.Itoa(arrPageSize))
+		pageURL := *base
+		pageURL.RawQuery = q.Encode()
+
+		body, err := fetchArrBody(ctx, client, pageURL.String(), apiKey)
+		if err != nil {
+			return nil, err
+		}
+
+		trimmed := bytes.TrimLeft(body, " \t\r\n")
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			var records []T
+			if err := json.Unmarshal(trimmed, &records); err != nil {
+				return nil, err
+			}
+			return append(all, records...), nil
+		}
+
+		var p arrPage[T]
+		if err := json.Unmarshal(trimmed, &p); err != nil {
+			return nil, err
+		}
+		all = append(all, p.Records...)
+
+		if len(p.Records) == 0 || len(all) >= p.TotalRecords {
+			return all, nil
+		}
+	}
+}
+
+func fetchArrBody(ctx context.Context, client *http.Client, endpoint, apiKey string) ([]byte, error) {
+	resp, err := doWithRetry(ctx, client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Api-Key", apiKey)
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
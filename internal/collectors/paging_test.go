@@ -0,0 +1,59 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestFetchArrList_FollowsPagination(t *testing.T) {
+	const total = 2500
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		if r.URL.Query().Get("seriesId") != "7" {
+			t.Errorf("existing query parameters were dropped: %s", r.URL.RawQuery)
+		}
+		var records []sonarrEpisodeFile
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			records = append(records, sonarrEpisodeFile{ID: i + 1})
+		}
+		_ = json.NewEncoder(w).Encode(arrPage[sonarrEpisodeFile]{
+			Page: page, PageSize: size, TotalRecords: total, Records: records,
+		})
+	}))
+	defer srv.Close()
+
+	files, err := fetchArrList[sonarrEpisodeFile](context.Background(), srv.Client(), srv.URL+"/api/v3/episodefile?seriesId=7", "key")
+	if err != nil {
+		t.Fatalf("fetchArrList: %v", err)
+	}
+	if len(files) != total {
+		t.Errorf("got %d records, want %d", len(files), total)
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+}
+
+func TestFetchArrList_PlainArray(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode([]radarrMovie{{ID: 1}, {ID: 2}})
+	}))
+	defer srv.Close()
+
+	movies, err := fetchArrList[radarrMovie](context.Background(), srv.Client(), srv.URL+"/api/v3/movie", "key")
+	if err != nil {
+		t.Fatalf("fetchArrList: %v", err)
+	}
+	if len(movies) != 2 || requests != 1 {
+		t.Errorf("got %d movies in %d requests, want 2 in 1", len(movies), requests)
+	}
+}
@@ -2,11 +2,15 @@ package collectors
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +18,10 @@ import (
 	"github.com/jdpx/auditarr/internal/models"
 )
 
+// errSessionExpired is returned when qBittorrent rejects the session cookie
+// with 403, typically because the WebUI session timed out mid-collection.
+var errSessionExpired = errors.New("session expired")
+
 type QBCollector struct {
 	client   *http.Client
 	baseURL  string
@@ -21,6 +29,7 @@ type QBCollector struct {
 	password string
 	cookie   string
 	mu       sync.Mutex
+	failed   []string
 }
 
 func NewQBCollector(baseURL, username, password string) *QBCollector {
@@ -34,10 +43,60 @@ func NewQBCollector(baseURL, username, password string) *QBCollector {
 	}
 }
 
+// SetRootCAs verifies the WebUI's certificate against pool.
+func (qbc *QBCollector) SetRootCAs(pool *x509.CertPool) {
+	withRootCAs(qbc.client, pool)
+}
+
+// SetTransport sends requests through transport, e.g. the shared [http] one.
+func (qbc *QBCollector) SetTransport(transport http.RoundTripper) {
+	withTransport(qbc.client, transport)
+}
+
 func (qbc *QBCollector) Name() string {
 	return "qbittorrent"
 }
 
+// FailedFetches describes the per-torrent requests that failed during
+// Collect; those torrents are reported without their files or trackers.
+func (qbc *QBCollector) FailedFetches() []string {
+	return qbc.failed
+}
+
+func (qbc *QBCollector) TestConnection(ctx context.Context) error {
+	if qbc.baseURL == "" {
+		return fmt.Errorf("qbittorrent URL not configured")
+	}
+
+	if err := qbc.authenticate(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	qbc.mu.Lock()
+	cookie := qbc.cookie
+	qbc.mu.Unlock()
+
+	url := fmt.Sprintf("%s/api/v2/app/version", qbc.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Cookie", cookie)
+
+	resp, err := qbc.client.Do(req)
+	if err != nil {
+		return unreachableError(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, "authentication failed (session rejected)")
+	}
+
+	return nil
+}
+
 func (qbc *QBCollector) Collect(ctx context.Context) ([]models.Torrent, error) {
 	if qbc.baseURL == "" {
 		return nil, nil
@@ -53,6 +112,8 @@ func (qbc *QBCollector) Collect(ctx context.Context) ([]models.Torrent, error) {
 	}
 
 	var result []models.Torrent
+	unmapped := make(map[string]int)
+	defer warnUnmappedStates(unmapped)
 	for _, t := range torrents {
 		select {
 		case <-ctx.Done():
@@ -63,6 +124,24 @@ func (qbc *QBCollector) Collect(ctx context.Context) ([]models.Torrent, error) {
 		files, err := qbc.fetchTorrentFiles(ctx, t.Hash)
 		if err != nil {
 			fmt.Printf("Warning: failed to fetch files for torrent %s: %v\n", t.Hash, err)
+			qbc.failed = append(qbc.failed, fmt.Sprintf("files for torrent %s: %v", t.Hash, err))
+		}
+
+		var trackers []string
+		var private bool
+		if t.Private != nil {
+			// qBittorrent 5.0+ reports privacy in the listing, so the
+			// current tracker is enough and saves a request per torrent.
+			private = *t.Private
+			if t.Tracker != "" {
+				trackers = []string{t.Tracker}
+			}
+		} else {
+			trackers, private, err = qbc.fetchTorrentTrackers(ctx, t.Hash)
+			if err != nil {
+				fmt.Printf("Warning: failed to fetch trackers for torrent %s: %v\n", t.Hash, err)
+				qbc.failed = append(qbc.failed, fmt.Sprintf("trackers for torrent %s: %v", t.Hash, err))
+			}
 		}
 
 		completedOn := time.Time{}
@@ -70,14 +149,22 @@ func (qbc *QBCollector) Collect(ctx context.Context) ([]models.Torrent, error) {
 			completedOn = time.Unix(t.CompletionOn, 0)
 		}
 
+		state, ok := mapQBState(t.State)
+		if !ok {
+			unmapped[t.State]++
+		}
+
 		result = append(result, models.Torrent{
 			Hash:        t.Hash,
 			Name:        t.Name,
 			SavePath:    t.SavePath,
+			Category:    t.Category,
 			Size:        t.Size,
-			State:       mapQBState(t.State),
+			State:       state,
 			CompletedOn: completedOn,
 			Files:       files,
+			Trackers:    trackers,
+			IsPrivate:   private,
 		})
 	}
 
@@ -106,7 +193,7 @@ func (qbc *QBCollector) authenticate(ctx context.Context) error {
 
 	resp, err := qbc.client.Do(req)
 	if err != nil {
-		return err
+		return unreachableError(err)
 	}
 	defer resp.Body.Close()
 
@@ -114,7 +201,7 @@ func (qbc *QBCollector) authenticate(ctx context.Context) error {
 	// successful login. Treat both as success.
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		_, _ = io.Copy(io.Discard, resp.Body)
-		return fmt.Errorf("authentication failed with status %d", resp.StatusCode)
+		return statusError(resp.StatusCode, fmt.Sprintf("authentication failed with status %d", resp.StatusCode))
 	}
 
 	for _, cookie := range resp.Cookies() {
@@ -130,7 +217,8 @@ func (qbc *QBCollector) authenticate(ctx context.Context) error {
 	_, _ = io.Copy(io.Discard, resp.Body)
 
 	if qbc.cookie == "" {
-		return fmt.Errorf("no session cookie received")
+		// qBittorrent answers bad credentials with 200 "Fails." and no cookie.
+		return &ConnectionError{Kind: ConnAuth, Err: fmt.Errorf("no session cookie received (check username/password)")}
 	}
 
 	return nil
@@ -161,7 +249,7 @@ func (qbc *QBCollector) fetchTorrents(ctx context.Context) ([]qbTorrent, error)
 		qbc.cookie = ""
 		qbc.mu.Unlock()
 		_, _ = io.Copy(io.Discard, resp.Body)
-		return nil, fmt.Errorf("session expired")
+		return nil, errSessionExpired
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -177,12 +265,71 @@ func (qbc *QBCollector) fetchTorrents(ctx context.Context) ([]qbTorrent, error)
 	return torrents, nil
 }
 
+// fetchTorrentFiles lists a torrent's files. getJSON logs in again if the
+// session expired since login, so the remaining per-torrent fetches are not
+// all lost.
 func (qbc *QBCollector) fetchTorrentFiles(ctx context.Context, hash string) ([]string, error) {
+	var files []qbFile
+	url := fmt.Sprintf("%s/api/v2/torrents/files?hash=%s", qbc.baseURL, hash)
+	if err := qbc.getJSON(ctx, url, &files); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Name)
+	}
+
+	return paths, nil
+}
+
+// fetchTorrentTrackers returns a torrent's tracker URLs and whether it is
+// private. qBittorrent lists DHT, PeX and LSD as pseudo-trackers; for private
+// torrents these are disabled with a "This torrent is private" message, which
+// is used as the signal on versions that do not report privacy directly.
+func (qbc *QBCollector) fetchTorrentTrackers(ctx context.Context, hash string) ([]string, bool, error) {
+	var trackers []qbTracker
+	url := fmt.Sprintf("%s/api/v2/torrents/trackers?hash=%s", qbc.baseURL, hash)
+	if err := qbc.getJSON(ctx, url, &trackers); err != nil {
+		return nil, false, err
+	}
+
+	var urls []string
+	private := false
+	for _, tr := range trackers {
+		if strings.HasPrefix(tr.URL, "** [") {
+			if strings.Contains(strings.ToLower(tr.Msg), "private") {
+				private = true
+			}
+			continue
+		}
+		urls = append(urls, tr.URL)
+	}
+
+	return urls, private, nil
+}
+
+// getJSON GETs a WebUI API endpoint and decodes the response into v. If the
+// session expired since login, it re-authenticates once and retries so one
+// timeout mid-collection doesn't fail every remaining request.
+func (qbc *QBCollector) getJSON(ctx context.Context, url string, v any) error {
+	err := qbc.requestJSON(ctx, url, v)
+	if !errors.Is(err, errSessionExpired) {
+		return err
+	}
+
+	if err := qbc.authenticate(ctx); err != nil {
+		return fmt.Errorf("failed to re-authenticate after session expiry: %w", err)
+	}
+
+	return qbc.requestJSON(ctx, url, v)
+}
+
+func (qbc *QBCollector) requestJSON(ctx context.Context, url string, v any) error {
 	qbc.mu.Lock()
 	cookie := qbc.cookie
 	qbc.mu.Unlock()
 
-	url := fmt.Sprintf("%s/api/v2/torrents/files?hash=%s", qbc.baseURL, hash)
 	resp, err := doWithRetry(ctx, qbc.client, func() (*http.Request, error) {
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
@@ -193,42 +340,61 @@ func (qbc *QBCollector) fetchTorrentFiles(ctx context.Context, hash string) ([]s
 		return req, nil
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode == http.StatusForbidden {
+		qbc.mu.Lock()
+		if qbc.cookie == cookie {
+			qbc.cookie = ""
+		}
+		qbc.mu.Unlock()
 		_, _ = io.Copy(io.Discard, resp.Body)
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		return errSessionExpired
 	}
 
-	var files []qbFile
-	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	var paths []string
-	for _, f := range files {
-		paths = append(paths, f.Name)
-	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
 
-	return paths, nil
+// warnUnmappedStates logs each qBittorrent state string mapQBState didn't
+// recognize, once per collection, so new states can be given a mapping.
+func warnUnmappedStates(counts map[string]int) {
+	states := make([]string, 0, len(counts))
+	for s := range counts {
+		states = append(states, s)
+	}
+	sort.Strings(states)
+	for _, s := range states {
+		fmt.Fprintf(os.Stderr, "Warning: unmapped qBittorrent state %q on %d torrent(s); treating as unknown\n", s, counts[s])
+	}
 }
 
-func mapQBState(state string) models.TorrentState {
+// mapQBState translates a qBittorrent WebUI state. ok is false for state
+// strings with no mapping, which are returned as StateUnknown.
+func mapQBState(state string) (mapped models.TorrentState, ok bool) {
 	switch state {
-	case "downloading", "metaDL", "allocating":
-		return models.StateDownloading
-	case "checkingUP", "checkingDL":
-		return models.StateChecking
-	case "uploading", "pausedUP":
-		return models.StateCompleted
-	case "pausedDL":
-		return models.StatePaused
+	case "downloading", "metaDL", "forcedDL", "forcedMetaDL", "queuedDL", "allocating":
+		return models.StateDownloading, true
+	case "checkingUP", "checkingDL", "checkingResumeData":
+		return models.StateChecking, true
+	case "uploading", "forcedUP":
+		return models.StateSeeding, true
+	case "pausedUP", "stoppedUP", "queuedUP":
+		return models.StateCompleted, true
+	case "pausedDL", "stoppedDL":
+		return models.StatePaused, true
 	case "stalledUP", "stalledDL":
-		return models.StateStalled
+		return models.StateStalled, true
+	case "error", "missingFiles", "moving", "unknown":
+		return models.StateUnknown, true
 	default:
-		return models.StateCompleted
+		return models.StateUnknown, false
 	}
 }
 
@@ -237,10 +403,21 @@ type qbTorrent struct {
 	Name         string `json:"name"`
 	State        string `json:"state"`
 	SavePath     string `json:"save_path"`
+	Category     string `json:"category"`
 	Size         int64  `json:"size"`
 	CompletionOn int64  `json:"completion_on"`
+	// Tracker is the tracker currently in use, if any.
+	Tracker string `json:"tracker"`
+	// Private is only reported by qBittorrent 5.0 and later.
+	Private *bool `json:"private"`
 }
 
 type qbFile struct {
 	Name string `json:"name"`
 }
+
+type qbTracker struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	Msg    string `json:"msg"`
+}
@@ -0,0 +1,164 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// A WebUI session can time out partway through the per-torrent file fetches.
+// The collector must log in again and carry on rather than losing every
+// remaining torrent's file list.
+func TestQBCollector_ReauthenticatesOnSessionExpiry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		session  int
+		logins   int
+		expireAt = "bbb"
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		logins++
+		session++
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: fmt.Sprintf("s%d", session)})
+		w.WriteHeader(http.StatusOK)
+	})
+	validSession := func(r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		c, err := r.Cookie("SID")
+		return err == nil && c.Value == fmt.Sprintf("s%d", session)
+	}
+	mux.HandleFunc("/api/v2/torrents/info", func(w http.ResponseWriter, r *http.Request) {
+		if !validSession(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]qbTorrent{
+			{Hash: "aaa", Name: "First", State: "uploading"},
+			{Hash: "bbb", Name: "Second", State: "uploading"},
+			{Hash: "ccc", Name: "Third", State: "uploading"},
+		})
+	})
+	mux.HandleFunc("/api/v2/torrents/files", func(w http.ResponseWriter, r *http.Request) {
+		hash := r.URL.Query().Get("hash")
+		mu.Lock()
+		if hash == expireAt {
+			// Simulate the server-side session timing out mid-run.
+			expireAt = ""
+			session++
+		}
+		mu.Unlock()
+		if !validSession(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]qbFile{{Name: hash + ".mkv"}})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	qbc := NewQBCollector(srv.URL, "user", "pass")
+	torrents, err := qbc.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+
+	if len(torrents) != 3 {
+		t.Fatalf("got %d torrents, want 3", len(torrents))
+	}
+	for _, tr := range torrents {
+		if len(tr.Files) != 1 || tr.Files[0] != tr.Hash+".mkv" {
+			t.Errorf("torrent %s files = %v, want [%s.mkv]", tr.Hash, tr.Files, tr.Hash)
+		}
+	}
+	if logins != 2 {
+		t.Errorf("logins = %d, want 2 (initial + one re-authentication)", logins)
+	}
+}
+
+func TestQBCollector_PrivateTorrents(t *testing.T) {
+	yes, no := true, false
+	var mu sync.Mutex
+	trackerRequests := make(map[string]int)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: "s1"})
+	})
+	mux.HandleFunc("/api/v2/torrents/info", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]qbTorrent{
+			{Hash: "v5private", State: "uploading", Private: &yes, Tracker: "https://private.example/announce"},
+			{Hash: "v5public", State: "uploading", Private: &no},
+			{Hash: "v4private", State: "uploading"},
+			{Hash: "v4public", State: "uploading"},
+		})
+	})
+	mux.HandleFunc("/api/v2/torrents/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/api/v2/torrents/trackers", func(w http.ResponseWriter, r *http.Request) {
+		hash := r.URL.Query().Get("hash")
+		mu.Lock()
+		trackerRequests[hash]++
+		mu.Unlock()
+		dht := qbTracker{URL: "** [DHT] **", Msg: ""}
+		if hash == "v4private" {
+			dht.Msg = "This torrent is private"
+		}
+		_ = json.NewEncoder(w).Encode([]qbTracker{dht, {URL: "https://" + hash + ".example/announce"}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	torrents, err := NewQBCollector(srv.URL, "user", "pass").Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	want := map[string]bool{"v5private": true, "v5public": false, "v4private": true, "v4public": false}
+	for _, tr := range torrents {
+		if tr.IsPrivate != want[tr.Hash] {
+			t.Errorf("torrent %s IsPrivate = %t, want %t", tr.Hash, tr.IsPrivate, want[tr.Hash])
+		}
+	}
+	if len(torrents) != 4 || len(torrents[0].Trackers) != 1 || torrents[0].Trackers[0] != "https://private.example/announce" {
+		t.Errorf("torrents = %+v, want v5private's current tracker recorded from the listing", torrents)
+	}
+	if len(torrents) == 4 && (len(torrents[2].Trackers) != 1 || torrents[2].Trackers[0] != "https://v4private.example/announce") {
+		t.Errorf("v4private trackers = %v, want only the real tracker", torrents[2].Trackers)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if trackerRequests["v5private"]+trackerRequests["v5public"] != 0 || trackerRequests["v4private"] != 1 || trackerRequests["v4public"] != 1 {
+		t.Errorf("tracker requests = %v, want one each only for torrents without a private field", trackerRequests)
+	}
+}
+
+func TestMapQBState(t *testing.T) {
+	for state, want := range map[string]models.TorrentState{
+		"uploading":    models.StateSeeding,
+		"stoppedUP":    models.StateCompleted,
+		"queuedDL":     models.StateDownloading,
+		"stoppedDL":    models.StatePaused,
+		"missingFiles": models.StateUnknown,
+	} {
+		got, ok := mapQBState(state)
+		if got != want || !ok {
+			t.Errorf("mapQBState(%q) = %s, %t; want %s, true", state, got, ok, want)
+		}
+	}
+	if got, ok := mapQBState("someFutureState"); got != models.StateUnknown || ok {
+		t.Errorf("unrecognized state mapped to %s, %t; want unknown, false", got, ok)
+	}
+}
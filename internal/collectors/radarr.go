@@ -2,8 +2,10 @@ package collectors
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -11,13 +13,17 @@ import (
 )
 
 type RadarrCollector struct {
+	name    string
 	client  *http.Client
 	baseURL string
 	apiKey  string
+	breaker *breakerTransport
+	failed  []string
 }
 
 func NewRadarrCollector(baseURL, apiKey string) *RadarrCollector {
 	return &RadarrCollector{
+		name: "radarr",
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -26,8 +32,44 @@ func NewRadarrCollector(baseURL, apiKey string) *RadarrCollector {
 	}
 }
 
+// SetAuth sends basic-auth credentials and extra headers with every request.
+func (rc *RadarrCollector) SetAuth(username, password string, headers map[string]string) {
+	withAuth(rc.client, username, password, headers)
+}
+
+// SetRootCAs verifies the server's certificate against pool.
+func (rc *RadarrCollector) SetRootCAs(pool *x509.CertPool) {
+	withRootCAs(rc.client, pool)
+}
+
+// SetTransport sends requests through transport, e.g. the shared [http] one.
+func (rc *RadarrCollector) SetTransport(transport http.RoundTripper) {
+	withTransport(rc.client, transport)
+}
+
+// SetRequestTimeout bounds each request; zero keeps the 30s default.
+func (rc *RadarrCollector) SetRequestTimeout(timeout time.Duration) {
+	withRequestTimeout(rc.client, timeout)
+}
+
+// SetCircuitBreaker skips further requests after threshold consecutive
+// failures. Call it after SetAuth and SetRootCAs or SetTransport.
+func (rc *RadarrCollector) SetCircuitBreaker(threshold int) {
+	rc.breaker = withCircuitBreaker(rc.client, threshold)
+}
+
+// SkippedRequests returns how many requests the circuit breaker refused.
+func (rc *RadarrCollector) SkippedRequests() int {
+	return rc.breaker.Skipped()
+}
+
+// FailedFetches describes the per-item requests that failed and were skipped.
+func (rc *RadarrCollector) FailedFetches() []string {
+	return rc.failed
+}
+
 func (rc *RadarrCollector) Name() string {
-	return "radarr"
+	return rc.name
 }
 
 func (rc *RadarrCollector) TestConnection(ctx context.Context) error {
@@ -46,15 +88,16 @@ func (rc *RadarrCollector) TestConnection(ctx context.Context) error {
 
 	resp, err := rc.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+		return unreachableError(err)
 	}
-	defer resp.Body.Close()
+	defer func() {
+		// Drain the body so the connection goes back to the pool.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("authentication failed (invalid API key)")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API returned status %d", resp.StatusCode)
+		return statusError(resp.StatusCode, "authentication failed (invalid API key)")
 	}
 
 	return nil
@@ -65,13 +108,61 @@ func (rc *RadarrCollector) Collect(ctx context.Context) ([]models.ArrFile, error
 		return nil, nil
 	}
 
-	var arrFiles []models.ArrFile
+	movies, err := rc.fetchMovies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch movies: %w", err)
+	}
+	return rc.collectMovies(ctx, movies, nil)
+}
+
+// CollectSince refetches movie files only for movies with file history since
+// the given time and reuses cached for the rest, with each movie's current
+// monitored state. Movies no longer in Radarr are dropped. Files added
+// without a history record, e.g. by a disk rescan, are only seen by the next
+// full Collect.
+func (rc *RadarrCollector) CollectSince(ctx context.Context, since time.Time, cached []models.ArrFile) ([]models.ArrFile, error) {
+	if rc.baseURL == "" {
+		return nil, nil
+	}
 
 	movies, err := rc.fetchMovies(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch movies: %w", err)
 	}
+	history, err := fetchArrHistorySince[radarrHistoryRecord](ctx, rc.client, rc.baseURL, rc.apiKey, since)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[int]bool, len(history))
+	for _, h := range history {
+		if changesFiles(h.EventType) {
+			changed[h.MovieID] = true
+		}
+	}
+
+	byMovie := make(map[int][]models.ArrFile)
+	for _, f := range cached {
+		byMovie[f.MovieID] = append(byMovie[f.MovieID], f)
+	}
 
+	var reused []models.ArrFile
+	var refetch []radarrMovie
+	for _, movie := range movies {
+		if changed[movie.ID] {
+			refetch = append(refetch, movie)
+			continue
+		}
+		for _, f := range byMovie[movie.ID] {
+			f.Monitored = movie.Monitored
+			reused = append(reused, f)
+		}
+	}
+	return rc.collectMovies(ctx, refetch, reused)
+}
+
+// collectMovies fetches the files of each movie and appends them to
+// arrFiles.
+func (rc *RadarrCollector) collectMovies(ctx context.Context, movies []radarrMovie, arrFiles []models.ArrFile) ([]models.ArrFile, error) {
 	for _, movie := range movies {
 		select {
 		case <-ctx.Done():
@@ -81,7 +172,10 @@ func (rc *RadarrCollector) Collect(ctx context.Context) ([]models.ArrFile, error
 
 		movieFiles, err := rc.fetchMovieFiles(ctx, movie.ID)
 		if err != nil {
-			fmt.Printf("Warning: failed to fetch movie files for movie %d: %v\n", movie.ID, err)
+			if !errors.Is(err, ErrCircuitOpen) {
+				fmt.Printf("Warning: failed to fetch movie files for movie %d: %v\n", movie.ID, err)
+				rc.failed = append(rc.failed, fmt.Sprintf("movie files for movie %d: %v", movie.ID, err))
+			}
 			continue
 		}
 
@@ -91,6 +185,7 @@ func (rc *RadarrCollector) Collect(ctx context.Context) ([]models.ArrFile, error
 				MovieID:    movie.ID,
 				Monitored:  movie.Monitored,
 				ImportDate: mf.DateAdded,
+				Size:       mf.Size,
 			})
 		}
 	}
@@ -100,58 +195,21 @@ func (rc *RadarrCollector) Collect(ctx context.Context) ([]models.ArrFile, error
 
 func (rc *RadarrCollector) fetchMovies(ctx context.Context) ([]radarrMovie, error) {
 	url := fmt.Sprintf("%s/api/v3/movie", rc.baseURL)
-	resp, err := doWithRetry(ctx, rc.client, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("X-Api-Key", rc.apiKey)
-		req.Header.Set("Accept", "application/json")
-		return req, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	var movies []radarrMovie
-	if err := json.NewDecoder(resp.Body).Decode(&movies); err != nil {
-		return nil, err
-	}
-
-	return movies, nil
+	return fetchArrList[radarrMovie](ctx, rc.client, url, rc.apiKey)
 }
 
 func (rc *RadarrCollector) fetchMovieFiles(ctx context.Context, movieID int) ([]radarrMovieFile, error) {
 	url := fmt.Sprintf("%s/api/v3/moviefile?movieId=%d", rc.baseURL, movieID)
-	resp, err := doWithRetry(ctx, rc.client, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("X-Api-Key", rc.apiKey)
-		req.Header.Set("Accept", "application/json")
-		return req, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
+	return fetchArrList[radarrMovieFile](ctx, rc.client, url, rc.apiKey)
+}
 
-	var files []radarrMovieFile
-	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+func (rc *RadarrCollector) FetchRootFolders(ctx context.Context) ([]models.RootFolder, error) {
+	url := fmt.Sprintf("%s/api/v3/rootfolder", rc.baseURL)
+	folders, err := fetchArrList[arrRootFolder](ctx, rc.client, url, rc.apiKey)
+	if err != nil {
 		return nil, err
 	}
-
-	return files, nil
+	return toRootFolders(folders), nil
 }
 
 type radarrMovie struct {
@@ -160,9 +218,15 @@ type radarrMovie struct {
 	Monitored bool   `json:"monitored"`
 }
 
+type radarrHistoryRecord struct {
+	MovieID   int    `json:"movieId"`
+	EventType string `json:"eventType"`
+}
+
 type radarrMovieFile struct {
 	ID        int       `json:"id"`
 	MovieID   int       `json:"movieId"`
 	Path      string    `json:"path"`
 	DateAdded time.Time `json:"dateAdded"`
+	Size      int64     `json:"size"`
 }
@@ -2,6 +2,7 @@ package collectors
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -15,8 +16,9 @@ const httpRetryAttempts = 3
 
 // doWithRetry runs an idempotent request, rebuilt fresh by newReq on each
 // attempt, retrying on network errors or HTTP 5xx with a short linear backoff.
-// Non-5xx responses (2xx/4xx) are returned to the caller to handle, and the
-// caller owns closing the response body. The backoff respects ctx cancellation.
+// A request refused by the circuit breaker is not retried. Non-5xx responses
+// (2xx/4xx) are returned to the caller to handle, and the caller owns closing
+// the response body. The backoff respects ctx cancellation.
 func doWithRetry(ctx context.Context, client *http.Client, newReq func() (*http.Request, error)) (*http.Response, error) {
 	var lastErr error
 	for attempt := 1; attempt <= httpRetryAttempts; attempt++ {
@@ -27,6 +29,8 @@ func doWithRetry(ctx context.Context, client *http.Client, newReq func() (*http.
 
 		resp, err := client.Do(req)
 		switch {
+		case errors.Is(err, ErrCircuitOpen):
+			return nil, err
 		case err != nil:
 			lastErr = err
 		case resp.StatusCode >= 500:
@@ -2,18 +2,28 @@ package collectors
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/jdpx/auditarr/internal/models"
 )
 
+// sonarrSeriesBatch is how many series are requested per bulk episodefile
+// call, keeping the query string well under common URL length limits.
+const sonarrSeriesBatch = 100
+
 type SonarrCollector struct {
 	client  *http.Client
 	baseURL string
 	apiKey  string
+	breaker *breakerTransport
+	failed  []string
 }
 
 func NewSonarrCollector(baseURL, apiKey string) *SonarrCollector {
@@ -26,6 +36,42 @@ func NewSonarrCollector(baseURL, apiKey string) *SonarrCollector {
 	}
 }
 
+// SetAuth sends basic-auth credentials and extra headers with every request.
+func (sc *SonarrCollector) SetAuth(username, password string, headers map[string]string) {
+	withAuth(sc.client, username, password, headers)
+}
+
+// SetRootCAs verifies the server's certificate against pool.
+func (sc *SonarrCollector) SetRootCAs(pool *x509.CertPool) {
+	withRootCAs(sc.client, pool)
+}
+
+// SetTransport sends requests through transport, e.g. the shared [http] one.
+func (sc *SonarrCollector) SetTransport(transport http.RoundTripper) {
+	withTransport(sc.client, transport)
+}
+
+// SetRequestTimeout bounds each request; zero keeps the 30s default.
+func (sc *SonarrCollector) SetRequestTimeout(timeout time.Duration) {
+	withRequestTimeout(sc.client, timeout)
+}
+
+// SetCircuitBreaker skips further requests after threshold consecutive
+// failures. Call it after SetAuth and SetRootCAs or SetTransport.
+func (sc *SonarrCollector) SetCircuitBreaker(threshold int) {
+	sc.breaker = withCircuitBreaker(sc.client, threshold)
+}
+
+// SkippedRequests returns how many requests the circuit breaker refused.
+func (sc *SonarrCollector) SkippedRequests() int {
+	return sc.breaker.Skipped()
+}
+
+// FailedFetches describes the per-item requests that failed and were skipped.
+func (sc *SonarrCollector) FailedFetches() []string {
+	return sc.failed
+}
+
 func (sc *SonarrCollector) Name() string {
 	return "sonarr"
 }
@@ -46,15 +92,16 @@ func (sc *SonarrCollector) TestConnection(ctx context.Context) error {
 
 	resp, err := sc.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+		return unreachableError(err)
 	}
-	defer resp.Body.Close()
+	defer func() {
+		// Drain the body so the connection goes back to the pool.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("authentication failed (invalid API key)")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API returned status %d", resp.StatusCode)
+		return statusError(resp.StatusCode, "authentication failed (invalid API key)")
 	}
 
 	return nil
@@ -65,94 +112,188 @@ func (sc *SonarrCollector) Collect(ctx context.Context) ([]models.ArrFile, error
 		return nil, nil
 	}
 
-	var arrFiles []models.ArrFile
-
 	seriesList, err := sc.fetchSeries(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch series: %w", err)
 	}
 
+	ids := make([]int, 0, len(seriesList))
 	for _, series := range seriesList {
-		select {
-		case <-ctx.Done():
-			return arrFiles, ctx.Err()
-		default:
+		ids = append(ids, series.ID)
+	}
+	return sc.collectSeries(ctx, ids, nil)
+}
+
+// CollectSince refetches episode files only for series with file history
+// since the given time and reuses cached for the rest. Series no longer in
+// Sonarr are dropped. Files added without a history record, e.g. by a disk
+// rescan, are only seen by the next full Collect.
+func (sc *SonarrCollector) CollectSince(ctx context.Context, since time.Time, cached []models.ArrFile) ([]models.ArrFile, error) {
+	if sc.baseURL == "" {
+		return nil, nil
+	}
+
+	seriesList, err := sc.fetchSeries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch series: %w", err)
+	}
+	history, err := fetchArrHistorySince[sonarrHistoryRecord](ctx, sc.client, sc.baseURL, sc.apiKey, since)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[int]bool, len(history))
+	for _, h := range history {
+		if changesFiles(h.EventType) {
+			changed[h.SeriesID] = true
 		}
+	}
+
+	bySeries := make(map[int][]models.ArrFile)
+	for _, f := range cached {
+		bySeries[f.SeriesID] = append(bySeries[f.SeriesID], f)
+	}
 
-		episodeFiles, err := sc.fetchEpisodeFiles(ctx, series.ID)
-		if err != nil {
-			fmt.Printf("Warning: failed to fetch episode files for series %d: %v\n", series.ID, err)
+	var reused []models.ArrFile
+	var refetch []int
+	for _, series := range seriesList {
+		if changed[series.ID] {
+			refetch = append(refetch, series.ID)
 			continue
 		}
+		reused = append(reused, bySeries[series.ID]...)
+	}
+	return sc.collectSeries(ctx, refetch, reused)
+}
 
-		for _, ef := range episodeFiles {
+// collectSeries fetches the episode files of the given series, batching
+// them into bulk requests where Sonarr supports it, and appends them to
+// arrFiles.
+func (sc *SonarrCollector) collectSeries(ctx context.Context, ids []int, arrFiles []models.ArrFile) ([]models.ArrFile, error) {
+	appendFiles := func(files []sonarrEpisodeFile, seriesID int) {
+		for _, ef := range files {
+			id := ef.SeriesID
+			if seriesID != 0 {
+				id = seriesID
+			}
 			arrFiles = append(arrFiles, models.ArrFile{
 				Path:       ef.Path,
-				SeriesID:   series.ID,
+				SeriesID:   id,
 				EpisodeID:  ef.ID,
 				Monitored:  ef.Monitored,
 				ImportDate: ef.DateAdded,
+				Size:       ef.Size,
 			})
 		}
 	}
 
-	return arrFiles, nil
-}
+	bulk := true
+	for start := 0; start < len(ids); start += sonarrSeriesBatch {
+		select {
+		case <-ctx.Done():
+			return arrFiles, ctx.Err()
+		default:
+		}
 
-func (sc *SonarrCollector) fetchSeries(ctx context.Context) ([]sonarrSeries, error) {
-	url := fmt.Sprintf("%s/api/v3/series", sc.baseURL)
-	resp, err := doWithRetry(ctx, sc.client, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
+		batch := ids[start:min(start+sonarrSeriesBatch, len(ids))]
+		if bulk && len(batch) > 1 {
+			files, err := sc.fetchEpisodeFilesBulk(ctx, batch)
+			if err == nil {
+				appendFiles(files, 0)
+				continue
+			}
+			// An empty batch can't show whether bulk requests work, so
+			// only this batch is fetched per series. Otherwise older
+			// Sonarr rejects repeated seriesId parameters or only honours
+			// the first; fetch per series from here on.
+			if !errors.Is(err, errBulkUnverified) {
+				bulk = false
+			}
 		}
-		req.Header.Set("X-Api-Key", sc.apiKey)
-		req.Header.Set("Accept", "application/json")
-		return req, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
+		for _, id := range batch {
+			select {
+			case <-ctx.Done():
+				return arrFiles, ctx.Err()
+			default:
+			}
 
-	var series []sonarrSeries
-	if err := json.NewDecoder(resp.Body).Decode(&series); err != nil {
-		return nil, err
+			episodeFiles, err := sc.fetchEpisodeFiles(ctx, id)
+			if err != nil {
+				if !errors.Is(err, ErrCircuitOpen) {
+					fmt.Printf("Warning: failed to fetch episode files for series %d: %v\n", id, err)
+					sc.failed = append(sc.failed, fmt.Sprintf("episode files for series %d: %v", id, err))
+				}
+				continue
+			}
+			appendFiles(episodeFiles, id)
+		}
 	}
 
-	return series, nil
+	return arrFiles, nil
+}
+
+func (sc *SonarrCollector) fetchSeries(ctx context.Context) ([]sonarrSeries, error) {
+	url := fmt.Sprintf("%s/api/v3/series", sc.baseURL)
+	return fetchArrList[sonarrSeries](ctx, sc.client, url, sc.apiKey)
 }
 
 func (sc *SonarrCollector) fetchEpisodeFiles(ctx context.Context, seriesID int) ([]sonarrEpisodeFile, error) {
 	url := fmt.Sprintf("%s/api/v3/episodefile?seriesId=%d", sc.baseURL, seriesID)
-	resp, err := doWithRetry(ctx, sc.client, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("X-Api-Key", sc.apiKey)
-		req.Header.Set("Accept", "application/json")
-		return req, nil
-	})
+	return fetchArrList[sonarrEpisodeFile](ctx, sc.client, url, sc.apiKey)
+}
+
+// errBulkUnverified is returned for an empty bulk episodefile response,
+// which Sonarr versions that bind only the first seriesId also give.
+var errBulkUnverified = errors.New("empty bulk episodefile response can't be verified")
+
+// fetchEpisodeFilesBulk fetches episode files for several series in one
+// request by repeating seriesId. Versions that bind only the first value
+// would silently drop the rest, so a response whose files all belong to the
+// first series, or that lack seriesId, is treated as unsupported, and an
+// empty one as unverified.
+func (sc *SonarrCollector) fetchEpisodeFilesBulk(ctx context.Context, seriesIDs []int) ([]sonarrEpisodeFile, error) {
+	q := url.Values{}
+	for _, id := range seriesIDs {
+		q.Add("seriesId", strconv.Itoa(id))
+	}
+	endpoint := fmt.Sprintf("%s/api/v3/episodefile?%s", sc.baseURL, q.Encode())
+	files, err := fetchArrList[sonarrEpisodeFile](ctx, sc.client, endpoint, sc.apiKey)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	if len(files) == 0 {
+		// Versions that bind only the first seriesId answer with nothing
+		// when that series has no files, so an empty response says nothing
+		// about the rest of the batch.
+		return nil, errBulkUnverified
+	}
+	requested := make(map[int]bool, len(seriesIDs))
+	for _, id := range seriesIDs {
+		requested[id] = true
+	}
+	onlyFirst := true
+	for _, f := range files {
+		if !requested[f.SeriesID] {
+			return nil, fmt.Errorf("bulk episodefile response contains unrequested series %d", f.SeriesID)
+		}
+		if f.SeriesID != seriesIDs[0] {
+			onlyFirst = false
+		}
+	}
+	if onlyFirst {
+		return nil, fmt.Errorf("bulk episodefile response only covers the first series")
 	}
+	return files, nil
+}
 
-	var files []sonarrEpisodeFile
-	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+func (sc *SonarrCollector) FetchRootFolders(ctx context.Context) ([]models.RootFolder, error) {
+	url := fmt.Sprintf("%s/api/v3/rootfolder", sc.baseURL)
+	folders, err := fetchArrList[arrRootFolder](ctx, sc.client, url, sc.apiKey)
+	if err != nil {
 		return nil, err
 	}
-
-	return files, nil
+	return toRootFolders(folders), nil
 }
 
 type sonarrSeries struct {
@@ -161,10 +302,16 @@ type sonarrSeries struct {
 	Monitored bool   `json:"monitored"`
 }
 
+type sonarrHistoryRecord struct {
+	SeriesID  int    `json:"seriesId"`
+	EventType string `json:"eventType"`
+}
+
 type sonarrEpisodeFile struct {
 	ID        int       `json:"id"`
 	SeriesID  int       `json:"seriesId"`
 	Path      string    `json:"path"`
 	Monitored bool      `json:"monitored"`
 	DateAdded time.Time `json:"dateAdded"`
+	Size      int64     `json:"size"`
 }
@@ -0,0 +1,100 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func sonarrServer(t *testing.T, bulk bool, requests *int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/series", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]sonarrSeries{{ID: 1}, {ID: 2}, {ID: 3}})
+	})
+	mux.HandleFunc("/api/v3/episodefile", func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		ids := r.URL.Query()["seriesId"]
+		if len(ids) > 1 && !bulk {
+			http.Error(w, "seriesId must be a single value", http.StatusBadRequest)
+			return
+		}
+		var files []sonarrEpisodeFile
+		for _, s := range ids {
+			id, _ := strconv.Atoi(s)
+			files = append(files, sonarrEpisodeFile{ID: id * 10, SeriesID: id, Path: "/tv/" + s + ".mkv"})
+		}
+		_ = json.NewEncoder(w).Encode(files)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSonarrCollector_BulkEpisodeFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		bulk     bool
+		requests int
+	}{
+		{"bulk supported", true, 1},
+		{"falls back per series", false, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			srv := sonarrServer(t, tc.bulk, &requests)
+
+			files, err := NewSonarrCollector(srv.URL, "key").Collect(context.Background())
+			if err != nil {
+				t.Fatalf("Collect: %v", err)
+			}
+			if len(files) != 3 {
+				t.Fatalf("got %d files, want 3: %+v", len(files), files)
+			}
+			for _, f := range files {
+				if f.EpisodeID != f.SeriesID*10 {
+					t.Errorf("file %s attributed to series %d", f.Path, f.SeriesID)
+				}
+			}
+			if requests != tc.requests {
+				t.Errorf("made %d episodefile requests, want %d", requests, tc.requests)
+			}
+		})
+	}
+}
+
+// Older Sonarr binds only the first seriesId. When that series has no files
+// the bulk response is empty, which must not be taken to mean the whole
+// batch has none.
+func TestSonarrCollector_BulkFirstSeriesEmpty(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/series", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]sonarrSeries{{ID: 1}, {ID: 2}, {ID: 3}})
+	})
+	mux.HandleFunc("/api/v3/episodefile", func(w http.ResponseWriter, r *http.Request) {
+		first := r.URL.Query().Get("seriesId")
+		var files []sonarrEpisodeFile
+		if first != "1" {
+			id, _ := strconv.Atoi(first)
+			files = append(files, sonarrEpisodeFile{ID: id * 10, SeriesID: id, Path: "/tv/" + first + ".mkv"})
+		}
+		_ = json.NewEncoder(w).Encode(files)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	files, err := NewSonarrCollector(srv.URL, "key").Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	got := make(map[int]bool)
+	for _, f := range files {
+		got[f.SeriesID] = true
+	}
+	if len(files) != 2 || !got[2] || !got[3] {
+		t.Errorf("files = %+v, want series 2 and 3 fetched per series", files)
+	}
+}
@@ -1,33 +1,166 @@
 package config
 
 import (
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"net/http"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/collectors"
+	"github.com/jdpx/auditarr/internal/utils"
 )
 
 type Config struct {
-	Paths         PathsConfig        `toml:"paths"`
-	Sonarr        ArrConfig          `toml:"sonarr"`
-	Radarr        ArrConfig          `toml:"radarr"`
-	Qbittorrent   QBConfig           `toml:"qbittorrent"`
-	Notifications NotificationConfig `toml:"notifications"`
-	Outputs       OutputConfig       `toml:"outputs"`
-	Suspicious    SuspiciousConfig   `toml:"suspicious"`
-	Permissions   PermissionsConfig  `toml:"permissions"`
-	PathMappings  map[string]string  `toml:"path_mappings"`
+	Paths         PathsConfig         `toml:"paths"`
+	Sonarr        ArrConfig           `toml:"sonarr"`
+	Radarr        ArrConfig           `toml:"radarr"`
+	Arr           []ArrInstanceConfig `toml:"arr"`
+	Qbittorrent   QBConfig            `toml:"qbittorrent"`
+	HTTP          HTTPConfig          `toml:"http"`
+	Notifications NotificationConfig  `toml:"notifications"`
+	Outputs       OutputConfig        `toml:"outputs"`
+	Suspicious    SuspiciousConfig    `toml:"suspicious"`
+	Permissions   PermissionsConfig   `toml:"permissions"`
+	Verify        VerifyConfig        `toml:"verify"`
+	Performance   PerformanceConfig   `toml:"performance"`
+	Analysis      AnalysisConfig      `toml:"analysis"`
+	PathMappings  map[string]string   `toml:"path_mappings"`
+
+	// DefaultPathMappings is set when path_mappings was empty and the
+	// /data/media and /data/torrents defaults were applied.
+	DefaultPathMappings bool `toml:"-"`
 }
 
 type PathsConfig struct {
 	MediaRoot      string   `toml:"media_root"`
 	TorrentRoot    string   `toml:"torrent_root"`
 	ExtraScanPaths []string `toml:"extra_scan_paths"`
+	FollowSymlinks bool     `toml:"follow_symlinks"`
+	// MinFileAgeSeconds skips files modified within this many seconds during
+	// collection, so files still being written are never analyzed.
+	MinFileAgeSeconds int `toml:"min_file_age_seconds"`
+	// MaxDepth limits how many levels below each root are walked; 0 means
+	// unlimited.
+	MaxDepth int `toml:"max_depth"`
+	// CheckpointFile records scan progress per top-level directory so an
+	// interrupted scan can continue with scan --resume. Empty disables it.
+	CheckpointFile string `toml:"checkpoint_file"`
+	// ArrCacheFile stores each Arr service's tracked files between runs so
+	// Sonarr and Radarr are collected incrementally from their history.
+	// Empty disables it. ArrFullRefreshHours forces a full collection when
+	// the last one is older; unset means 24, negative never forces one.
+	ArrCacheFile        string `toml:"arr_cache_file"`
+	ArrFullRefreshHours int    `toml:"arr_full_refresh_hours"`
+	// SnapshotMediaRoot and SnapshotTorrentRoot are read-only snapshots
+	// (e.g. ZFS or btrfs) of media_root and torrent_root that are walked in
+	// their place. Files are still reported under the live roots, so path
+	// mappings and Arr's paths apply unchanged.
+	SnapshotMediaRoot   string `toml:"snapshot_media_root"`
+	SnapshotTorrentRoot string `toml:"snapshot_torrent_root"`
+	// IgnoreMarkers are file names that exclude the directory holding one,
+	// and everything below it, from the walk. Unset means
+	// DefaultIgnoreMarkers; an empty list turns markers off.
+	IgnoreMarkers []string `toml:"ignore_markers"`
+	// DefaultMappings applies the /data/media and /data/torrents path
+	// mappings when path_mappings is empty. Unset means true; false leaves
+	// Arr paths unmapped.
+	DefaultMappings *bool `toml:"default_path_mappings"`
+	// WalkRetries is how many times a directory is re-read after a
+	// transient error (EINTR, ESTALE, EIO...) before the walk fails. Unset
+	// means 3; negative disables retries.
+	WalkRetries int `toml:"walk_retries"`
+}
+
+// DefaultMappingsEnabled reports whether the built-in path mappings apply
+// when none are configured.
+func (p PathsConfig) DefaultMappingsEnabled() bool {
+	return p.DefaultMappings == nil || *p.DefaultMappings
+}
+
+// ScanRoots returns the directories the filesystem walk reads for the media
+// and torrent roots: the snapshot when one is configured, else the live root.
+func (p PathsConfig) ScanRoots() (media, torrent string) {
+	media, torrent = p.MediaRoot, p.TorrentRoot
+	if p.SnapshotMediaRoot != "" {
+		media = p.SnapshotMediaRoot
+	}
+	if p.SnapshotTorrentRoot != "" {
+		torrent = p.SnapshotTorrentRoot
+	}
+	return media, torrent
 }
 
 type ArrConfig struct {
 	URL        string `toml:"url"`
 	APIKey     string `toml:"api_key"`
 	GraceHours int    `toml:"grace_hours"`
+	// ExcludeRootFolders lists Arr root folders (as the Arr service reports
+	// them) whose contents are left out of the audit entirely.
+	ExcludeRootFolders []string `toml:"exclude_root_folders"`
+	// Username, Password and Headers are sent with every request in addition
+	// to the API key, for instances behind a forward-auth proxy.
+	Username string            `toml:"username"`
+	Password string            `toml:"password"`
+	Headers  map[string]string `toml:"headers"`
+	// APIRoot and FSRoot are one directory as the service and auditarr see
+	// it. When set, a path mapping is derived for each of the service's root
+	// folders under APIRoot instead of having to list them in path_mappings.
+	APIRoot string `toml:"api_root"`
+	FSRoot  string `toml:"fs_root"`
+}
+
+// RootFolderMappings maps each root folder under c.APIRoot to the matching
+// directory under c.FSRoot. Folders outside APIRoot are returned as skipped.
+func (c ArrConfig) RootFolderMappings(rootFolders []string) (mappings map[string]string, skipped []string) {
+	if c.APIRoot == "" || c.FSRoot == "" {
+		return nil, nil
+	}
+	apiRoot := path.Clean(c.APIRoot)
+	mappings = make(map[string]string)
+	for _, folder := range rootFolders {
+		folder = path.Clean(folder)
+		rel, ok := strings.CutPrefix(folder, apiRoot)
+		if !ok || (rel != "" && !strings.HasPrefix(rel, "/") && apiRoot != "/") {
+			skipped = append(skipped, folder)
+			continue
+		}
+		mappings[folder] = filepath.Join(c.FSRoot, filepath.FromSlash(rel))
+	}
+	return mappings, skipped
+}
+
+// AddPathMappings adds derived mappings, keeping any path_mappings entry for
+// the same API path. Once any is added the /data defaults no longer stand
+// alone, so DefaultPathMappings is cleared.
+func (c *Config) AddPathMappings(mappings map[string]string) {
+	for apiPath, fsPath := range mappings {
+		if _, exists := c.PathMappings[apiPath]; exists {
+			continue
+		}
+		if c.PathMappings == nil {
+			c.PathMappings = make(map[string]string)
+		}
+		c.PathMappings[apiPath] = fsPath
+		c.DefaultPathMappings = false
+	}
+}
+
+// ArrInstanceConfig configures an additional *arr service of any supported
+// kind (sonarr, radarr, whisparr, lidarr) via an [[arr]] table.
+type ArrInstanceConfig struct {
+	Kind string `toml:"kind"`
+	// Name labels the instance in reports; defaults to the kind.
+	Name string `toml:"name"`
+	ArrConfig
 }
 
 type QBConfig struct {
@@ -35,19 +168,197 @@ type QBConfig struct {
 	Username   string `toml:"username"`
 	Password   string `toml:"password"`
 	GraceHours int    `toml:"grace_hours"`
+	// BackupDir is qBittorrent's BT_backup directory. Its .fastresume files
+	// are read when url is empty or the WebUI can't be reached.
+	BackupDir string `toml:"backup_dir"`
+	// IncludeUnknownState includes torrents in a state auditarr doesn't
+	// recognize in unlinked torrent analysis, treating them as completed.
+	IncludeUnknownState bool `toml:"include_unknown_state"`
+	// CategoryGraceHours overrides grace_hours for torrents in the named
+	// qBittorrent categories in unlinked torrent analysis.
+	CategoryGraceHours map[string]int `toml:"category_grace_hours"`
 }
 
 type NotificationConfig struct {
 	DiscordWebhook string `toml:"discord_webhook"`
+	// DiscordWebhooks route messages by severity, in addition to
+	// discord_webhook which receives every run that is notified.
+	DiscordWebhooks []DiscordWebhookConfig `toml:"discord_webhooks"`
+	// DiscordAttachReport uploads the markdown report with the message
+	// instead of only referencing its server-side path.
+	DiscordAttachReport bool `toml:"discord_attach_report"`
+	// OnlyOnIssues skips notifications for runs without any warning- or
+	// error-level findings.
+	OnlyOnIssues bool `toml:"only_on_issues"`
+	// MinSeverity (info, warning, error) skips notifications unless the run's
+	// most severe finding reaches it. Empty notifies on every run.
+	MinSeverity string `toml:"min_severity"`
+	// Orphan and at-risk thresholds scale severity with the count instead of
+	// escalating on the first file: at or above warn is a warning, at or
+	// above critical an error, and any count below warn only info. Unset
+	// (0) keeps the defaults: any orphan is an error, any at-risk file a
+	// warning.
+	OrphanWarnThreshold     int `toml:"orphan_warn_threshold"`
+	OrphanCriticalThreshold int `toml:"orphan_critical_threshold"`
+	AtRiskWarnThreshold     int `toml:"at_risk_warn_threshold"`
+	AtRiskCriticalThreshold int `toml:"at_risk_critical_threshold"`
+	// TimeoutSeconds bounds each notifier's send. Notifiers are sent to
+	// concurrently, so a hung webhook only loses its own message.
+	TimeoutSeconds int `toml:"timeout_seconds"`
+	// QuietHours holds watch mode's notifications during a daily window.
+	QuietHours QuietHoursConfig `toml:"quiet_hours"`
+}
+
+// QuietHoursConfig is a daily window, start to end as "HH:MM", in which watch
+// mode records findings in reports but sends no notifications. A window whose
+// end is before its start runs overnight. Timezone defaults to
+// outputs.timezone. With CatchUp, the latest held run is sent once the window
+// ends.
+type QuietHoursConfig struct {
+	Start    string `toml:"start"`
+	End      string `toml:"end"`
+	Timezone string `toml:"timezone"`
+	CatchUp  bool   `toml:"catch_up"`
+
+	startMinute int
+	endMinute   int
+	Location    *time.Location `toml:"-"`
+}
+
+// Enabled reports whether a quiet window is configured.
+func (q QuietHoursConfig) Enabled() bool {
+	return q.Start != ""
+}
+
+// Active reports whether t falls inside the quiet window.
+func (q QuietHoursConfig) Active(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	if q.Location != nil {
+		t = t.In(q.Location)
+	}
+	m := t.Hour()*60 + t.Minute()
+	if q.startMinute < q.endMinute {
+		return m >= q.startMinute && m < q.endMinute
+	}
+	return m >= q.startMinute || m < q.endMinute
+}
+
+// NextEnd returns the first end of the quiet window after t.
+func (q QuietHoursConfig) NextEnd(t time.Time) time.Time {
+	if q.Location != nil {
+		t = t.In(q.Location)
+	}
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.endMinute/60, q.endMinute%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(value, field string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a 24-hour time like \"22:30\" (got %q)", field, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// DiscordWebhookConfig is one [[notifications.discord_webhooks]] entry. Runs
+// whose most severe finding is below MinSeverity are not sent to it.
+type DiscordWebhookConfig struct {
+	URL         string `toml:"url"`
+	MinSeverity string `toml:"min_severity"`
 }
 
 type OutputConfig struct {
-	ReportDir string `toml:"report_dir"`
+	// ReportDir is one directory or a list of them; each report is written
+	// to every entry.
+	ReportDir       StringList `toml:"report_dir"`
+	ReportFile      string     `toml:"report_file"`
+	Timezone        string     `toml:"timezone"`
+	TimestampFormat string     `toml:"timestamp_format"`
+	SQLitePath      string     `toml:"sqlite_path"`
+	// Compress gzips reports from earlier runs in report_dir after each run.
+	Compress bool `toml:"compress"`
+	// ReportDirOverlap controls what happens when reports would be written
+	// inside a scanned root: "skip" (default) adds the report directory, or
+	// a fixed report file and its siblings, to permissions.skip_paths;
+	// "error" rejects the config.
+	ReportDirOverlap string `toml:"report_dir_overlap"`
+	// UseEmoji keeps emoji status markers in reports and notifications;
+	// false swaps them for plain labels like [OK] and [WARN]. Unset means true.
+	UseEmoji *bool `toml:"use_emoji"`
+	// Fingerprint records a per-file fingerprint in the JSON report: "stat"
+	// (size and mtime), "sample" (hash of both ends) or "full" (whole-file
+	// hash). Empty disables it.
+	Fingerprint string `toml:"fingerprint"`
+	// MarkdownVersion pins the markdown report layout. 1 is the original
+	// layout without the sections added since; 0 selects the current layout.
+	MarkdownVersion int `toml:"markdown_version"`
+	// SARIF also writes orphaned, at-risk, suspicious and permission
+	// findings as a SARIF 2.1.0 log (.sarif) next to each JSON report.
+	SARIF bool `toml:"sarif"`
+	// MaxSectionEntries caps the rows each markdown section lists, with a
+	// banner saying how many were left out. Unset means 5000; negative
+	// lists everything.
+	MaxSectionEntries int `toml:"max_section_entries"`
+
+	Location *time.Location `toml:"-"`
+}
+
+// EmojiEnabled reports whether output should use emoji markers.
+func (o OutputConfig) EmojiEnabled() bool {
+	return o.UseEmoji == nil || *o.UseEmoji
+}
+
+// DefaultMaxSectionEntries is the markdown section cap when
+// outputs.max_section_entries is unset.
+const DefaultMaxSectionEntries = 5000
+
+// CurrentMarkdownVersion is the markdown report layout used when
+// outputs.markdown_version is unset.
+const CurrentMarkdownVersion = 2
+
+// DefaultTimestampFormat renders local time with the zone abbreviation so
+// reports read unambiguously across time zones.
+const DefaultTimestampFormat = "2006-01-02 15:04:05 MST"
+
+// Now returns the current time in the configured report time zone.
+func (o OutputConfig) Now() time.Time {
+	if o.Location == nil {
+		return time.Now()
+	}
+	return time.Now().In(o.Location)
+}
+
+// FormatTimestamp renders t in the configured zone and timestamp format.
+func (o OutputConfig) FormatTimestamp(t time.Time) string {
+	if o.Location != nil {
+		t = t.In(o.Location)
+	}
+	format := o.TimestampFormat
+	if format == "" {
+		format = DefaultTimestampFormat
+	}
+	return t.Format(format)
 }
 
 type SuspiciousConfig struct {
 	Extensions   []string `toml:"extensions"`
 	FlagArchives bool     `toml:"flag_archives"`
+	// Severities maps an extension to critical, warning or info; unlisted
+	// suspicious extensions are critical.
+	Severities map[string]string `toml:"severities"`
+	// KnownBadHashesFile lists SHA-256 hashes, one per line in sha256sum
+	// format or bare, that escalate a matching suspicious file to critical.
+	KnownBadHashesFile string `toml:"known_bad_hashes_file"`
+
+	// KnownBadHashes is the parsed form of KnownBadHashesFile, lowercased,
+	// populated by Validate.
+	KnownBadHashes map[string]bool `toml:"-"`
 }
 
 type PermissionsConfig struct {
@@ -57,6 +368,91 @@ type PermissionsConfig struct {
 	SGIDPaths           []string `toml:"sgid_paths"`
 	SkipPaths           []string `toml:"skip_paths"`
 	NonstandardSeverity string   `toml:"nonstandard_severity"`
+	ExpectedFileMode    string   `toml:"expected_file_mode"`
+	ExpectedDirMode     string   `toml:"expected_dir_mode"`
+
+	// FileMode and DirMode are the parsed forms of ExpectedFileMode and
+	// ExpectedDirMode, populated by Validate.
+	FileMode uint32 `toml:"-"`
+	DirMode  uint32 `toml:"-"`
+}
+
+type AnalysisConfig struct {
+	BaselineFile string       `toml:"baseline_file"`
+	Rules        []RuleConfig `toml:"rules"`
+	// ProtectedPaths are scanned but always reported healthy: directory
+	// prefixes or globs for curated content that legitimately isn't in Arr.
+	ProtectedPaths []string `toml:"protected_paths"`
+	// OrphanIgnoreExtensions lists extensions (e.g. ".txt", ".sfv") of
+	// non-media files that are reported under Other Files instead of as
+	// orphans when Arr doesn't track them.
+	OrphanIgnoreExtensions []string `toml:"orphan_ignore_extensions"`
+	// ExpectedUntrackedPaths are directory prefixes or globs of files kept
+	// out of Arr on purpose. Untracked files there are listed as Expected
+	// Untracked instead of counting as orphans.
+	ExpectedUntrackedPaths []string `toml:"expected_untracked_paths"`
+	// MaxExpectedHardlinks flags files with more links than this as a
+	// suspicious hardlink count, a sign of a runaway import script. Zero
+	// disables the check.
+	MaxExpectedHardlinks int `toml:"max_expected_hardlinks"`
+	// Reflinks counts copy-on-write clones as protected like hardlinks, for
+	// btrfs/XFS libraries imported by reflink: "detect" compares each
+	// unlinked library file's extents with its same-name download, "assume"
+	// treats every library file as reflinked. Empty requires hardlinks.
+	Reflinks string `toml:"reflinks"`
+}
+
+// RuleConfig is a custom classification rule. When is a condition over the
+// file's size, age_days, age_hours, nlink, hardlinked, hidden, tracked,
+// source, path, name and ext; Classification is the outcome when it matches
+// (or "ignore" to drop the file from the report).
+type RuleConfig struct {
+	Name           string `toml:"name"`
+	When           string `toml:"when"`
+	Classification string `toml:"classification"`
+}
+
+// HTTPConfig applies to every outgoing HTTP client: the Arr services,
+// qBittorrent and notifiers.
+type HTTPConfig struct {
+	// CACertFile is a PEM bundle of extra CAs trusted alongside the system
+	// roots, for services with certificates from an internal CA.
+	CACertFile string `toml:"ca_cert_file"`
+
+	// MaxIdleConnsPerHost is how many idle connections to each service are
+	// kept for reuse; zero keeps Go's default of 2. IdleConnTimeoutSeconds
+	// closes idle connections after that long (zero: 90s), and
+	// DisableKeepAlives opens a new connection for every request.
+	MaxIdleConnsPerHost    int  `toml:"max_idle_conns_per_host"`
+	IdleConnTimeoutSeconds int  `toml:"idle_conn_timeout_seconds"`
+	DisableKeepAlives      bool `toml:"disable_keep_alives"`
+
+	RootCAs *x509.CertPool `toml:"-"`
+	// Transport is built by Validate from the settings above and shared by
+	// the service collectors, pooling connections per host. watch wraps it
+	// so each request counts as progress for the systemd watchdog.
+	Transport http.RoundTripper `toml:"-"`
+	// ArrRequestTimeout and ArrMaxFailures come from the scan flags and
+	// set the per-request timeout and circuit breaker threshold of the Arr
+	// clients.
+	ArrRequestTimeout time.Duration `toml:"-"`
+	ArrMaxFailures    int           `toml:"-"`
+}
+
+type VerifyConfig struct {
+	SampleSize     int `toml:"sample_size"`
+	Concurrency    int `toml:"concurrency"`
+	TimeoutSeconds int `toml:"timeout_seconds"`
+}
+
+// PerformanceConfig tunes every parallel stage of a run in one place.
+type PerformanceConfig struct {
+	// Workers is how many goroutines a parallel stage runs. 0 uses
+	// GOMAXPROCS. ffprobe uses it too unless verify.concurrency is set.
+	Workers int `toml:"workers"`
+	// QueueSize buffers the channels that feed and drain those workers. 0
+	// sizes them to the worker count.
+	QueueSize int `toml:"queue_size"`
 }
 
 func (c *Config) Validate() error {
@@ -64,6 +460,37 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("paths.media_root is required")
 	}
 
+	if err := c.resolvePaths(); err != nil {
+		return err
+	}
+
+	switch c.Outputs.Fingerprint {
+	case "", "stat", "sample", "full":
+	default:
+		return fmt.Errorf("outputs.fingerprint must be one of stat, sample, full (got %q)", c.Outputs.Fingerprint)
+	}
+
+	for i, dir := range c.Outputs.ReportDir {
+		if dir == "" {
+			return fmt.Errorf("outputs.report_dir[%d] is empty", i)
+		}
+	}
+
+	switch c.Analysis.Reflinks {
+	case "", "detect", "assume":
+	default:
+		return fmt.Errorf("analysis.reflinks must be one of detect, assume (got %q)", c.Analysis.Reflinks)
+	}
+
+	switch c.Outputs.ReportDirOverlap {
+	case "", "skip", "error":
+	default:
+		return fmt.Errorf("outputs.report_dir_overlap must be one of skip, error (got %q)", c.Outputs.ReportDirOverlap)
+	}
+	if err := c.checkReportOverlap(); err != nil {
+		return err
+	}
+
 	if c.Sonarr.URL != "" {
 		if err := validateURL(c.Sonarr.URL, "sonarr.url"); err != nil {
 			return err
@@ -76,19 +503,224 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	arrRoots := []struct {
+		field string
+		cfg   ArrConfig
+	}{{"sonarr", c.Sonarr}, {"radarr", c.Radarr}}
+	for i, arr := range c.Arr {
+		arrRoots = append(arrRoots, struct {
+			field string
+			cfg   ArrConfig
+		}{fmt.Sprintf("arr[%d]", i), arr.ArrConfig})
+	}
+	for _, a := range arrRoots {
+		if (a.cfg.APIRoot == "") != (a.cfg.FSRoot == "") {
+			return fmt.Errorf("%s.api_root and %s.fs_root must be set together", a.field, a.field)
+		}
+	}
+
+	for i, arr := range c.Arr {
+		field := fmt.Sprintf("arr[%d]", i)
+		if !slices.Contains(collectors.ArrKinds, arr.Kind) {
+			return fmt.Errorf("%s.kind must be one of %s (got %q)", field, strings.Join(collectors.ArrKinds, ", "), arr.Kind)
+		}
+		if arr.URL == "" {
+			return fmt.Errorf("%s.url is required", field)
+		}
+		if err := validateURL(arr.URL, field+".url"); err != nil {
+			return err
+		}
+	}
+
 	if c.Qbittorrent.URL != "" {
 		if err := validateURL(c.Qbittorrent.URL, "qbittorrent.url"); err != nil {
 			return err
 		}
 	}
+	for category, hours := range c.Qbittorrent.CategoryGraceHours {
+		if hours < 0 {
+			return fmt.Errorf("qbittorrent.category_grace_hours[%q] must not be negative (got %d)", category, hours)
+		}
+	}
+
+	if c.Performance.Workers < 0 {
+		return fmt.Errorf("performance.workers must not be negative (got %d)", c.Performance.Workers)
+	}
+	if c.Performance.QueueSize < 0 {
+		return fmt.Errorf("performance.queue_size must not be negative (got %d)", c.Performance.QueueSize)
+	}
+
+	if c.Outputs.MarkdownVersion < 0 || c.Outputs.MarkdownVersion > CurrentMarkdownVersion {
+		return fmt.Errorf("outputs.markdown_version must be 0 (current) or between 1 and %d (got %d)", CurrentMarkdownVersion, c.Outputs.MarkdownVersion)
+	}
+
+	switch c.Notifications.MinSeverity {
+	case "", "info", "warning", "error":
+	default:
+		return fmt.Errorf("notifications.min_severity must be one of info, warning, error (got %q)", c.Notifications.MinSeverity)
+	}
+
+	thresholds := []struct {
+		name           string
+		warn, critical int
+	}{
+		{"orphan", c.Notifications.OrphanWarnThreshold, c.Notifications.OrphanCriticalThreshold},
+		{"at_risk", c.Notifications.AtRiskWarnThreshold, c.Notifications.AtRiskCriticalThreshold},
+	}
+	for _, t := range thresholds {
+		if t.warn < 0 || t.critical < 0 {
+			return fmt.Errorf("notifications.%s thresholds must be zero (unset) or positive", t.name)
+		}
+		if t.warn > 0 && t.critical > 0 && t.critical < t.warn {
+			return fmt.Errorf("notifications.%s_critical_threshold (%d) must not be below %s_warn_threshold (%d)", t.name, t.critical, t.name, t.warn)
+		}
+	}
+
+	for i, wh := range c.Notifications.DiscordWebhooks {
+		field := fmt.Sprintf("notifications.discord_webhooks[%d]", i)
+		if wh.URL == "" {
+			return fmt.Errorf("%s.url is required", field)
+		}
+		if err := validateURL(wh.URL, field+".url"); err != nil {
+			return err
+		}
+		switch wh.MinSeverity {
+		case "", "info", "warning", "error":
+		default:
+			return fmt.Errorf("%s.min_severity must be one of info, warning, error (got %q)", field, wh.MinSeverity)
+		}
+	}
+
+	if c.Paths.MaxDepth < 0 {
+		return fmt.Errorf("paths.max_depth must be zero (unlimited) or positive")
+	}
+
+	if c.Paths.SnapshotTorrentRoot != "" && c.Paths.TorrentRoot == "" {
+		return fmt.Errorf("paths.snapshot_torrent_root requires paths.torrent_root")
+	}
+
+	if c.Paths.MinFileAgeSeconds < 0 {
+		return fmt.Errorf("paths.min_file_age_seconds must be zero (disabled) or positive")
+	}
+
+	if c.Verify.SampleSize < 0 {
+		return fmt.Errorf("verify.sample_size must be zero (all files) or positive")
+	}
 
 	if c.Permissions.NonstandardSeverity == "" {
 		c.Permissions.NonstandardSeverity = "warning"
 	}
 
+	switch c.Permissions.NonstandardSeverity {
+	case "info", "warning", "error":
+	default:
+		return fmt.Errorf("permissions.nonstandard_severity must be one of info, warning, error (got %q)", c.Permissions.NonstandardSeverity)
+	}
+
+	if c.Analysis.MaxExpectedHardlinks < 0 {
+		return fmt.Errorf("analysis.max_expected_hardlinks must be zero (disabled) or positive (got %d)", c.Analysis.MaxExpectedHardlinks)
+	}
+
+	for ext, severity := range c.Suspicious.Severities {
+		switch severity {
+		case "critical", "warning", "info":
+		default:
+			return fmt.Errorf("suspicious.severities[%q] must be one of critical, warning, info (got %q)", ext, severity)
+		}
+	}
+
+	if c.Suspicious.KnownBadHashesFile != "" {
+		hashes, err := loadHashList(os.ExpandEnv(c.Suspicious.KnownBadHashesFile))
+		if err != nil {
+			return fmt.Errorf("suspicious.known_bad_hashes_file: %w", err)
+		}
+		c.Suspicious.KnownBadHashes = hashes
+	}
+
+	if c.HTTP.CACertFile != "" {
+		pool, err := loadCertPool(os.ExpandEnv(c.HTTP.CACertFile))
+		if err != nil {
+			return fmt.Errorf("http.ca_cert_file: %w", err)
+		}
+		c.HTTP.RootCAs = pool
+	}
+	if c.HTTP.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("http.max_idle_conns_per_host must be zero (default) or positive")
+	}
+	if c.HTTP.IdleConnTimeoutSeconds < 0 {
+		return fmt.Errorf("http.idle_conn_timeout_seconds must be zero (default) or positive")
+	}
+	c.HTTP.Transport = utils.NewTransport(utils.TransportOptions{
+		RootCAs:             c.HTTP.RootCAs,
+		MaxIdleConnsPerHost: c.HTTP.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(c.HTTP.IdleConnTimeoutSeconds) * time.Second,
+		DisableKeepAlives:   c.HTTP.DisableKeepAlives,
+	})
+
+	c.Outputs.Location = time.Local
+	if c.Outputs.Timezone != "" {
+		loc, err := time.LoadLocation(c.Outputs.Timezone)
+		if err != nil {
+			return fmt.Errorf("outputs.timezone must be an IANA time zone name like \"Europe/London\": %w", err)
+		}
+		c.Outputs.Location = loc
+	}
+
+	if q := &c.Notifications.QuietHours; q.Start != "" || q.End != "" {
+		start, err := parseClock(q.Start, "notifications.quiet_hours.start")
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(q.End, "notifications.quiet_hours.end")
+		if err != nil {
+			return err
+		}
+		q.startMinute, q.endMinute = start, end
+		if q.startMinute == q.endMinute {
+			return fmt.Errorf("notifications.quiet_hours.start and end must differ")
+		}
+		q.Location = c.Outputs.Location
+		if q.Timezone != "" {
+			loc, err := time.LoadLocation(q.Timezone)
+			if err != nil {
+				return fmt.Errorf("notifications.quiet_hours.timezone must be an IANA time zone name like \"Europe/London\": %w", err)
+			}
+			q.Location = loc
+		}
+	}
+
+	fileMode, err := parseOctalMode(c.Permissions.ExpectedFileMode, "permissions.expected_file_mode")
+	if err != nil {
+		return err
+	}
+	dirMode, err := parseOctalMode(c.Permissions.ExpectedDirMode, "permissions.expected_dir_mode")
+	if err != nil {
+		return err
+	}
+	c.Permissions.FileMode = fileMode
+	c.Permissions.DirMode = dirMode
+
 	return nil
 }
 
+// parseOctalMode parses a permission string such as "0664", "664" or "0o664".
+// Only the permission bits (0777) are accepted; special bits like SGID are
+// audited separately.
+func parseOctalMode(s, field string) (uint32, error) {
+	if s == "" {
+		return 0, nil
+	}
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O")
+	mode, err := strconv.ParseUint(trimmed, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an octal mode like \"0664\" (got %q)", field, s)
+	}
+	if mode > 0777 {
+		return 0, fmt.Errorf("%s must only contain permission bits (0000-0777), got %q", field, s)
+	}
+	return uint32(mode), nil
+}
+
 func validateURL(u, field string) error {
 	if u == "" {
 		return nil
@@ -103,6 +735,69 @@ func validateURL(u, field string) error {
 	return nil
 }
 
+// loadCertPool returns the system roots plus the certificates in the PEM file
+// at path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// loadHashList reads SHA-256 hashes from path, one per line: bare or as in
+// sha256sum output ("<hash>  <name>"). Blank lines and # comments are skipped.
+func loadHashList(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		hash := strings.ToLower(fields[0])
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+			return nil, fmt.Errorf("line %d of %s is not a SHA-256 hash: %q", i+1, path, fields[0])
+		}
+		hashes[hash] = true
+	}
+	return hashes, nil
+}
+
+// StringList is a config value written either as a single string or as an
+// array of strings.
+type StringList []string
+
+func (l *StringList) UnmarshalTOML(v any) error {
+	switch x := v.(type) {
+	case string:
+		*l = StringList{x}
+	case []any:
+		list := make(StringList, 0, len(x))
+		for _, e := range x {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %T", e)
+			}
+			list = append(list, s)
+		}
+		*l = list
+	default:
+		return fmt.Errorf("expected a string or an array of strings, got %T", v)
+	}
+	return nil
+}
+
 func DefaultReportDir() string {
 	switch runtime.GOOS {
 	case "darwin":
@@ -114,6 +809,10 @@ func DefaultReportDir() string {
 	}
 }
 
+func DefaultIgnoreMarkers() []string {
+	return []string{".auditarr-ignore", ".nomedia"}
+}
+
 func DefaultSuspiciousExtensions() []string {
 	return []string{
 		".exe", ".msi", ".bat", ".cmd", ".com", ".scr",
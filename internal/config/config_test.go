@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOctalMode(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    uint32
+		wantErr string
+	}{
+		{"", 0, ""},
+		{"0664", 0664, ""},
+		{"664", 0664, ""},
+		{"0o775", 0775, ""},
+		{"0O775", 0775, ""},
+		{"0000", 0, ""},
+		{"0777", 0777, ""},
+		{"2775", 0, "only contain permission bits"},
+		{"0668", 0, "octal mode"},
+		{"rw-rw-r--", 0, "octal mode"},
+	} {
+		got, err := parseOctalMode(tc.in, "permissions.expected_file_mode")
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) || !strings.Contains(err.Error(), "permissions.expected_file_mode") {
+				t.Errorf("parseOctalMode(%q) error = %v, want one containing %q", tc.in, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("parseOctalMode(%q) = %04o, %v; want %04o", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	at := time.Date(2026, 7, 1, 12, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name, timezone, format, want string
+	}{
+		{"UTC", "UTC", "", "2026-07-01 12:30:00 UTC"},
+		{"named zone", "America/New_York", "", "2026-07-01 08:30:00 EDT"},
+		{"custom format", "Europe/London", "02 Jan 2006 15:04", "01 Jul 2026 13:30"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Paths: PathsConfig{MediaRoot: t.TempDir()}, Outputs: OutputConfig{Timezone: tc.timezone, TimestampFormat: tc.format}}
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if got := cfg.Outputs.FormatTimestamp(at); got != tc.want {
+				t.Errorf("FormatTimestamp = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	cfg := &Config{Paths: PathsConfig{MediaRoot: t.TempDir()}, Outputs: OutputConfig{Timezone: "Mars/Olympus_Mons"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "outputs.timezone") {
+		t.Errorf("invalid zone: err = %v, want an outputs.timezone error", err)
+	}
+}
+
+func TestValidate_MarkdownVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version int
+		valid   bool
+	}{
+		{-1, false},
+		{0, true},
+		{1, true},
+		{CurrentMarkdownVersion, true},
+		{CurrentMarkdownVersion + 1, false},
+	} {
+		cfg := &Config{Paths: PathsConfig{MediaRoot: t.TempDir()}, Outputs: OutputConfig{MarkdownVersion: tc.version}}
+		err := cfg.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("markdown_version = %d: %v", tc.version, err)
+		}
+		if !tc.valid && (err == nil || !strings.Contains(err.Error(), "must be 0 (current) or between 1 and")) {
+			t.Errorf("markdown_version = %d: err = %v, want the allowed range", tc.version, err)
+		}
+	}
+}
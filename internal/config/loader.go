@@ -2,20 +2,70 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/jdpx/auditarr/internal/utils"
 )
 
-func Load(path string) (*Config, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil, fmt.Errorf("config file not found: %s", path)
+// SearchPaths lists where Locate looks for a config file, in order:
+// ./auditarr.toml, $XDG_CONFIG_HOME/auditarr/config.toml,
+// ~/.config/auditarr/config.toml, then /etc/auditarr/config.toml.
+func SearchPaths() []string {
+	paths := []string{"auditarr.toml"}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "auditarr", "config.toml"))
 	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		paths = append(paths, filepath.Join(home, ".config", "auditarr", "config.toml"))
+	}
+	return append(paths, "/etc/auditarr/config.toml")
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// Locate returns path when set, otherwise the first of SearchPaths that
+// exists.
+func Locate(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	candidates := SearchPaths()
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no config file found (searched %s); pass --config", strings.Join(candidates, ", "))
+}
+
+// StdinPath is the config path that makes Load read the config from stdin.
+const StdinPath = "-"
+
+// stdin is where Load reads a StdinPath config from; tests replace it.
+var stdin io.Reader = os.Stdin
+
+// Load reads, defaults and validates the config at path, or from stdin when
+// path is StdinPath.
+func Load(path string) (*Config, error) {
+	var data []byte
+	var err error
+	if path == StdinPath {
+		data, err = io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config from stdin: %w", err)
+		}
+	} else {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("config file not found: %s", path)
+		}
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
 	}
 
 	var cfg Config
@@ -33,8 +83,23 @@ func Load(path string) (*Config, error) {
 }
 
 func (c *Config) applyDefaults() {
-	if c.Outputs.ReportDir == "" {
-		c.Outputs.ReportDir = DefaultReportDir()
+	if len(c.Outputs.ReportDir) == 0 {
+		c.Outputs.ReportDir = StringList{DefaultReportDir()}
+	}
+
+	if c.Outputs.MaxSectionEntries == 0 {
+		c.Outputs.MaxSectionEntries = DefaultMaxSectionEntries
+	}
+
+	if c.Paths.WalkRetries == 0 {
+		c.Paths.WalkRetries = 3
+	}
+	if c.Paths.ArrFullRefreshHours == 0 {
+		c.Paths.ArrFullRefreshHours = 24
+	}
+
+	if c.Paths.IgnoreMarkers == nil {
+		c.Paths.IgnoreMarkers = DefaultIgnoreMarkers()
 	}
 
 	if c.Sonarr.GraceHours == 0 {
@@ -45,6 +110,15 @@ func (c *Config) applyDefaults() {
 		c.Radarr.GraceHours = 24
 	}
 
+	for i := range c.Arr {
+		if c.Arr[i].GraceHours == 0 {
+			c.Arr[i].GraceHours = 24
+		}
+		if c.Arr[i].Name == "" {
+			c.Arr[i].Name = c.Arr[i].Kind
+		}
+	}
+
 	if c.Qbittorrent.GraceHours == 0 {
 		c.Qbittorrent.GraceHours = 12
 	}
@@ -53,15 +127,39 @@ func (c *Config) applyDefaults() {
 		c.Suspicious.Extensions = DefaultSuspiciousExtensions()
 	}
 
+	if c.Permissions.ExpectedFileMode == "" {
+		c.Permissions.ExpectedFileMode = "0664"
+	}
+
+	if c.Permissions.ExpectedDirMode == "" {
+		c.Permissions.ExpectedDirMode = "0775"
+	}
+
+	if c.Verify.Concurrency <= 0 {
+		c.Verify.Concurrency = 2
+		if c.Performance.Workers > 0 {
+			c.Verify.Concurrency = c.Performance.Workers
+		}
+	}
+
+	if c.Verify.TimeoutSeconds <= 0 {
+		c.Verify.TimeoutSeconds = 60
+	}
+
+	if c.Notifications.TimeoutSeconds <= 0 {
+		c.Notifications.TimeoutSeconds = 30
+	}
+
 	c.applyDefaultPathMappings()
 }
 
 func (c *Config) applyDefaultPathMappings() {
-	if len(c.PathMappings) > 0 {
+	if len(c.PathMappings) > 0 || !c.Paths.DefaultMappingsEnabled() {
 		return
 	}
 
 	c.PathMappings = make(map[string]string)
+	c.DefaultPathMappings = true
 
 	if c.Paths.MediaRoot != "" {
 		c.PathMappings["/data/media"] = c.Paths.MediaRoot
@@ -73,12 +171,25 @@ func (c *Config) applyDefaultPathMappings() {
 	}
 }
 
+// GetReportPath returns the first configured report directory.
 func (c *Config) GetReportPath() string {
-	reportDir := c.Outputs.ReportDir
-	if reportDir == "" {
-		reportDir = DefaultReportDir()
+	return c.GetReportPaths()[0]
+}
+
+// GetReportPaths returns every configured report directory with a leading
+// ~ or $HOME expanded.
+func (c *Config) GetReportPaths() []string {
+	if len(c.Outputs.ReportDir) == 0 {
+		return []string{expandHome(DefaultReportDir())}
+	}
+	dirs := make([]string, len(c.Outputs.ReportDir))
+	for i, d := range c.Outputs.ReportDir {
+		dirs[i] = expandHome(d)
 	}
+	return dirs
+}
 
+func expandHome(reportDir string) string {
 	if (len(reportDir) >= 1 && reportDir[:1] == "~") || (len(reportDir) >= 5 && reportDir[:5] == "$HOME") {
 		home, _ := os.UserHomeDir()
 		if home != "" {
@@ -92,3 +203,257 @@ func (c *Config) GetReportPath() string {
 
 	return reportDir
 }
+
+// absPath resolves a relative path against the working directory. Absolute
+// paths, empty values and ones expanded later ($VAR, ~) are returned as is,
+// and a trailing separator is kept since some paths are matched as prefixes.
+func absPath(p, field string) (string, error) {
+	if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "~") || strings.Contains(p, "$") {
+		return p, nil
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("%s: cannot resolve relative path %q: %w", field, p, err)
+	}
+	if strings.HasSuffix(p, string(filepath.Separator)) {
+		abs += string(filepath.Separator)
+	}
+	return abs, nil
+}
+
+// resolvePaths makes every configured filesystem path absolute, so behaviour
+// does not depend on the working directory and mappings compare against the
+// absolute paths the scan produces. Arr-side paths (mapping keys, excluded
+// root folders) are left alone.
+func (c *Config) resolvePaths() error {
+	fields := []struct {
+		name string
+		path *string
+	}{
+		{"paths.media_root", &c.Paths.MediaRoot},
+		{"paths.torrent_root", &c.Paths.TorrentRoot},
+		{"paths.checkpoint_file", &c.Paths.CheckpointFile},
+		{"paths.arr_cache_file", &c.Paths.ArrCacheFile},
+		{"paths.snapshot_media_root", &c.Paths.SnapshotMediaRoot},
+		{"paths.snapshot_torrent_root", &c.Paths.SnapshotTorrentRoot},
+		{"outputs.report_file", &c.Outputs.ReportFile},
+		{"outputs.sqlite_path", &c.Outputs.SQLitePath},
+		{"analysis.baseline_file", &c.Analysis.BaselineFile},
+		{"qbittorrent.backup_dir", &c.Qbittorrent.BackupDir},
+		{"http.ca_cert_file", &c.HTTP.CACertFile},
+		{"sonarr.fs_root", &c.Sonarr.FSRoot},
+		{"radarr.fs_root", &c.Radarr.FSRoot},
+	}
+	for i := range c.Arr {
+		fields = append(fields, struct {
+			name string
+			path *string
+		}{fmt.Sprintf("arr[%d].fs_root", i), &c.Arr[i].FSRoot})
+	}
+	for _, f := range fields {
+		abs, err := absPath(*f.path, f.name)
+		if err != nil {
+			return err
+		}
+		*f.path = abs
+	}
+
+	lists := []struct {
+		name  string
+		paths []string
+	}{
+		{"paths.extra_scan_paths", c.Paths.ExtraScanPaths},
+		{"outputs.report_dir", c.Outputs.ReportDir},
+		{"permissions.skip_paths", c.Permissions.SkipPaths},
+		{"permissions.sgid_paths", c.Permissions.SGIDPaths},
+		{"analysis.protected_paths", c.Analysis.ProtectedPaths},
+		{"analysis.expected_untracked_paths", c.Analysis.ExpectedUntrackedPaths},
+	}
+	for _, l := range lists {
+		for i, p := range l.paths {
+			abs, err := absPath(p, l.name)
+			if err != nil {
+				return err
+			}
+			l.paths[i] = abs
+		}
+	}
+
+	for apiPath, fsPath := range c.PathMappings {
+		abs, err := absPath(fsPath, fmt.Sprintf("path_mappings[%q]", apiPath))
+		if err != nil {
+			return err
+		}
+		c.PathMappings[apiPath] = abs
+	}
+	return nil
+}
+
+// checkReportOverlap guards against report output landing inside a scanned
+// root, where each run's reports would be picked up as orphaned files by the
+// next. Depending on outputs.report_dir_overlap a report directory is either
+// added to permissions.skip_paths or rejected; a report_dir that is a scan
+// root itself is always rejected, since skipping it would skip the root.
+// outputs.report_file only has its own files skipped, see
+// CheckReportFileOverlap.
+func (c *Config) checkReportOverlap() error {
+	dirs := c.GetReportPaths()
+	for i, dir := range dirs {
+		name := "outputs.report_dir"
+		if len(dirs) > 1 {
+			name = fmt.Sprintf("outputs.report_dir[%d]", i)
+		}
+		dir = os.ExpandEnv(dir)
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		dir = filepath.Clean(dir)
+		rootName, root, ok := c.scanRootContaining(dir)
+		if !ok {
+			continue
+		}
+		if dir == filepath.Clean(root) {
+			return fmt.Errorf("%s (%s) is %s itself, so reports would be scanned as orphaned files on the next run", name, dir, rootName)
+		}
+		if c.Outputs.ReportDirOverlap == "error" {
+			return fmt.Errorf("%s (%s) is inside %s (%s), so reports would be scanned as orphaned files on the next run", name, dir, rootName, root)
+		}
+		c.addSkipPath(dir + string(filepath.Separator))
+	}
+	if c.Outputs.ReportFile != "" {
+		return c.CheckReportFileOverlap("outputs.report_file", os.ExpandEnv(c.Outputs.ReportFile))
+	}
+	return nil
+}
+
+// CheckReportFileOverlap applies the report overlap guard to a fixed report
+// file (outputs.report_file or scan --report-file) named name. When the file
+// is inside a scanned root, the report and the JSON and SARIF files written
+// next to it are added to permissions.skip_paths, or rejected when
+// outputs.report_dir_overlap is "error". The rest of its directory is still
+// scanned.
+func (c *Config) CheckReportFileOverlap(name, file string) error {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	rootName, root, ok := c.scanRootContaining(filepath.Dir(file))
+	if !ok {
+		return nil
+	}
+	if c.Outputs.ReportDirOverlap == "error" {
+		return fmt.Errorf("%s (%s) is inside %s (%s), so reports would be scanned as orphaned files on the next run", name, file, rootName, root)
+	}
+	for _, path := range []string{file, utils.SiblingPath(file, ".json"), utils.SiblingPath(file, ".sarif")} {
+		c.addSkipPath(path)
+	}
+	return nil
+}
+
+// scanRootContaining returns the first scan root, by config key, that path
+// (which must be clean) is or lies below.
+func (c *Config) scanRootContaining(path string) (name, root string, ok bool) {
+	roots := map[string]string{
+		"paths.media_root":   c.Paths.MediaRoot,
+		"paths.torrent_root": c.Paths.TorrentRoot,
+	}
+	for i, p := range c.Paths.ExtraScanPaths {
+		roots[fmt.Sprintf("paths.extra_scan_paths[%d]", i)] = p
+	}
+	rootNames := make([]string, 0, len(roots))
+	for name := range roots {
+		rootNames = append(rootNames, name)
+	}
+	sort.Strings(rootNames)
+
+	for _, name := range rootNames {
+		root := roots[name]
+		if root != "" && isWithin(path, filepath.Clean(root)) {
+			return name, root, true
+		}
+	}
+	return "", "", false
+}
+
+func (c *Config) addSkipPath(path string) {
+	if !containsString(c.Permissions.SkipPaths, path) {
+		c.Permissions.SkipPaths = append(c.Permissions.SkipPaths, path)
+	}
+}
+
+// isWithin reports whether path is root or below it. Both must be clean.
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPathMappingsNotice describes the built-in mappings when they were
+// applied, or returns "" when path_mappings is configured or the defaults are
+// off. Nothing else hints that Arr paths are assumed to start with /data, so
+// it is shown at startup.
+func (c *Config) DefaultPathMappingsNotice() string {
+	if !c.DefaultPathMappings || len(c.PathMappings) == 0 {
+		return ""
+	}
+	apiPaths := make([]string, 0, len(c.PathMappings))
+	for apiPath := range c.PathMappings {
+		apiPaths = append(apiPaths, apiPath)
+	}
+	sort.Strings(apiPaths)
+	pairs := make([]string, 0, len(apiPaths))
+	for _, apiPath := range apiPaths {
+		pairs = append(pairs, apiPath+" -> "+c.PathMappings[apiPath])
+	}
+	return fmt.Sprintf("no [path_mappings] configured, using the built-in defaults %s. "+
+		"If your Arr paths don't start with /data, add [path_mappings] or set [paths].default_path_mappings = false.", strings.Join(pairs, ", "))
+}
+
+// PathMappingWarnings checks that every mapping's filesystem-side target is
+// an existing directory and, when the built-in defaults are in use, that the
+// Arr services actually report paths under /data. Wrong mappings make every
+// file look orphaned, so these are surfaced prominently.
+func (c *Config) PathMappingWarnings(arrPaths []string) []string {
+	var warnings []string
+
+	apiPaths := make([]string, 0, len(c.PathMappings))
+	for apiPath := range c.PathMappings {
+		apiPaths = append(apiPaths, apiPath)
+	}
+	sort.Strings(apiPaths)
+	for _, apiPath := range apiPaths {
+		target := c.PathMappings[apiPath]
+		info, err := os.Stat(target)
+		switch {
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("path mapping %s -> %s: target does not exist (%v)", apiPath, target, err))
+		case !info.IsDir():
+			warnings = append(warnings, fmt.Sprintf("path mapping %s -> %s: target is not a directory", apiPath, target))
+		}
+	}
+
+	if c.DefaultPathMappings && len(arrPaths) > 0 {
+		underData := 0
+		for _, p := range arrPaths {
+			if strings.HasPrefix(p, "/data/") {
+				underData++
+			}
+		}
+		if underData == 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"no [path_mappings] are configured, so the /data/media and /data/torrents defaults apply, "+
+					"but none of the %d Arr paths start with /data (e.g. %s). Add [path_mappings] from the Arr "+
+					"paths to media_root or every file will be reported as orphaned.", len(arrPaths), arrPaths[0]))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,370 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate_ResolvesRelativePaths(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Paths:       PathsConfig{MediaRoot: "media", TorrentRoot: "/mnt/torrents"},
+		Outputs:     OutputConfig{ReportDir: StringList{"$HOME/reports"}, SQLitePath: "history.db"},
+		Permissions: PermissionsConfig{SkipPaths: []string{"media/skip/"}},
+	}
+	cfg.applyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := map[string][2]string{
+		"media_root":   {cfg.Paths.MediaRoot, filepath.Join(wd, "media")},
+		"torrent_root": {cfg.Paths.TorrentRoot, "/mnt/torrents"},
+		"report_dir":   {cfg.Outputs.ReportDir[0], "$HOME/reports"},
+		"sqlite_path":  {cfg.Outputs.SQLitePath, filepath.Join(wd, "history.db")},
+		"skip_paths":   {cfg.Permissions.SkipPaths[0], filepath.Join(wd, "media", "skip") + "/"},
+		"mapping":      {cfg.PathMappings["/data/media"], filepath.Join(wd, "media")},
+	}
+	for name, c := range checks {
+		if c[0] != c[1] {
+			t.Errorf("%s = %q, want %q", name, c[0], c[1])
+		}
+	}
+}
+
+func TestPathMappingWarnings(t *testing.T) {
+	media := t.TempDir()
+	cfg := &Config{Paths: PathsConfig{MediaRoot: media, TorrentRoot: filepath.Join(media, "missing")}}
+	cfg.applyDefaults()
+
+	warnings := cfg.PathMappingWarnings([]string{"/tv/Show/S01E01.mkv"})
+	// /data/ and /data/torrents point at the missing torrent root, and the
+	// defaults are in use while Arr reports /tv paths.
+	if len(warnings) != 3 {
+		t.Fatalf("got %d warnings, want 3: %q", len(warnings), warnings)
+	}
+
+	if w := cfg.PathMappingWarnings([]string{"/data/media/tv/Show/S01E01.mkv"}); len(w) != 2 {
+		t.Errorf("Arr paths under /data should not warn about the defaults: %q", w)
+	}
+
+	explicit := &Config{PathMappings: map[string]string{"/tv": media}}
+	explicit.applyDefaults()
+	if w := explicit.PathMappingWarnings([]string{"/tv/Show/S01E01.mkv"}); len(w) != 0 {
+		t.Errorf("valid explicit mapping produced warnings: %q", w)
+	}
+}
+
+func TestValidate_ReportDirInsideMediaRoot(t *testing.T) {
+	media := t.TempDir()
+	reports := filepath.Join(media, "reports")
+
+	cfg := &Config{Paths: PathsConfig{MediaRoot: media}, Outputs: OutputConfig{ReportDir: StringList{reports}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if want := reports + "/"; len(cfg.Permissions.SkipPaths) != 1 || cfg.Permissions.SkipPaths[0] != want {
+		t.Errorf("skip_paths = %q, want [%q]", cfg.Permissions.SkipPaths, want)
+	}
+
+	strict := &Config{Paths: PathsConfig{MediaRoot: media}, Outputs: OutputConfig{ReportDir: StringList{reports}, ReportDirOverlap: "error"}}
+	if err := strict.Validate(); err == nil || !strings.Contains(err.Error(), "outputs.report_dir") {
+		t.Errorf("expected an overlap error, got %v", err)
+	}
+
+	outside := &Config{Paths: PathsConfig{MediaRoot: media}, Outputs: OutputConfig{ReportDir: StringList{media + "-reports"}, ReportDirOverlap: "error"}}
+	if err := outside.Validate(); err != nil {
+		t.Errorf("sibling directory sharing a prefix flagged as overlapping: %v", err)
+	}
+}
+
+func TestValidate_ReportFileInsideMediaRoot(t *testing.T) {
+	media := t.TempDir()
+	report := filepath.Join(media, "latest.md")
+
+	cfg := &Config{Paths: PathsConfig{MediaRoot: media}, Outputs: OutputConfig{ReportFile: report}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := []string{report, filepath.Join(media, "latest.json"), filepath.Join(media, "latest.sarif")}
+	if !reflect.DeepEqual(cfg.Permissions.SkipPaths, want) {
+		t.Errorf("skip_paths = %q, want only the report files %q", cfg.Permissions.SkipPaths, want)
+	}
+
+	root := &Config{Paths: PathsConfig{MediaRoot: media}, Outputs: OutputConfig{ReportDir: StringList{media}}}
+	if err := root.Validate(); err == nil || !strings.Contains(err.Error(), "paths.media_root itself") {
+		t.Errorf("report_dir equal to media_root: err = %v, want a rejection", err)
+	}
+
+	flagged := &Config{Paths: PathsConfig{MediaRoot: media}}
+	if err := flagged.CheckReportFileOverlap("--report-file", filepath.Join(media, "tv", "r.md")); err != nil {
+		t.Fatalf("CheckReportFileOverlap: %v", err)
+	}
+	if len(flagged.Permissions.SkipPaths) != 3 || flagged.Permissions.SkipPaths[0] != filepath.Join(media, "tv", "r.md") {
+		t.Errorf("--report-file skip_paths = %q, want the report and its siblings", flagged.Permissions.SkipPaths)
+	}
+	flagged.Outputs.ReportDirOverlap = "error"
+	if err := flagged.CheckReportFileOverlap("--report-file", report); err == nil || !strings.Contains(err.Error(), "--report-file") {
+		t.Errorf("--report-file with report_dir_overlap = error: err = %v, want a rejection", err)
+	}
+}
+
+func TestLocate_SearchesDefaultPaths(t *testing.T) {
+	work, xdg := t.TempDir(), t.TempDir()
+	t.Chdir(work)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("HOME", t.TempDir())
+
+	xdgConfig := filepath.Join(xdg, "auditarr", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(xdgConfig), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xdgConfig, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Locate(""); err != nil || got != xdgConfig {
+		t.Errorf("Locate = %q, %v; want %q", got, err, xdgConfig)
+	}
+
+	if err := os.WriteFile("auditarr.toml", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Locate(""); got != "auditarr.toml" {
+		t.Errorf("Locate = %q, want ./auditarr.toml to take precedence", got)
+	}
+	if got, _ := Locate("/explicit.toml"); got != "/explicit.toml" {
+		t.Errorf("an explicit --config path was not used as is: %q", got)
+	}
+}
+
+func TestLoad_ReportDirList(t *testing.T) {
+	dir := t.TempDir()
+	load := func(reportDir string) *Config {
+		t.Helper()
+		path := filepath.Join(dir, "config.toml")
+		data := "[paths]\nmedia_root = \"/data/media\"\n[outputs]\nreport_dir = " + reportDir + "\n"
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(report_dir = %s): %v", reportDir, err)
+		}
+		return cfg
+	}
+
+	if got := load(`"/srv/reports"`).GetReportPaths(); len(got) != 1 || got[0] != "/srv/reports" {
+		t.Errorf("single report_dir = %q, want [/srv/reports]", got)
+	}
+	cfg := load(`["/srv/reports", "/mnt/nas/reports"]`)
+	if got := cfg.GetReportPaths(); len(got) != 2 || got[1] != "/mnt/nas/reports" {
+		t.Errorf("report_dir list = %q, want both directories", got)
+	}
+	if cfg.GetReportPath() != "/srv/reports" {
+		t.Errorf("GetReportPath = %q, want the first directory", cfg.GetReportPath())
+	}
+}
+
+func TestLoad_Stdin(t *testing.T) {
+	orig := stdin
+	t.Cleanup(func() { stdin = orig })
+	stdin = strings.NewReader("[paths]\nmedia_root = \"/data/media\"\n")
+
+	cfg, err := Load(StdinPath)
+	if err != nil {
+		t.Fatalf("Load(-): %v", err)
+	}
+	if cfg.Paths.MediaRoot != "/data/media" {
+		t.Errorf("media_root = %q, want /data/media", cfg.Paths.MediaRoot)
+	}
+	if cfg.Sonarr.GraceHours != 24 {
+		t.Errorf("defaults were not applied to a stdin config: grace_hours = %d", cfg.Sonarr.GraceHours)
+	}
+}
+
+func TestLoad_KnownBadHashes(t *testing.T) {
+	dir := t.TempDir()
+	hashes := filepath.Join(dir, "bad.sha256")
+	list := "# from the tracker's advisory\n" +
+		"E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855  empty.exe\n" +
+		"\n"
+	if err := os.WriteFile(hashes, []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.toml")
+	data := "[paths]\nmedia_root = \"/data/media\"\n[suspicious]\nknown_bad_hashes_file = \"" + hashes + "\"\nseverities = { exe = \"critical\", iso = \"info\" }\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Suspicious.KnownBadHashes) != 1 || !cfg.Suspicious.KnownBadHashes["e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"] {
+		t.Errorf("KnownBadHashes = %v, want the one lowercased hash", cfg.Suspicious.KnownBadHashes)
+	}
+
+	if err := os.WriteFile(path, []byte("[paths]\nmedia_root = \"/data/media\"\n[suspicious]\nseverities = { exe = \"high\" }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "suspicious.severities") {
+		t.Errorf("Load with an unknown severity = %v, want a suspicious.severities error", err)
+	}
+}
+
+func TestLoad_RootFolderMappings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	data := "[paths]\nmedia_root = \"/mnt/media\"\n[path_mappings]\n\"/movies/kids\" = \"/mnt/kids\"\n[radarr]\nurl = \"http://radarr:7878\"\napi_key = \"k\"\napi_root = \"/movies\"\nfs_root = \"/mnt/media/movies\"\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	mappings, outside := cfg.Radarr.RootFolderMappings([]string{"/movies", "/movies/4k/", "/movies/kids", "/moviesextra", "/tv"})
+	if len(outside) != 2 || outside[0] != "/moviesextra" || outside[1] != "/tv" {
+		t.Errorf("outside = %v, want /moviesextra and /tv", outside)
+	}
+	cfg.AddPathMappings(mappings)
+	want := map[string]string{
+		"/movies":      "/mnt/media/movies",
+		"/movies/4k":   "/mnt/media/movies/4k",
+		"/movies/kids": "/mnt/kids",
+	}
+	for apiPath, fsPath := range want {
+		if cfg.PathMappings[apiPath] != fsPath {
+			t.Errorf("PathMappings[%q] = %q, want %q", apiPath, cfg.PathMappings[apiPath], fsPath)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("[paths]\nmedia_root = \"/mnt/media\"\n[sonarr]\nurl = \"http://sonarr:8989\"\napi_key = \"k\"\napi_root = \"/tv\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "sonarr.api_root") {
+		t.Errorf("Load with api_root but no fs_root = %v, want a sonarr.api_root error", err)
+	}
+}
+
+func TestLoad_DefaultPathMappings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("[paths]\nmedia_root = \"/mnt/media\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.PathMappings["/data/media"] != "/mnt/media" || !strings.Contains(cfg.DefaultPathMappingsNotice(), "/data/media -> /mnt/media") {
+		t.Errorf("defaults = %v, notice %q; want /data/media mapped and announced", cfg.PathMappings, cfg.DefaultPathMappingsNotice())
+	}
+
+	if err := os.WriteFile(path, []byte("[paths]\nmedia_root = \"/mnt/media\"\ndefault_path_mappings = false\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.PathMappings) != 0 || cfg.DefaultPathMappings || cfg.DefaultPathMappingsNotice() != "" {
+		t.Errorf("with defaults off: mappings %v, default %t; want none", cfg.PathMappings, cfg.DefaultPathMappings)
+	}
+}
+
+func TestLoad_Performance(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	load := func(extra string) (*Config, error) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("[paths]\nmedia_root = \"/mnt/media\"\n"+extra), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return Load(path)
+	}
+
+	cfg, err := load("[performance]\nworkers = 6\n")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Verify.Concurrency != 6 {
+		t.Errorf("verify.concurrency = %d, want performance.workers 6 when unset", cfg.Verify.Concurrency)
+	}
+
+	cfg, err = load("[performance]\nworkers = 6\n[verify]\nconcurrency = 1\n")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Verify.Concurrency != 1 {
+		t.Errorf("verify.concurrency = %d, want the explicit 1", cfg.Verify.Concurrency)
+	}
+
+	if _, err := load("[performance]\nqueue_size = -1\n"); err == nil || !strings.Contains(err.Error(), "performance.queue_size") {
+		t.Errorf("negative queue_size: err = %v, want a performance.queue_size error", err)
+	}
+}
+
+func TestLoad_QuietHours(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	load := func(quiet string) (*Config, error) {
+		t.Helper()
+		content := "[paths]\nmedia_root = \"/mnt/media\"\n[notifications]\nquiet_hours = " + quiet + "\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return Load(path)
+	}
+
+	cfg, err := load(`{ start = "22:00", end = "07:30", timezone = "UTC" }`)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	q := cfg.Notifications.QuietHours
+	at := func(hour, minute int) time.Time { return time.Date(2026, 3, 1, hour, minute, 0, 0, time.UTC) }
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{{at(23, 0), true}, {at(3, 0), true}, {at(7, 30), false}, {at(12, 0), false}, {at(22, 0), true}} {
+		if got := q.Active(tc.t); got != tc.want {
+			t.Errorf("Active(%s) = %t, want %t", tc.t.Format("15:04"), got, tc.want)
+		}
+	}
+	if got, want := q.NextEnd(at(23, 0)), at(7, 30).AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("NextEnd(23:00) = %s, want %s", got, want)
+	}
+
+	if _, err := load(`{ start = "25:00", end = "07:00" }`); err == nil || !strings.Contains(err.Error(), "quiet_hours.start") {
+		t.Errorf("invalid start: err = %v, want a quiet_hours.start error", err)
+	}
+}
+
+func TestLoad_ArrKind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	load := func(kind string) error {
+		t.Helper()
+		content := "[paths]\nmedia_root = \"/mnt/media\"\n[[arr]]\nkind = \"" + kind + "\"\nurl = \"http://localhost:8686\"\napi_key = \"key\"\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Load(path)
+		return err
+	}
+
+	if err := load("lidarr"); err != nil {
+		t.Errorf("kind lidarr: %v", err)
+	}
+	if err := load("readarr"); err == nil || !strings.Contains(err.Error(), "arr[0].kind must be one of sonarr, radarr, whisparr, lidarr") {
+		t.Errorf("kind readarr: err = %v, want a kind error listing the supported kinds", err)
+	}
+}
@@ -11,6 +11,12 @@ const (
 	MediaOrphanedDownload MediaClassification = "orphaned_download"
 	MediaHiddenFile       MediaClassification = "hidden_file"
 	MediaLostAndFound     MediaClassification = "lost_and_found"
+	MediaOrphanedSidecar  MediaClassification = "orphaned_sidecar"
+	MediaOtherFile        MediaClassification = "other_file"
+	// MediaExpectedUntracked is an untracked file under a path the operator
+	// maintains by hand, inventoried but not counted as an orphan.
+	MediaExpectedUntracked MediaClassification = "expected_untracked"
+	MediaRemovedFromArr    MediaClassification = "removed_from_arr"
 )
 
 type ClassifiedMedia struct {
@@ -18,7 +24,11 @@ type ClassifiedMedia struct {
 	KnownToArr     bool
 	ArrSource      string
 	Classification MediaClassification
+	Code           ReasonCode
 	Reason         string
+	// ArrConfirmed marks an orphan whose folder the Arr service itself lists
+	// as unmapped, so both sides agree it isn't part of the library.
+	ArrConfirmed bool
 }
 
 type ArrFile struct {
@@ -26,10 +36,26 @@ type ArrFile struct {
 	SeriesID   int
 	EpisodeID  int
 	MovieID    int
+	ArtistID   int
 	Monitored  bool
 	ImportDate time.Time
+	// Size is the file size Arr recorded, or 0 when unknown.
+	Size int64
+	// Service names the configured instance that tracks the file when it
+	// came from an [[arr]] entry; GraceHours overrides the per-kind default.
+	Service    string
+	GraceHours int
+}
+
+// RootFolder is a library root configured in Sonarr/Radarr, as reported by
+// its API (i.e. using the Arr service's own view of the path).
+type RootFolder struct {
+	Path string
+	// UnmappedFolders are directories in the root that the Arr service
+	// doesn't associate with any series or movie.
+	UnmappedFolders []string
 }
 
 func (af *ArrFile) IsKnown() bool {
-	return af != nil && af.Path != "" && (af.SeriesID > 0 || af.MovieID > 0)
+	return af != nil && af.Path != "" && (af.SeriesID > 0 || af.MovieID > 0 || af.ArtistID > 0)
 }
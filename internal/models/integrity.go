@@ -0,0 +1,17 @@
+package models
+
+type CorruptFile struct {
+	Path   string
+	Size   int64
+	Code   ReasonCode
+	Reason string
+}
+
+// SizeMismatch is a library file whose size on disk differs from the size
+// Sonarr/Radarr recorded for it, suggesting it was replaced out of band.
+type SizeMismatch struct {
+	Path      string
+	ArrSource string
+	DiskSize  int64
+	ArrSize   int64
+}
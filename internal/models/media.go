@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 type MediaFileSource string
 
@@ -19,6 +23,39 @@ type MediaFile struct {
 	IsHardlinked  bool
 	IsHidden      bool
 	Source        MediaFileSource
+	Device        uint64
+	Inode         uint64
+	// IsReflinked marks a file that shares its data copy-on-write with its
+	// library or download counterpart. It protects the file like a hardlink.
+	IsReflinked bool
+	// IsSidecar marks subtitle and metadata files kept only so they can be
+	// matched against their video; they are not classified as media.
+	IsSidecar bool
+	// Fingerprint identifies the file's content when fingerprinting is
+	// enabled, so a file replaced in place can be told apart across runs.
+	Fingerprint string
+}
+
+// Ext returns the file's extension, lowercased and including the dot.
+func (m *MediaFile) Ext() string {
+	return Ext(m.Path)
+}
+
+// Ext returns the lowercased extension of path including the leading dot,
+// or "" when there is none. All extension comparisons should go through it
+// so that "Movie.MKV" and "movie.mkv" are treated alike.
+func Ext(path string) string {
+	return strings.ToLower(filepath.Ext(path))
+}
+
+// NormalizeExtension lowercases a configured extension and adds the leading
+// dot if missing, so "EXE", "exe" and ".exe" all compare equal to Ext output.
+func NormalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
 }
 
 func (m *MediaFile) WithinGraceWindow(hours int) bool {
@@ -0,0 +1,16 @@
+package models
+
+import "testing"
+
+func TestExtNormalization(t *testing.T) {
+	m := MediaFile{Path: "/media/movies/Movie (2020)/Movie.MKV"}
+	if got := m.Ext(); got != ".mkv" {
+		t.Errorf("Ext() = %q, want .mkv", got)
+	}
+
+	for _, configured := range []string{"exe", "EXE", ".exe", " .Exe "} {
+		if ok, _ := IsSuspicious("/media/tv/Show.S01E01.EXE", []string{configured}, false); !ok {
+			t.Errorf("configured extension %q did not match an uppercase .EXE file", configured)
+		}
+	}
+}
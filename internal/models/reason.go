@@ -0,0 +1,37 @@
+package models
+
+// ReasonCode is a stable machine-readable reason for a finding, reported
+// alongside the human-readable text so scripts can branch without parsing
+// English. Values are part of the JSON report contract; add new ones rather
+// than renaming.
+type ReasonCode string
+
+const (
+	ReasonTrackedHardlinked ReasonCode = "tracked_hardlinked"
+	ReasonTrackedReflinked  ReasonCode = "tracked_reflinked"
+	ReasonNotHardlinked     ReasonCode = "not_hardlinked"
+	ReasonNotTracked        ReasonCode = "not_tracked"
+	ReasonDownloadNotLinked ReasonCode = "download_not_linked"
+	ReasonNotImported       ReasonCode = "not_imported"
+	ReasonHiddenFragment    ReasonCode = "hidden_fragment"
+	ReasonRecoveryArtifact  ReasonCode = "recovery_artifact"
+	ReasonSidecarNoVideo    ReasonCode = "sidecar_without_video"
+	ReasonCustomRule        ReasonCode = "custom_rule"
+	ReasonProtected         ReasonCode = "protected"
+	ReasonIgnoredExtension  ReasonCode = "ignored_extension"
+	ReasonExpectedUntracked ReasonCode = "expected_untracked"
+	ReasonRemovedFromArr    ReasonCode = "removed_from_arr"
+	ReasonUnknown           ReasonCode = "unknown"
+
+	ReasonSuspiciousExtension ReasonCode = "suspicious_extension"
+	ReasonDoubleExtension     ReasonCode = "double_extension"
+	ReasonKnownBadHash        ReasonCode = "known_bad_hash"
+	ReasonExcessiveHardlinks  ReasonCode = "excessive_hardlinks"
+	ReasonProbeFailed         ReasonCode = "probe_failed"
+
+	ReasonWrongOwner             ReasonCode = "wrong_owner"
+	ReasonWrongGroup             ReasonCode = "wrong_group"
+	ReasonNotGroupWritable       ReasonCode = "not_group_writable"
+	ReasonNonstandardPermissions ReasonCode = "nonstandard_permissions"
+	ReasonMissingSGID            ReasonCode = "missing_sgid"
+)
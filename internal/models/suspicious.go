@@ -7,7 +7,38 @@ import (
 
 type SuspiciousFile struct {
 	Path   string
+	Code   ReasonCode
 	Reason string
+	// Severity is one of SuspiciousSeverities, from [suspicious].severities
+	// or SuspiciousCritical when the extension has none configured.
+	Severity string
+	// SHA256 is set when the file was hashed against the known-bad list.
+	SHA256 string
+}
+
+// Suspicious finding severities, from most to least severe.
+const (
+	SuspiciousCritical = "critical"
+	SuspiciousWarning  = "warning"
+	SuspiciousInfo     = "info"
+)
+
+// SuspiciousSeverities lists the accepted suspicious finding severities.
+var SuspiciousSeverities = []string{SuspiciousCritical, SuspiciousWarning, SuspiciousInfo}
+
+// SuspiciousSeverityRank orders severities so the most severe ranks highest;
+// unknown levels rank 0.
+func SuspiciousSeverityRank(severity string) int {
+	switch severity {
+	case SuspiciousCritical:
+		return 3
+	case SuspiciousWarning:
+		return 2
+	case SuspiciousInfo:
+		return 1
+	default:
+		return 0
+	}
 }
 
 var defaultSuspiciousExtensions = []string{
@@ -17,32 +48,31 @@ var defaultSuspiciousExtensions = []string{
 	".iso", ".zip", ".rar", ".7z", ".tar", ".gz",
 }
 
-func IsSuspicious(path string, extensions []string, flagArchives bool) (bool, string) {
+func IsSuspicious(path string, extensions []string, flagArchives bool) (bool, ReasonCode) {
 	if len(extensions) == 0 {
 		extensions = defaultSuspiciousExtensions
 	}
 
-	ext := strings.ToLower(filepath.Ext(path))
+	ext := Ext(path)
 	if ext == "" {
 		return false, ""
 	}
 
 	for _, susExt := range extensions {
-		if ext == susExt {
+		if ext == NormalizeExtension(susExt) {
 			if isArchiveExtension(ext) && !flagArchives {
 				return false, ""
 			}
-			return true, "suspicious_extension"
+			return true, ReasonSuspiciousExtension
 		}
 	}
 
 	base := filepath.Base(path)
 	parts := strings.Split(base, ".")
 	if len(parts) > 2 {
-		lastExt := "." + strings.ToLower(parts[len(parts)-1])
 		for _, susExt := range extensions {
-			if lastExt == susExt && !isMediaExtension(parts[len(parts)-2]) {
-				return true, "double_extension"
+			if ext == NormalizeExtension(susExt) && !isMediaExtension(parts[len(parts)-2]) {
+				return true, ReasonDoubleExtension
 			}
 		}
 	}
@@ -116,7 +146,7 @@ type PermissionIssue struct {
 	ExpectedMode uint32
 	Owner        int
 	Group        int
-	Issue        string
+	Issue        ReasonCode
 	Severity     string
 	FixHint      string
 }
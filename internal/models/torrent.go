@@ -8,24 +8,41 @@ const (
 	StateDownloading TorrentState = "downloading"
 	StateChecking    TorrentState = "checking"
 	StateCompleted   TorrentState = "completed"
+	StateSeeding     TorrentState = "seeding"
 	StatePaused      TorrentState = "paused"
 	StateStalled     TorrentState = "stalled"
+	// StateUnknown is a state auditarr has no mapping for, e.g. one added by
+	// a newer qBittorrent, or an error state. Such torrents are left out of
+	// unlinked analysis unless configured otherwise.
+	StateUnknown TorrentState = "unknown"
 )
 
 type Torrent struct {
 	Hash        string
 	Name        string
 	SavePath    string
+	Category    string
 	Size        int64
 	State       TorrentState
 	CompletedOn time.Time
 	Files       []string
+	Trackers    []string
+	// IsPrivate marks private-tracker torrents. Removing these before their
+	// seeding requirements are met risks ratio or hit-and-run penalties, so
+	// they must never be the target of cleanup.
+	IsPrivate bool
 }
 
 func (t *Torrent) IsActive() bool {
 	return t.State == StateDownloading || t.State == StateChecking
 }
 
+// IsComplete reports whether the torrent has finished downloading;
+// StateSeeding is a completed torrent that is currently uploading.
+func (t *Torrent) IsComplete() bool {
+	return t.State == StateCompleted || t.State == StateSeeding
+}
+
 func (t *Torrent) WithinGraceWindow(hours int) bool {
 	if hours <= 0 {
 		return false
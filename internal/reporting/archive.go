@@ -0,0 +1,90 @@
+package reporting
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// reportPatterns match the timestamped reports written to report_dir, both
+// plain and already gzipped.
+var reportPatterns = []string{"audit-report-*.md", "audit-report-*.json", "audit-report-*.md.gz", "audit-report-*.json.gz"}
+
+// listReports returns every timestamped report in reportDir, compressed or
+// not, sorted by name (and so by run time).
+func listReports(reportDir string) ([]string, error) {
+	var reports []string
+	for _, pattern := range reportPatterns {
+		matches, err := filepath.Glob(filepath.Join(reportDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, matches...)
+	}
+	sort.Strings(reports)
+	return reports, nil
+}
+
+// CompressReports gzips every uncompressed report in reportDir except those
+// in keep (the current run's), replacing each with a .gz file that keeps the
+// original modification time. It returns how many reports were compressed.
+func CompressReports(reportDir string, keep ...string) (int, error) {
+	reports, err := listReports(reportDir)
+	if err != nil {
+		return 0, err
+	}
+	skip := make(map[string]bool, len(keep))
+	for _, k := range keep {
+		skip[filepath.Clean(k)] = true
+	}
+
+	var errs []error
+	compressed := 0
+	for _, path := range reports {
+		if strings.HasSuffix(path, ".gz") || skip[filepath.Clean(path)] {
+			continue
+		}
+		if err := gzipInPlace(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		compressed++
+	}
+	return compressed, errors.Join(errs...)
+}
+
+func gzipInPlace(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name = filepath.Base(path)
+	zw.ModTime = info.ModTime()
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+
+	gzPath := path + ".gz"
+	if err := writeFileAtomic(gzPath, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(gzPath, info.ModTime(), info.ModTime()); err != nil {
+		return fmt.Errorf("failed to preserve modification time of %s: %w", gzPath, err)
+	}
+	return os.Remove(path)
+}
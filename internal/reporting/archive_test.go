@@ -0,0 +1,67 @@
+package reporting
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompressReports(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	old := write("audit-report-2026-01-01_00-00-00.md", "# old")
+	oldTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(old, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+	write("audit-report-2026-01-01_00-00-00.json", "{}")
+	current := write("audit-report-2026-01-02_00-00-00.md", "# current")
+	write("notes.txt", "not a report")
+
+	n, err := CompressReports(dir, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("compressed %d reports, want 2", n)
+	}
+	if _, err := os.Stat(current); err != nil {
+		t.Errorf("current report was touched: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("original of compressed report still exists")
+	}
+
+	f, err := os.Open(old + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, _ := f.Stat()
+	if !info.ModTime().Equal(oldTime) {
+		t.Errorf("mod time %v, want %v", info.ModTime(), oldTime)
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if string(data) != "# old" {
+		t.Errorf("decompressed %q", data)
+	}
+
+	// A second pass leaves already compressed reports alone.
+	if n, err := CompressReports(dir, current); err != nil || n != 0 {
+		t.Errorf("second pass compressed %d (err %v)", n, err)
+	}
+}
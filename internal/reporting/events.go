@@ -0,0 +1,161 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+)
+
+// Event names written to the event log, in the order a run emits them.
+const (
+	EventRunStarted        = "run_started"
+	EventCollectorFinished = "collector_finished"
+	EventRunComplete       = "run_complete"
+)
+
+// EventLog writes audit progress as NDJSON, one slog JSON record per event,
+// so a live UI can tail the file while watch runs. Every record carries the
+// event name as msg and the run number, which starts at 1 and increments
+// with each RunStarted. A nil *EventLog discards events.
+type EventLog struct {
+	logger *slog.Logger
+	closer io.Closer
+
+	mu  sync.Mutex
+	run int
+}
+
+// OpenEventLog appends events to the file at path, creating it if needed.
+func OpenEventLog(path string) (*EventLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	l := NewEventLog(f)
+	l.closer = f
+	return l, nil
+}
+
+// NewEventLog writes events to w.
+func NewEventLog(w io.Writer) *EventLog {
+	return &EventLog{logger: slog.New(slog.NewJSONHandler(w, nil))}
+}
+
+// Close closes the underlying file when the log was opened by OpenEventLog.
+func (l *EventLog) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// RunStarted starts a new run and records it.
+func (l *EventLog) RunStarted(label string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.run++
+	l.mu.Unlock()
+	var attrs []any
+	if label != "" {
+		attrs = append(attrs, slog.String("label", label))
+	}
+	l.emit(slog.LevelInfo, EventRunStarted, attrs...)
+}
+
+// FilesystemFinished records the end of the filesystem walk.
+func (l *EventLog) FilesystemFinished(files int, duration time.Duration, err error) {
+	if l == nil {
+		return
+	}
+	attrs := []any{
+		slog.String("collector", "filesystem"),
+		slog.Bool("ok", err == nil),
+		slog.Int("files", files),
+		slog.Float64("duration_seconds", duration.Seconds()),
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.emit(level, EventCollectorFinished, attrs...)
+}
+
+// ServicesFinished records one event per enabled service collected during a
+// services phase that took duration.
+func (l *EventLog) ServicesFinished(statuses []analysis.ServiceStatus, duration time.Duration) {
+	if l == nil {
+		return
+	}
+	for _, s := range statuses {
+		if !s.Enabled {
+			continue
+		}
+		attrs := []any{
+			slog.String("collector", s.Name),
+			slog.Bool("ok", s.OK),
+			slog.Float64("duration_seconds", duration.Seconds()),
+		}
+		level := slog.LevelInfo
+		if !s.OK {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("reason", s.Reason), slog.String("error", s.Error))
+		}
+		if s.Degraded {
+			attrs = append(attrs, slog.Int("skipped_fetches", s.SkippedFetches))
+		}
+		l.emit(level, EventCollectorFinished, attrs...)
+	}
+}
+
+// RunComplete records the run's outcome and summary counts. The run is ok
+// when the filesystem walk and every enabled service worked, matching the
+// watch metrics.
+func (l *EventLog) RunComplete(result *analysis.AnalysisResult, duration time.Duration, reportPath string) {
+	if l == nil {
+		return
+	}
+	ok := !result.FilesystemFailed && len(result.FailedServices()) == 0
+	attrs := []any{
+		slog.Bool("ok", ok),
+		slog.Float64("duration_seconds", duration.Seconds()),
+		slog.Group("summary",
+			slog.Int("total_files", result.Summary.TotalFiles),
+			slog.Int("healthy", result.Summary.HealthyCount),
+			slog.Int("at_risk", result.Summary.AtRiskCount),
+			slog.Int("orphaned", result.Summary.OrphanCount),
+			slog.Int("orphaned_downloads", result.Summary.OrphanedDownloadCount),
+			slog.Int("suspicious", result.Summary.SuspiciousCount),
+			slog.Int("corrupt", result.Summary.CorruptCount),
+		),
+	}
+	if reportPath != "" {
+		attrs = append(attrs, slog.String("report", reportPath))
+	}
+	if failed := result.FailedServices(); len(failed) > 0 {
+		attrs = append(attrs, slog.Any("failed_services", failed))
+	}
+	if len(result.Warnings) > 0 {
+		attrs = append(attrs, slog.Any("warnings", result.Warnings))
+	}
+	level := slog.LevelInfo
+	if !ok {
+		level = slog.LevelWarn
+	}
+	l.emit(level, EventRunComplete, attrs...)
+}
+
+func (l *EventLog) emit(level slog.Level, event string, attrs ...any) {
+	l.mu.Lock()
+	run := l.run
+	l.mu.Unlock()
+	l.logger.Log(context.Background(), level, event, append([]any{slog.Int("run", run)}, attrs...)...)
+}
@@ -0,0 +1,75 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+)
+
+func TestEventLog(t *testing.T) {
+	var buf bytes.Buffer
+	events := NewEventLog(&buf)
+
+	events.RunStarted("nightly")
+	events.FilesystemFinished(0, time.Second, errors.New("permission denied"))
+	events.ServicesFinished([]analysis.ServiceStatus{
+		{Name: "Sonarr", Enabled: true, OK: true},
+		{Name: "Radarr", Enabled: false},
+	}, 2*time.Second)
+	events.RunComplete(&analysis.AnalysisResult{
+		FilesystemFailed: true,
+		Summary:          analysis.SummaryStats{HealthyCount: 3},
+	}, 3*time.Second, "/reports/r.md")
+	events.RunStarted("")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d events, want 5 (disabled services are skipped):\n%s", len(lines), buf.String())
+	}
+
+	var records []map[string]any
+	for _, line := range lines {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("event is not JSON: %v\n%s", err, line)
+		}
+		records = append(records, rec)
+	}
+
+	wantMsgs := []string{EventRunStarted, EventCollectorFinished, EventCollectorFinished, EventRunComplete, EventRunStarted}
+	for i, want := range wantMsgs {
+		if records[i]["msg"] != want {
+			t.Errorf("event %d msg = %v, want %s", i, records[i]["msg"], want)
+		}
+	}
+	if records[1]["ok"] != false || records[1]["error"] != "permission denied" {
+		t.Errorf("filesystem event = %v, want a failure with the error", records[1])
+	}
+	if records[2]["collector"] != "Sonarr" || records[2]["ok"] != true {
+		t.Errorf("service event = %v, want Sonarr ok", records[2])
+	}
+	complete := records[3]
+	if complete["ok"] != false || complete["level"] != "WARN" || complete["report"] != "/reports/r.md" {
+		t.Errorf("run_complete = %v, want a failed run with the report path", complete)
+	}
+	if summary, _ := complete["summary"].(map[string]any); summary["healthy"] != float64(3) {
+		t.Errorf("run_complete summary = %v, want healthy 3", complete["summary"])
+	}
+	if records[0]["run"] != float64(1) || records[3]["run"] != float64(1) || records[4]["run"] != float64(2) {
+		t.Errorf("run numbers = %v, %v, %v; want 1, 1, 2", records[0]["run"], records[3]["run"], records[4]["run"])
+	}
+}
+
+func TestEventLog_NilDiscards(t *testing.T) {
+	var events *EventLog
+	events.RunStarted("x")
+	events.RunComplete(&analysis.AnalysisResult{}, 0, "")
+	if err := events.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,11 +2,30 @@ package reporting
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/jdpx/auditarr/internal/analysis"
 	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
 )
 
+// writeFileAtomic writes data to path, creating parent directories. The file
+// is replaced via rename so a tool watching a fixed path never sees a
+// partially written report, and two runs writing the same report never share
+// a temporary file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	return utils.WriteFileAtomic(path, data, 0644)
+}
+
 func filterByClassification(classified []models.ClassifiedMedia, class models.MediaClassification) []models.ClassifiedMedia {
 	var result []models.ClassifiedMedia
 	for _, cm := range classified {
@@ -17,6 +36,80 @@ func filterByClassification(classified []models.ClassifiedMedia, class models.Me
 	return result
 }
 
+// categoryAge is the modification time range of one classification's files.
+type categoryAge struct {
+	Classification models.MediaClassification
+	Label          string
+	Oldest, Newest time.Time
+}
+
+// ageCategories lists the classifications whose age range is reported, in
+// summary table order.
+var ageCategories = []struct {
+	class models.MediaClassification
+	label string
+}{
+	{models.MediaHealthy, "Healthy Media"},
+	{models.MediaAtRisk, "At Risk"},
+	{models.MediaOrphan, "Orphaned Media"},
+	{models.MediaRemovedFromArr, "Removed from Arr"},
+	{models.MediaOrphanedDownload, "Orphaned Downloads"},
+	{models.MediaHiddenFile, "Hidden Files"},
+	{models.MediaLostAndFound, "Lost+Found"},
+	{models.MediaOrphanedSidecar, "Orphaned Sidecars"},
+	{models.MediaOtherFile, "Other Files"},
+	{models.MediaExpectedUntracked, "Expected Untracked"},
+}
+
+// categoryAges returns the oldest and newest ModTime for each category in
+// ageCategories that has files, so a report can show whether findings built
+// up slowly or arrived in one recent batch.
+func categoryAges(classified []models.ClassifiedMedia) []categoryAge {
+	ranges := make(map[models.MediaClassification]*categoryAge)
+	for _, cm := range classified {
+		mod := cm.File.ModTime
+		if mod.IsZero() {
+			continue
+		}
+		r := ranges[cm.Classification]
+		if r == nil {
+			ranges[cm.Classification] = &categoryAge{Classification: cm.Classification, Oldest: mod, Newest: mod}
+			continue
+		}
+		if mod.Before(r.Oldest) {
+			r.Oldest = mod
+		}
+		if mod.After(r.Newest) {
+			r.Newest = mod
+		}
+	}
+
+	var ages []categoryAge
+	for _, c := range ageCategories {
+		if r := ranges[c.class]; r != nil {
+			r.Label = c.label
+			ages = append(ages, *r)
+		}
+	}
+	return ages
+}
+
+// ageDays returns the whole number of days since t.
+func ageDays(t time.Time) int {
+	return int(time.Since(t).Hours() / 24)
+}
+
+// sortSuspicious orders suspicious files most severe first, then by path.
+func sortSuspicious(files []models.SuspiciousFile) {
+	sort.Slice(files, func(i, j int) bool {
+		ri, rj := models.SuspiciousSeverityRank(files[i].Severity), models.SuspiciousSeverityRank(files[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return files[i].Path < files[j].Path
+	})
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Hour {
 		return fmt.Sprintf("%d minutes", int(d.Minutes()))
@@ -30,6 +123,72 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%d months", int(d.Hours()/24/30))
 }
 
+// formatCount renders n with thousands separators, e.g. 4,210.
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// percent returns n as a percentage of total, or 0 when total is 0.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(total)*1000) / 10
+}
+
+func formatPercent(pct float64) string {
+	return strconv.FormatFloat(pct, 'f', -1, 64) + "%"
+}
+
+// ReconciliationLine summarizes how many of a service's tracked files were
+// found on disk, e.g. "Sonarr tracks 4,210 files; 4,198 found on disk; 12 missing".
+// plainLabels replaces each emoji status marker when use_emoji is off.
+var plainLabels = map[string]string{
+	"✅":  "[OK]",
+	"⚠️": "[WARN]",
+	"❌":  "[FAIL]",
+	"⛔":  "[FAIL]",
+	"🚨":  "[ALERT]",
+	"💾":  "[DL]",
+	"👻":  "[HIDDEN]",
+	"🔧":  "[LOST]",
+	"🗒️": "[SIDECAR]",
+	"📎":  "[OTHER]",
+	"🗂️": "[EXPECTED]",
+	"🗑️": "[REMOVED]",
+	"📏":  "[SIZE]",
+	"🩺":  "[CORRUPT]",
+	"📦":  "[PACK]",
+	"🔒":  "[PRIVATE]",
+}
+
+// icon returns the emoji marker, or its plain-text label when emoji are off.
+func icon(emoji string, useEmoji bool) string {
+	if useEmoji {
+		return emoji
+	}
+	if label, ok := plainLabels[emoji]; ok {
+		return label
+	}
+	return emoji
+}
+
+func ReconciliationLine(r analysis.ArrReconciliation) string {
+	tracked := formatCount(r.Tracked) + " files"
+	if r.TrackedBytes > 0 {
+		tracked += " (" + formatBytes(r.TrackedBytes) + ")"
+	}
+	return fmt.Sprintf("%s tracks %s; %s found on disk; %s missing",
+		r.Service, tracked, formatCount(r.Found), formatCount(len(r.Missing)))
+}
+
 func formatBytes(b int64) string {
 	const (
 		KB = 1024
@@ -51,3 +210,55 @@ func formatBytes(b int64) string {
 		return fmt.Sprintf("%d B", b)
 	}
 }
+
+// Grouping modes for --group-by.
+const (
+	GroupByDir  = "dir"
+	GroupByShow = "show"
+)
+
+var seasonDirPattern = regexp.MustCompile(`(?i)^(season[ ._-]*\d+|specials|s\d{1,2})$`)
+
+// mediaGroup is a set of findings that share a parent directory or show.
+type mediaGroup struct {
+	Key   string
+	Files []models.ClassifiedMedia
+	Size  int64
+}
+
+// groupKey returns the directory a file is grouped under. For "show", season
+// folders are collapsed into their series directory, so a movie groups by its
+// own folder and an episode by its show.
+func groupKey(path, mode string) string {
+	dir := filepath.Dir(path)
+	if mode == GroupByShow && seasonDirPattern.MatchString(filepath.Base(dir)) {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// groupMedia groups findings by groupKey, sorted by key and then by path.
+func groupMedia(files []models.ClassifiedMedia, mode string) []mediaGroup {
+	index := make(map[string]int)
+	var groups []mediaGroup
+	for _, cm := range files {
+		key := groupKey(cm.File.Path, mode)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, mediaGroup{Key: key})
+		}
+		groups[i].Files = append(groups[i].Files, cm)
+		groups[i].Size += cm.File.Size
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	for _, g := range groups {
+		sort.Slice(g.Files, func(i, j int) bool {
+			return g.Files[i].File.Path < g.Files[j].File.Path
+		})
+	}
+	return groups
+}
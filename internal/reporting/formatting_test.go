@@ -0,0 +1,100 @@
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func assertNoTempFiles(t *testing.T, path string) {
+	t.Helper()
+	if leftover, _ := filepath.Glob(path + ".tmp*"); len(leftover) > 0 {
+		t.Errorf("temporary files left behind: %q", leftover)
+	}
+}
+
+func TestWriteFileAtomic_ReplacesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "latest.md")
+	if err := writeFileAtomic(path, []byte("old")); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+
+	// A reader that opened the old report keeps seeing it whole: the new
+	// one is renamed into place rather than written over it.
+	reader, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if err := (&MarkdownFormatter{}).WriteToPath("new", path); err != nil {
+		t.Fatalf("WriteToPath: %v", err)
+	}
+	if old, _ := io.ReadAll(reader); string(old) != "old" {
+		t.Errorf("open reader saw %q, want the old report intact", old)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new" {
+		t.Errorf("report = %q, want new", got)
+	}
+	assertNoTempFiles(t, path)
+}
+
+func TestWriteFileAtomic_FailureKeepsNoTemp(t *testing.T) {
+	dir := t.TempDir()
+	// A directory in the way makes the rename fail.
+	path := filepath.Join(dir, "latest.json")
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&JSONFormatter{}).WriteToPath([]byte("{}"), path); err == nil {
+		t.Fatal("WriteToPath over a directory succeeded")
+	}
+	assertNoTempFiles(t, path)
+}
+
+// Overlapping runs (cron during watch, a multi-config batch) may write the
+// same report at once. Each must rename only its own complete output.
+func TestWriteFileAtomic_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "latest.md")
+	const writers = 8
+	payloads := make([][]byte, writers)
+	for i := range payloads {
+		payloads[i] = bytes.Repeat([]byte(fmt.Sprintf("run %d\n", i)), 64<<10)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for _, data := range payloads {
+		wg.Add(1)
+		go func(data []byte) {
+			defer wg.Done()
+			errs <- writeFileAtomic(path, data)
+		}(data)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("writeFileAtomic: %v", err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	whole := false
+	for _, data := range payloads {
+		if bytes.Equal(got, data) {
+			whole = true
+		}
+	}
+	if !whole {
+		t.Errorf("report (%d bytes) is not any single writer's complete output", len(got))
+	}
+	assertNoTempFiles(t, path)
+}
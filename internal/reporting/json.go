@@ -15,36 +15,104 @@ import (
 
 // JSONReport is a script-friendly output format
 type JSONReport struct {
-	GeneratedAt          string                   `json:"generated_at"`
-	Duration             float64                  `json:"duration_seconds"`
-	Summary              JSONSummary              `json:"summary"`
-	DiskUsage            JSONDiskUsage            `json:"disk_usage"`
-	ConnectionStatus     []analysis.ServiceStatus `json:"connection_status"`
-	OrphanedMedia        []JSONFileEntry          `json:"orphaned_media"`
-	OrphanedDownloads    []JSONFileEntry          `json:"orphaned_downloads"`
-	OrphanedDirectories  []JSONDirectoryEntry     `json:"orphaned_directories"`
-	AtRisk               []JSONFileEntry          `json:"at_risk"`
-	HiddenFiles          []JSONFileEntry          `json:"hidden_files"`
-	LostAndFound         []JSONLostFoundEntry     `json:"lost_and_found"`
-	SuspiciousFiles      []JSONSuspiciousEntry    `json:"suspicious_files"`
-	UnlinkedTorrents     []JSONTorrentEntry       `json:"unlinked_torrents"`
-	PermissionIssues     []JSONPermissionEntry    `json:"permission_issues"`
+	GeneratedAt         string                   `json:"generated_at"`
+	RunLabel            string                   `json:"run_label,omitempty"`
+	Duration            float64                  `json:"duration_seconds"`
+	Warnings            []string                 `json:"warnings,omitempty"`
+	FailedServices      []string                 `json:"failed_services,omitempty"`
+	Summary             JSONSummary              `json:"summary"`
+	DiskUsage           JSONDiskUsage            `json:"disk_usage"`
+	ConnectionStatus    []analysis.ServiceStatus `json:"connection_status"`
+	ArrReconciliation   []JSONArrReconciliation  `json:"arr_reconciliation"`
+	OrphanedMedia       []JSONFileEntry          `json:"orphaned_media"`
+	RemovedFromArr      []JSONFileEntry          `json:"removed_from_arr"`
+	OrphanedDownloads   []JSONFileEntry          `json:"orphaned_downloads"`
+	OrphanedDirectories []JSONDirectoryEntry     `json:"orphaned_directories"`
+	CaseDuplicates      []JSONCaseDuplicate      `json:"case_duplicates"`
+	CopiedImports       []JSONCopiedImport       `json:"copied_imports"`
+	PartialSeasonPacks  []JSONSeasonPack         `json:"partial_season_packs"`
+	// Fingerprints maps each classified file's path to its content
+	// fingerprint when [outputs].fingerprint or --fingerprint is set.
+	Fingerprints           map[string]string       `json:"fingerprints,omitempty"`
+	UnimportedDownloads    []JSONFileEntry         `json:"unimported_downloads"`
+	AtRisk                 []JSONFileEntry         `json:"at_risk"`
+	HiddenFiles            []JSONFileEntry         `json:"hidden_files"`
+	OrphanedSidecars       []JSONFileEntry         `json:"orphaned_sidecars"`
+	OtherFiles             []JSONFileEntry         `json:"other_files"`
+	ExpectedUntracked      []JSONFileEntry         `json:"expected_untracked"`
+	AtRiskGroups           []JSONFileGroup         `json:"at_risk_groups,omitempty"`
+	OrphanedMediaGroups    []JSONFileGroup         `json:"orphaned_media_groups,omitempty"`
+	OrphanedDownloadGroups []JSONFileGroup         `json:"orphaned_download_groups,omitempty"`
+	LostAndFound           []JSONLostFoundEntry    `json:"lost_and_found"`
+	SuspiciousFiles        []JSONSuspiciousEntry   `json:"suspicious_files"`
+	CorruptFiles           []JSONCorruptEntry      `json:"corrupt_files"`
+	SizeMismatches         []JSONSizeMismatchEntry `json:"size_mismatches"`
+	UnlinkedTorrents       []JSONTorrentEntry      `json:"unlinked_torrents"`
+	OutOfScopeTorrents     []JSONTorrentEntry      `json:"out_of_scope_torrents"`
+	PermissionIssues       []JSONPermissionEntry   `json:"permission_issues"`
+	// TrackedPaths lists the files Arr tracked this run, so the next run can
+	// tell which of its orphans were removed from Arr.
+	TrackedPaths []string `json:"tracked_paths,omitempty"`
 }
 
 // JSONSummary provides high-level counts
 type JSONSummary struct {
-	TotalFiles            int    `json:"total_files"`
-	HealthyCount          int    `json:"healthy_count"`
-	AtRiskCount           int    `json:"at_risk_count"`
-	OrphanCount           int    `json:"orphan_count"`
-	OrphanedDownloadCount int    `json:"orphaned_download_count"`
-	HiddenFileCount       int    `json:"hidden_file_count"`
-	LostAndFoundCount     int    `json:"lost_and_found_count"`
-	SuspiciousCount       int    `json:"suspicious_count"`
-	PermissionErrors      int    `json:"permission_errors"`
-	PermissionWarnings    int    `json:"permission_warnings"`
-	TotalOrphanSizeBytes  int64  `json:"total_orphan_size_bytes"`
-	TotalOrphanSizeHuman  string `json:"total_orphan_size_human"`
+	TotalFiles             int    `json:"total_files"`
+	HealthyCount           int    `json:"healthy_count"`
+	AtRiskCount            int    `json:"at_risk_count"`
+	OrphanCount            int    `json:"orphan_count"`
+	OrphanedDownloadCount  int    `json:"orphaned_download_count"`
+	HiddenFileCount        int    `json:"hidden_file_count"`
+	LostAndFoundCount      int    `json:"lost_and_found_count"`
+	OtherFileCount         int    `json:"other_file_count"`
+	ExpectedUntrackedCount int    `json:"expected_untracked_count"`
+	RemovedFromArrCount    int    `json:"removed_from_arr_count"`
+	OrphanedSidecarCount   int    `json:"orphaned_sidecar_count"`
+	SuspiciousCount        int    `json:"suspicious_count"`
+	CorruptCount           int    `json:"corrupt_count"`
+	SizeMismatchCount      int    `json:"size_mismatch_count"`
+	CaseDuplicateCount     int    `json:"case_duplicate_count"`
+	CopiedImportCount      int    `json:"copied_import_count"`
+	CopiedImportWaste      int64  `json:"copied_import_wasted_bytes"`
+	ArrUnmappedChecked     bool   `json:"arr_unmapped_checked"`
+	ArrConfirmedOrphans    int    `json:"arr_confirmed_orphans"`
+	OutOfScopeTorrentCount int    `json:"out_of_scope_torrent_count"`
+	PartialSeasonPackCount int    `json:"partial_season_pack_count"`
+	UnimportedCount        int    `json:"unimported_count"`
+	UnimportedSizeBytes    int64  `json:"unimported_size_bytes"`
+	UnimportedSizeHuman    string `json:"unimported_size_human"`
+	VerifiedCount          int    `json:"verified_count"`
+	PermissionErrors       int    `json:"permission_errors"`
+	PermissionWarnings     int    `json:"permission_warnings"`
+	BaselineSuppressed     int    `json:"baseline_suppressed"`
+	TotalOrphanSizeBytes   int64  `json:"total_orphan_size_bytes"`
+	TotalOrphanSizeHuman   string `json:"total_orphan_size_human"`
+	// Percentages of TotalFiles, rounded to one decimal; 0 when there are no files.
+	HealthyPct          float64 `json:"healthy_pct"`
+	AtRiskPct           float64 `json:"at_risk_pct"`
+	OrphanPct           float64 `json:"orphan_pct"`
+	OrphanedDownloadPct float64 `json:"orphaned_download_pct"`
+	// Ages maps each classification with files to its oldest and newest
+	// modification time.
+	Ages map[string]JSONCategoryAge `json:"ages,omitempty"`
+}
+
+// JSONCategoryAge is the modification time range of one classification
+type JSONCategoryAge struct {
+	OldestModTime string `json:"oldest_mod_time"`
+	NewestModTime string `json:"newest_mod_time"`
+	OldestAgeDays int    `json:"oldest_age_days"`
+	NewestAgeDays int    `json:"newest_age_days"`
+}
+
+// JSONArrReconciliation compares an Arr service's tracked files with the scan
+type JSONArrReconciliation struct {
+	Service      string   `json:"service"`
+	Tracked      int      `json:"tracked"`
+	TrackedBytes int64    `json:"tracked_bytes"`
+	Found        int      `json:"found"`
+	MissingCount int      `json:"missing_count"`
+	Missing      []string `json:"missing,omitempty"`
 }
 
 // JSONDiskUsage shows actual vs logical disk usage
@@ -56,14 +124,47 @@ type JSONDiskUsage struct {
 	DedupRatio       float64 `json:"dedup_ratio"`
 }
 
+// JSONCaseDuplicate lists sibling names that differ only by case
+type JSONCaseDuplicate struct {
+	Parent string   `json:"parent"`
+	Names  []string `json:"names"`
+	IsDir  bool     `json:"is_dir"`
+}
+
+// JSONCopiedImport is a library file stored again under the torrent root
+type JSONCopiedImport struct {
+	Path          string   `json:"path"`
+	SizeBytes     int64    `json:"size_bytes"`
+	TorrentCopies []string `json:"torrent_copies"`
+	WastedBytes   int64    `json:"wasted_bytes"`
+}
+
+// JSONSeasonPack is one season of a torrent with both imported and leftover
+// episodes
+type JSONSeasonPack struct {
+	Torrent       string               `json:"torrent"`
+	Series        string               `json:"series"`
+	Season        int                  `json:"season"`
+	ImportedCount int                  `json:"imported_count"`
+	LeftoverCount int                  `json:"leftover_count"`
+	Imported      []JSONSeasonPackFile `json:"imported"`
+	Leftover      []string             `json:"leftover"`
+}
+
+// JSONSeasonPackFile is an imported episode and its library copy
+type JSONSeasonPackFile struct {
+	TorrentPath string `json:"torrent_path"`
+	LibraryPath string `json:"library_path,omitempty"`
+}
+
 // JSONDirectoryEntry represents a directory containing orphaned files
 type JSONDirectoryEntry struct {
-	Path          string `json:"path"`
-	OrphanedCount int    `json:"orphaned_count"`
-	TotalCount    int    `json:"total_count"`
-	TotalSize     int64  `json:"total_size_bytes"`
+	Path           string `json:"path"`
+	OrphanedCount  int    `json:"orphaned_count"`
+	TotalCount     int    `json:"total_count"`
+	TotalSize      int64  `json:"total_size_bytes"`
 	TotalSizeHuman string `json:"total_size_human"`
-	FullyOrphaned bool   `json:"fully_orphaned"`
+	FullyOrphaned  bool   `json:"fully_orphaned"`
 }
 
 // JSONLostFoundEntry represents a file from an extra scan path
@@ -86,23 +187,60 @@ type JSONFileEntry struct {
 	Age            string `json:"age"`
 	Hardlinks      int    `json:"hardlinks"`
 	Classification string `json:"classification"`
+	ReasonCode     string `json:"reason_code"`
 	Reason         string `json:"reason"`
 	ArrSource      string `json:"arr_source,omitempty"`
+	// ArrConfirmed is set on orphans whose folder Sonarr/Radarr also report
+	// as unmapped.
+	ArrConfirmed bool `json:"arr_confirmed,omitempty"`
+}
+
+// JSONFileGroup holds findings under one directory or show when --group-by is set
+type JSONFileGroup struct {
+	Group     string          `json:"group"`
+	Count     int             `json:"count"`
+	SizeBytes int64           `json:"size_bytes"`
+	SizeHuman string          `json:"size_human"`
+	Files     []JSONFileEntry `json:"files"`
 }
 
 // JSONSuspiciousEntry represents suspicious files
 type JSONSuspiciousEntry struct {
-	Path   string `json:"path"`
-	Reason string `json:"reason"`
+	Path       string `json:"path"`
+	Severity   string `json:"severity"`
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason"`
+	SHA256     string `json:"sha256,omitempty"`
 }
 
-// JSONTorrentEntry represents unlinked torrents
+// JSONCorruptEntry represents files that failed ffprobe verification
+type JSONCorruptEntry struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size_bytes"`
+	SizeHuman  string `json:"size_human"`
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason"`
+}
+
+// JSONSizeMismatchEntry represents files whose size differs from Arr's record
+type JSONSizeMismatchEntry struct {
+	Path          string `json:"path"`
+	ArrSource     string `json:"arr_source"`
+	DiskSizeBytes int64  `json:"disk_size_bytes"`
+	ArrSizeBytes  int64  `json:"arr_size_bytes"`
+}
+
+// JSONTorrentEntry represents unlinked and out-of-scope torrents
 type JSONTorrentEntry struct {
 	Path      string `json:"path"`
 	Name      string `json:"name"`
 	Size      int64  `json:"size_bytes"`
 	SizeHuman string `json:"size_human"`
 	Completed string `json:"completed"`
+	// Seeding is set for torrents that are still uploading.
+	Seeding  bool     `json:"seeding"`
+	Private  bool     `json:"private"`
+	Trackers []string `json:"trackers,omitempty"`
 }
 
 // JSONPermissionEntry represents permission issues
@@ -113,31 +251,113 @@ type JSONPermissionEntry struct {
 	FixHint  string `json:"fix_hint"`
 }
 
-type JSONFormatter struct{}
+type JSONFormatter struct {
+	groupBy string
+	label   string
+}
 
 func NewJSONFormatter() *JSONFormatter {
 	return &JSONFormatter{}
 }
 
+// SetGroupBy adds *_groups arrays that nest findings by directory or show.
+// The flat lists are always emitted so existing scripts keep working.
+func (jf *JSONFormatter) SetGroupBy(mode string) {
+	jf.groupBy = mode
+}
+
+// SetLabel records the run label as run_label.
+func (jf *JSONFormatter) SetLabel(label string) {
+	jf.label = label
+}
+
+func (jf *JSONFormatter) groups(files []models.ClassifiedMedia) []JSONFileGroup {
+	if jf.groupBy == "" {
+		return nil
+	}
+	var out []JSONFileGroup
+	for _, g := range groupMedia(files, jf.groupBy) {
+		group := JSONFileGroup{
+			Group:     g.Key,
+			Count:     len(g.Files),
+			SizeBytes: g.Size,
+			SizeHuman: formatBytes(g.Size),
+		}
+		for _, cm := range g.Files {
+			group.Files = append(group.Files, JSONFileEntry{
+				Path:           cm.File.Path,
+				Size:           cm.File.Size,
+				SizeHuman:      formatBytes(cm.File.Size),
+				ModTime:        cm.File.ModTime.Format(time.RFC3339),
+				Age:            formatDuration(time.Since(cm.File.ModTime)),
+				Hardlinks:      cm.File.HardlinkCount,
+				Classification: string(cm.Classification),
+				ReasonCode:     string(cm.Code),
+				Reason:         cm.Reason,
+				ArrSource:      cm.ArrSource,
+				ArrConfirmed:   cm.ArrConfirmed,
+			})
+		}
+		out = append(out, group)
+	}
+	return out
+}
+
 func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Config, duration time.Duration) ([]byte, error) {
 	report := JSONReport{
-		GeneratedAt:      time.Now().Format(time.RFC3339),
+		GeneratedAt:      cfg.Outputs.Now().Format(time.RFC3339),
+		RunLabel:         jf.label,
 		Duration:         duration.Seconds(),
 		ConnectionStatus: result.ConnectionStatus,
+		Warnings:         result.Warnings,
+		FailedServices:   result.FailedServices(),
 	}
 
 	// Build summary
 	report.Summary = JSONSummary{
-		TotalFiles:            result.Summary.TotalFiles,
-		HealthyCount:          result.Summary.HealthyCount,
-		AtRiskCount:           result.Summary.AtRiskCount,
-		OrphanCount:           result.Summary.OrphanCount,
-		OrphanedDownloadCount: result.Summary.OrphanedDownloadCount,
-		HiddenFileCount:       result.Summary.HiddenFileCount,
-		LostAndFoundCount:     result.Summary.LostAndFoundCount,
-		SuspiciousCount:       result.Summary.SuspiciousCount,
-		PermissionErrors:      result.Summary.PermissionErrors,
-		PermissionWarnings:    result.Summary.PermissionWarnings,
+		TotalFiles:             result.Summary.TotalFiles,
+		HealthyCount:           result.Summary.HealthyCount,
+		AtRiskCount:            result.Summary.AtRiskCount,
+		OrphanCount:            result.Summary.OrphanCount,
+		OrphanedDownloadCount:  result.Summary.OrphanedDownloadCount,
+		HiddenFileCount:        result.Summary.HiddenFileCount,
+		LostAndFoundCount:      result.Summary.LostAndFoundCount,
+		OtherFileCount:         result.Summary.OtherFileCount,
+		ExpectedUntrackedCount: result.Summary.ExpectedUntrackedCount,
+		RemovedFromArrCount:    result.Summary.RemovedFromArrCount,
+		OrphanedSidecarCount:   result.Summary.OrphanedSidecarCount,
+		SuspiciousCount:        result.Summary.SuspiciousCount,
+		CorruptCount:           result.Summary.CorruptCount,
+		SizeMismatchCount:      result.Summary.SizeMismatchCount,
+		CaseDuplicateCount:     result.Summary.CaseDuplicateCount,
+		CopiedImportCount:      result.Summary.CopiedImportCount,
+		CopiedImportWaste:      result.Summary.CopiedImportWaste,
+		ArrUnmappedChecked:     result.ArrUnmappedChecked,
+		ArrConfirmedOrphans:    result.Summary.ArrConfirmedOrphans,
+		OutOfScopeTorrentCount: result.Summary.OutOfScopeTorrentCount,
+		PartialSeasonPackCount: result.Summary.PartialSeasonPackCount,
+		UnimportedCount:        result.Summary.UnimportedCount,
+		UnimportedSizeBytes:    result.Summary.UnimportedSize,
+		UnimportedSizeHuman:    formatBytes(result.Summary.UnimportedSize),
+		VerifiedCount:          result.Summary.VerifiedCount,
+		PermissionErrors:       result.Summary.PermissionErrors,
+		PermissionWarnings:     result.Summary.PermissionWarnings,
+		BaselineSuppressed:     result.Summary.BaselineSuppressed,
+		HealthyPct:             percent(result.Summary.HealthyCount, result.Summary.TotalFiles),
+		AtRiskPct:              percent(result.Summary.AtRiskCount, result.Summary.TotalFiles),
+		OrphanPct:              percent(result.Summary.OrphanCount, result.Summary.TotalFiles),
+		OrphanedDownloadPct:    percent(result.Summary.OrphanedDownloadCount, result.Summary.TotalFiles),
+	}
+	for _, a := range categoryAges(result.ClassifiedMedia) {
+		if report.Summary.Ages == nil {
+			report.Summary.Ages = make(map[string]JSONCategoryAge)
+		}
+		report.Summary.Ages[string(a.Classification)] = JSONCategoryAge{
+			OldestModTime: a.Oldest.Format(time.RFC3339),
+			NewestModTime: a.Newest.Format(time.RFC3339),
+			OldestAgeDays: ageDays(a.Oldest),
+			NewestAgeDays: ageDays(a.Newest),
+		}
 	}
 
 	// Build disk usage
@@ -153,6 +373,17 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 		DedupRatio:       dedupRatio,
 	}
 
+	for _, r := range result.ArrReconciliation {
+		report.ArrReconciliation = append(report.ArrReconciliation, JSONArrReconciliation{
+			Service:      r.Service,
+			Tracked:      r.Tracked,
+			TrackedBytes: r.TrackedBytes,
+			Found:        r.Found,
+			MissingCount: len(r.Missing),
+			Missing:      r.Missing,
+		})
+	}
+
 	// Collect orphaned media
 	var orphanTotalSize int64
 	orphans := filterByClassification(result.ClassifiedMedia, models.MediaOrphan)
@@ -169,12 +400,41 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 			Age:            formatDuration(time.Since(cm.File.ModTime)),
 			Hardlinks:      cm.File.HardlinkCount,
 			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
 			Reason:         cm.Reason,
 			ArrSource:      cm.ArrSource,
+			ArrConfirmed:   cm.ArrConfirmed,
 		})
 	}
 	report.Summary.TotalOrphanSizeBytes = orphanTotalSize
 	report.Summary.TotalOrphanSizeHuman = formatBytes(orphanTotalSize)
+	report.OrphanedMediaGroups = jf.groups(orphans)
+
+	// Collect files removed from Arr since the previous run
+	removed := filterByClassification(result.ClassifiedMedia, models.MediaRemovedFromArr)
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].File.Path < removed[j].File.Path
+	})
+	for _, cm := range removed {
+		report.RemovedFromArr = append(report.RemovedFromArr, JSONFileEntry{
+			Path:           cm.File.Path,
+			Size:           cm.File.Size,
+			SizeHuman:      formatBytes(cm.File.Size),
+			ModTime:        cm.File.ModTime.Format(time.RFC3339),
+			Age:            formatDuration(time.Since(cm.File.ModTime)),
+			Hardlinks:      cm.File.HardlinkCount,
+			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
+			Reason:         cm.Reason,
+		})
+	}
+
+	for _, cm := range result.ClassifiedMedia {
+		if cm.KnownToArr && (cm.Classification == models.MediaHealthy || cm.Classification == models.MediaAtRisk) {
+			report.TrackedPaths = append(report.TrackedPaths, cm.File.Path)
+		}
+	}
+	sort.Strings(report.TrackedPaths)
 
 	// Collect orphaned downloads
 	orphanedDownloads := filterByClassification(result.ClassifiedMedia, models.MediaOrphanedDownload)
@@ -190,10 +450,13 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 			Age:            formatDuration(time.Since(cm.File.ModTime)),
 			Hardlinks:      cm.File.HardlinkCount,
 			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
 			Reason:         cm.Reason,
 		})
 	}
 
+	report.OrphanedDownloadGroups = jf.groups(orphanedDownloads)
+
 	// Collect at-risk files
 	atRisk := filterByClassification(result.ClassifiedMedia, models.MediaAtRisk)
 	sort.Slice(atRisk, func(i, j int) bool {
@@ -208,11 +471,29 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 			Age:            formatDuration(time.Since(cm.File.ModTime)),
 			Hardlinks:      cm.File.HardlinkCount,
 			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
 			Reason:         cm.Reason,
 			ArrSource:      cm.ArrSource,
 		})
 	}
 
+	report.AtRiskGroups = jf.groups(atRisk)
+
+	// Collect downloads never hardlinked into the library
+	for _, f := range result.UnimportedDownloads {
+		report.UnimportedDownloads = append(report.UnimportedDownloads, JSONFileEntry{
+			Path:           f.Path,
+			Size:           f.Size,
+			SizeHuman:      formatBytes(f.Size),
+			ModTime:        f.ModTime.Format(time.RFC3339),
+			Age:            formatDuration(time.Since(f.ModTime)),
+			Hardlinks:      f.HardlinkCount,
+			Classification: "unimported_download",
+			ReasonCode:     string(models.ReasonNotImported),
+			Reason:         "Torrent file shares no inode with any file under the media root",
+		})
+	}
+
 	// Collect hidden files
 	hiddenFiles := filterByClassification(result.ClassifiedMedia, models.MediaHiddenFile)
 	sort.Slice(hiddenFiles, func(i, j int) bool {
@@ -227,6 +508,64 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 			Age:            formatDuration(time.Since(cm.File.ModTime)),
 			Hardlinks:      cm.File.HardlinkCount,
 			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
+			Reason:         cm.Reason,
+		})
+	}
+
+	// Collect other files
+	otherFiles := filterByClassification(result.ClassifiedMedia, models.MediaOtherFile)
+	sort.Slice(otherFiles, func(i, j int) bool {
+		return otherFiles[i].File.Path < otherFiles[j].File.Path
+	})
+	for _, cm := range otherFiles {
+		report.OtherFiles = append(report.OtherFiles, JSONFileEntry{
+			Path:           cm.File.Path,
+			Size:           cm.File.Size,
+			SizeHuman:      formatBytes(cm.File.Size),
+			ModTime:        cm.File.ModTime.Format(time.RFC3339),
+			Age:            formatDuration(time.Since(cm.File.ModTime)),
+			Hardlinks:      cm.File.HardlinkCount,
+			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
+			Reason:         cm.Reason,
+		})
+	}
+
+	// Collect expected untracked files
+	expected := filterByClassification(result.ClassifiedMedia, models.MediaExpectedUntracked)
+	sort.Slice(expected, func(i, j int) bool {
+		return expected[i].File.Path < expected[j].File.Path
+	})
+	for _, cm := range expected {
+		report.ExpectedUntracked = append(report.ExpectedUntracked, JSONFileEntry{
+			Path:           cm.File.Path,
+			Size:           cm.File.Size,
+			SizeHuman:      formatBytes(cm.File.Size),
+			ModTime:        cm.File.ModTime.Format(time.RFC3339),
+			Age:            formatDuration(time.Since(cm.File.ModTime)),
+			Hardlinks:      cm.File.HardlinkCount,
+			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
+			Reason:         cm.Reason,
+		})
+	}
+
+	// Collect orphaned sidecars
+	sidecars := filterByClassification(result.ClassifiedMedia, models.MediaOrphanedSidecar)
+	sort.Slice(sidecars, func(i, j int) bool {
+		return sidecars[i].File.Path < sidecars[j].File.Path
+	})
+	for _, cm := range sidecars {
+		report.OrphanedSidecars = append(report.OrphanedSidecars, JSONFileEntry{
+			Path:           cm.File.Path,
+			Size:           cm.File.Size,
+			SizeHuman:      formatBytes(cm.File.Size),
+			ModTime:        cm.File.ModTime.Format(time.RFC3339),
+			Age:            formatDuration(time.Since(cm.File.ModTime)),
+			Hardlinks:      cm.File.HardlinkCount,
+			Classification: string(cm.Classification),
+			ReasonCode:     string(cm.Code),
 			Reason:         cm.Reason,
 		})
 	}
@@ -260,14 +599,78 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 		})
 	}
 
+	for _, cm := range result.ClassifiedMedia {
+		if cm.File.Fingerprint == "" {
+			continue
+		}
+		if report.Fingerprints == nil {
+			report.Fingerprints = make(map[string]string)
+		}
+		report.Fingerprints[cm.File.Path] = cm.File.Fingerprint
+	}
+
+	for _, d := range result.CaseDuplicates {
+		report.CaseDuplicates = append(report.CaseDuplicates, JSONCaseDuplicate{
+			Parent: d.Parent,
+			Names:  d.Names,
+			IsDir:  d.IsDir,
+		})
+	}
+
+	for _, c := range result.CopiedImports {
+		report.CopiedImports = append(report.CopiedImports, JSONCopiedImport{
+			Path:          c.Path,
+			SizeBytes:     c.Size,
+			TorrentCopies: c.TorrentCopies,
+			WastedBytes:   c.WastedBytes,
+		})
+	}
+
+	for _, p := range result.PartialSeasonPacks {
+		entry := JSONSeasonPack{
+			Torrent:       p.Torrent,
+			Series:        p.Series,
+			Season:        p.Season,
+			ImportedCount: len(p.Imported),
+			LeftoverCount: len(p.Leftover),
+			Leftover:      p.Leftover,
+		}
+		for _, f := range p.Imported {
+			entry.Imported = append(entry.Imported, JSONSeasonPackFile{TorrentPath: f.TorrentPath, LibraryPath: f.LibraryPath})
+		}
+		report.PartialSeasonPacks = append(report.PartialSeasonPacks, entry)
+	}
+
 	// Collect suspicious files
-	sort.Slice(result.SuspiciousFiles, func(i, j int) bool {
-		return result.SuspiciousFiles[i].Path < result.SuspiciousFiles[j].Path
-	})
+	sortSuspicious(result.SuspiciousFiles)
 	for _, sf := range result.SuspiciousFiles {
 		report.SuspiciousFiles = append(report.SuspiciousFiles, JSONSuspiciousEntry{
-			Path:   sf.Path,
-			Reason: sf.Reason,
+			Path:       sf.Path,
+			Severity:   sf.Severity,
+			ReasonCode: string(sf.Code),
+			Reason:     sf.Reason,
+			SHA256:     sf.SHA256,
+		})
+	}
+
+	// Collect corrupt files
+	for _, cf := range result.CorruptFiles {
+		report.CorruptFiles = append(report.CorruptFiles, JSONCorruptEntry{
+			Path:       cf.Path,
+			Size:       cf.Size,
+			SizeHuman:  formatBytes(cf.Size),
+			ReasonCode: string(cf.Code),
+			Reason:     cf.Reason,
+		})
+	}
+
+	// Collect size mismatches
+	for _, sm := range result.SizeMismatches {
+		report.SizeMismatches = append(report.SizeMismatches, JSONSizeMismatchEntry{
+			Path:          sm.Path,
+			ArrSource:     sm.ArrSource,
+			DiskSizeBytes: sm.DiskSize,
+			ArrSizeBytes:  sm.ArrSize,
 		})
 	}
 
@@ -288,6 +691,26 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 			Size:      t.Size,
 			SizeHuman: formatBytes(t.Size),
 			Completed: completed,
+			Seeding:   t.State == models.StateSeeding,
+			Private:   t.IsPrivate,
+			Trackers:  t.Trackers,
+		})
+	}
+
+	for _, t := range result.OutOfScopeTorrents {
+		completed := "unknown"
+		if !t.CompletedOn.IsZero() {
+			completed = formatDuration(time.Since(t.CompletedOn)) + " ago"
+		}
+		report.OutOfScopeTorrents = append(report.OutOfScopeTorrents, JSONTorrentEntry{
+			Path:      filepath.Join(t.SavePath, t.Name),
+			Name:      t.Name,
+			Size:      t.Size,
+			SizeHuman: formatBytes(t.Size),
+			Completed: completed,
+			Seeding:   t.State == models.StateSeeding,
+			Private:   t.IsPrivate,
+			Trackers:  t.Trackers,
 		})
 	}
 
@@ -295,7 +718,7 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 	for _, issue := range result.PermissionIssues {
 		report.PermissionIssues = append(report.PermissionIssues, JSONPermissionEntry{
 			Path:     issue.Path,
-			Issue:    issue.Issue,
+			Issue:    string(issue.Issue),
 			Severity: issue.Severity,
 			FixHint:  issue.FixHint,
 		})
@@ -304,6 +727,14 @@ func (jf *JSONFormatter) Format(result *analysis.AnalysisResult, cfg *config.Con
 	return json.MarshalIndent(report, "", "  ")
 }
 
+// WriteToPath writes the report to exactly path, overwriting any previous run.
+func (jf *JSONFormatter) WriteToPath(data []byte, path string) error {
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
+
 func (jf *JSONFormatter) WriteToFile(data []byte, reportDir string) (string, error) {
 	if err := os.MkdirAll(reportDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create report directory: %w", err)
@@ -15,31 +15,166 @@ import (
 	"github.com/jdpx/auditarr/internal/utils"
 )
 
-type MarkdownFormatter struct{}
+type MarkdownFormatter struct {
+	groupBy string
+	label   string
+}
 
 func NewMarkdownFormatter() *MarkdownFormatter {
 	return &MarkdownFormatter{}
 }
 
+// SetGroupBy nests at-risk and orphaned findings under their parent
+// directory ("dir") or series/movie folder ("show") instead of a flat table.
+func (mf *MarkdownFormatter) SetGroupBy(mode string) {
+	mf.groupBy = mode
+}
+
+// SetLabel names the run in the report header, e.g. "nightly-prod".
+func (mf *MarkdownFormatter) SetLabel(label string) {
+	mf.label = label
+}
+
+// writeGroups renders groups as a nested list with per-group totals. Only
+// the first shown files are listed; group totals still count every file.
+func (mf *MarkdownFormatter) writeGroups(buf *bytes.Buffer, files []models.ClassifiedMedia, shown int, detail func(cm models.ClassifiedMedia) string) {
+	for _, g := range groupMedia(files, mf.groupBy) {
+		if shown <= 0 {
+			break
+		}
+		buf.WriteString(fmt.Sprintf("- **`%s`** — %d file(s), %s\n", escapeMarkdown(g.Key), len(g.Files), formatBytes(g.Size)))
+		for _, cm := range g.Files[:min(shown, len(g.Files))] {
+			rel, err := filepath.Rel(g.Key, cm.File.Path)
+			if err != nil {
+				rel = cm.File.Path
+			}
+			buf.WriteString(fmt.Sprintf("  - `%s` — %s\n", escapeMarkdown(rel), detail(cm)))
+		}
+		shown -= len(g.Files)
+	}
+	buf.WriteString("\n")
+}
+
 func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config.Config, duration time.Duration) string {
 	var buf bytes.Buffer
 
+	// legacy reproduces the version 1 layout for downstream parsers: the
+	// original sections and columns only.
+	legacy := cfg.Outputs.MarkdownVersion == 1
+	useEmoji := cfg.Outputs.EmojiEnabled()
+	limit := cfg.Outputs.MaxSectionEntries
+
+	generated := cfg.Outputs.FormatTimestamp(time.Now())
+	if legacy && cfg.Outputs.TimestampFormat == "" {
+		generated = cfg.Outputs.Now().Format("2006-01-02 15:04:05")
+	}
+
 	buf.WriteString("# Media Audit Report\n\n")
-	buf.WriteString(fmt.Sprintf("**Generated**: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+	if mf.label != "" && !legacy {
+		buf.WriteString(fmt.Sprintf("**Run**: %s\n\n", mf.label))
+	}
+	buf.WriteString(fmt.Sprintf("**Generated**: %s\n\n", generated))
 	buf.WriteString(fmt.Sprintf("**Duration**: %.1f seconds\n\n", duration.Seconds()))
 
+	if failed := result.FailedServices(); len(failed) > 0 && !legacy {
+		buf.WriteString("## " + icon("⛔", useEmoji) + " Incomplete Audit\n\n")
+		buf.WriteString(fmt.Sprintf("> **%d of %d service(s) failed**: %s\n>\n", len(failed), len(result.ConnectionStatus), strings.Join(failed, ", ")))
+		buf.WriteString("> Findings that depend on these services are missing or wrong. An empty or clean-looking report does **not** mean the library is healthy; see Service Connections below.\n\n")
+	}
+
+	if len(result.Warnings) > 0 && !legacy {
+		buf.WriteString("## " + icon("⚠️", useEmoji) + " Warnings\n\n")
+		for _, w := range result.Warnings {
+			buf.WriteString(fmt.Sprintf("> **Warning**: %s\n>\n", w))
+		}
+		buf.WriteString("\n")
+	}
+
+	total := result.Summary.TotalFiles
+	// Share is each category's portion of TotalFiles; counts that are not
+	// file classifications (suspicious, size mismatch, corrupt) have none.
+	summaryRow := func(category string, count int, share bool, status, description string) {
+		status = icon(status, useEmoji)
+		if legacy {
+			buf.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", category, count, status, description))
+			return
+		}
+		pct := "—"
+		if share {
+			pct = formatPercent(percent(count, total))
+		}
+		buf.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s |\n", category, count, pct, status, description))
+	}
+
 	buf.WriteString("## Summary\n\n")
-	buf.WriteString("| Category | Count | Status | Description |\n")
-	buf.WriteString("|----------|-------|--------|-------------|\n")
-	buf.WriteString(fmt.Sprintf("| Healthy Media | %d | ✅ | Tracked by Arr and hardlinked to torrent |\n", result.Summary.HealthyCount))
-	buf.WriteString(fmt.Sprintf("| At Risk | %d | ⚠️ | Tracked by Arr but NOT hardlinked (no torrent protection) |\n", result.Summary.AtRiskCount))
-	buf.WriteString(fmt.Sprintf("| Orphaned Media | %d | ❌ | Not tracked by Arr (outside grace window) |\n", result.Summary.OrphanCount))
-	buf.WriteString(fmt.Sprintf("| Orphaned Downloads | %d | 💾 | Files in torrent dir not hardlinked or tracked |\n", result.Summary.OrphanedDownloadCount))
-	buf.WriteString(fmt.Sprintf("| Hidden Files | %d | 👻 | Hidden dot-files (e.g. .parts fragments) |\n", result.Summary.HiddenFileCount))
-	buf.WriteString(fmt.Sprintf("| Lost+Found | %d | 🔧 | Files in extra scan paths (e.g. lost+found) |\n", result.Summary.LostAndFoundCount))
-	buf.WriteString(fmt.Sprintf("| Suspicious Files | %d | 🚨 | Suspicious extensions detected |\n", result.Summary.SuspiciousCount))
+	if legacy {
+		buf.WriteString("| Category | Count | Status | Description |\n")
+		buf.WriteString("|----------|-------|--------|-------------|\n")
+	} else {
+		if total > 0 {
+			buf.WriteString(fmt.Sprintf("**%s healthy, %s at-risk, %s orphaned** of %s files\n\n",
+				formatPercent(percent(result.Summary.HealthyCount, total)),
+				formatPercent(percent(result.Summary.AtRiskCount, total)),
+				formatPercent(percent(result.Summary.OrphanCount, total)),
+				formatCount(total)))
+		}
+		buf.WriteString("| Category | Count | Share | Status | Description |\n")
+		buf.WriteString("|----------|-------|-------|--------|-------------|\n")
+	}
+	summaryRow("Healthy Media", result.Summary.HealthyCount, true, "✅", "Tracked by Arr and hardlinked to torrent")
+	summaryRow("At Risk", result.Summary.AtRiskCount, true, "⚠️", "Tracked by Arr but NOT hardlinked (no torrent protection)")
+	summaryRow("Orphaned Media", result.Summary.OrphanCount, true, "❌", "Not tracked by Arr (outside grace window)")
+	if result.Summary.RemovedFromArrCount > 0 && !legacy {
+		summaryRow("Removed from Arr", result.Summary.RemovedFromArrCount, true, "🗑️", "Tracked by Arr last run, now deleted from Arr but still on disk")
+	}
+	summaryRow("Orphaned Downloads", result.Summary.OrphanedDownloadCount, true, "💾", "Files in torrent dir not hardlinked or tracked")
+	summaryRow("Hidden Files", result.Summary.HiddenFileCount, true, "👻", "Hidden dot-files (e.g. .parts fragments)")
+	summaryRow("Lost+Found", result.Summary.LostAndFoundCount, true, "🔧", "Files in extra scan paths (e.g. lost+found)")
+	summaryRow("Suspicious Files", result.Summary.SuspiciousCount, false, "🚨", "Suspicious extensions detected")
+	if result.Summary.OrphanedSidecarCount > 0 && !legacy {
+		summaryRow("Orphaned Sidecars", result.Summary.OrphanedSidecarCount, true, "🗒️", "Subtitles/metadata whose video is gone")
+	}
+	if result.Summary.OtherFileCount > 0 && !legacy {
+		summaryRow("Other Files", result.Summary.OtherFileCount, true, "📎", "Untracked files with an ignored extension")
+	}
+	if result.Summary.ExpectedUntrackedCount > 0 && !legacy {
+		summaryRow("Expected Untracked", result.Summary.ExpectedUntrackedCount, true, "🗂️", "Untracked files under expected_untracked_paths")
+	}
+	if result.Summary.SizeMismatchCount > 0 && !legacy {
+		summaryRow("Size Mismatch", result.Summary.SizeMismatchCount, false, "📏", "Size on disk differs from what Arr recorded")
+	}
+	if result.Summary.PartialSeasonPackCount > 0 && !legacy {
+		summaryRow("Partial Season Packs", result.Summary.PartialSeasonPackCount, false, "📦", "Season torrents with episodes left unimported")
+	}
+	if result.Summary.VerifiedCount > 0 && !legacy {
+		summaryRow("Corrupt Media", result.Summary.CorruptCount, false, "🩺", fmt.Sprintf("Failed ffprobe verification (%d probed)", result.Summary.VerifiedCount))
+	}
 	buf.WriteString("\n")
 
+	if ages := categoryAges(result.ClassifiedMedia); len(ages) > 0 && !legacy {
+		buf.WriteString("**File ages** (by modification time):\n\n")
+		for _, a := range ages {
+			buf.WriteString(fmt.Sprintf("- %s: oldest %d days, newest %d days\n", a.Label, ageDays(a.Oldest), ageDays(a.Newest)))
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(result.ArrReconciliation) > 0 && !legacy {
+		buf.WriteString("**Arr reconciliation**:\n\n")
+		for _, r := range result.ArrReconciliation {
+			marker := icon("✅", useEmoji)
+			if len(r.Missing) > 0 {
+				marker = icon("⚠️", useEmoji)
+			}
+			buf.WriteString(fmt.Sprintf("- %s %s\n", marker, ReconciliationLine(r)))
+		}
+		buf.WriteString("\n")
+	}
+
+	if result.Summary.BaselineSuppressed > 0 && !legacy {
+		buf.WriteString(fmt.Sprintf("**Suppressed by baseline**: %d finding(s) acknowledged in `%s`\n\n", result.Summary.BaselineSuppressed, cfg.Analysis.BaselineFile))
+	}
+
 	healthy := filterByClassification(result.ClassifiedMedia, models.MediaHealthy)
 	atRisk := filterByClassification(result.ClassifiedMedia, models.MediaAtRisk)
 	orphans := filterByClassification(result.ClassifiedMedia, models.MediaOrphan)
@@ -86,27 +221,34 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("\n")
 	}
 
-	if len(result.ConnectionStatus) > 0 {
+	if len(result.ConnectionStatus) > 0 || !legacy {
 		buf.WriteString("## Service Connections\n\n")
 		buf.WriteString("Connection status of all configured Arr services and download clients:\n\n")
 		buf.WriteString("- Verifies API connectivity and authentication\n")
 		buf.WriteString("- Checks if services are reachable and responding to health checks\n")
 		buf.WriteString("- Reports any connection errors or authentication failures\n\n")
-		buf.WriteString("| Service | Status | Details |\n")
-		buf.WriteString("|---------|--------|---------|\n")
-		sort.Slice(result.ConnectionStatus, func(i, j int) bool {
-			return result.ConnectionStatus[i].Name < result.ConnectionStatus[j].Name
-		})
-		for _, svc := range result.ConnectionStatus {
-			status := "✅ Connected"
-			details := "OK"
-			if !svc.OK {
-				status = "❌ Failed"
-				details = svc.Error
+		if len(result.ConnectionStatus) == 0 {
+			buf.WriteString("No services are configured, so nothing was checked against Arr or the download client.\n\n")
+		} else {
+			buf.WriteString("| Service | Status | Details |\n")
+			buf.WriteString("|---------|--------|---------|\n")
+			sort.Slice(result.ConnectionStatus, func(i, j int) bool {
+				return result.ConnectionStatus[i].Name < result.ConnectionStatus[j].Name
+			})
+			for _, svc := range result.ConnectionStatus {
+				status := icon("✅", useEmoji) + " Connected"
+				details := "OK"
+				if !svc.OK {
+					status = icon("❌", useEmoji) + " Failed"
+					if svc.Reason != "" && !legacy {
+						status = icon("❌", useEmoji) + " " + svc.Reason
+					}
+					details = svc.Error
+				}
+				buf.WriteString(fmt.Sprintf("| %s | %s | %s |\n", svc.Name, status, escapeMarkdown(details)))
 			}
-			buf.WriteString(fmt.Sprintf("| %s | %s | %s |\n", svc.Name, status, escapeMarkdown(details)))
+			buf.WriteString("\n")
 		}
-		buf.WriteString("\n")
 	}
 
 	if len(atRisk) > 0 {
@@ -117,16 +259,24 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("- The torrent was removed from qBittorrent\n")
 		buf.WriteString("- The file system no longer shows the expected link count\n\n")
 		buf.WriteString("**Risk**: If the original torrent is removed, these files could be lost if they're not backed up elsewhere.\n\n")
-		buf.WriteString("| Path | Source | Age |\n")
-		buf.WriteString("|------|--------|-----|\n")
-		sort.Slice(atRisk, func(i, j int) bool {
-			return atRisk[i].File.Path < atRisk[j].File.Path
-		})
-		for _, cm := range atRisk {
-			age := time.Since(cm.File.ModTime)
-			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(cm.File.Path), cm.ArrSource, formatDuration(age)))
+		shown := sectionRows(len(atRisk), limit)
+		writeTruncated(&buf, shown, len(atRisk), useEmoji)
+		if mf.groupBy != "" {
+			mf.writeGroups(&buf, atRisk, shown, func(cm models.ClassifiedMedia) string {
+				return fmt.Sprintf("%s, %s", cm.ArrSource, formatDuration(time.Since(cm.File.ModTime)))
+			})
+		} else {
+			buf.WriteString("| Path | Source | Age |\n")
+			buf.WriteString("|------|--------|-----|\n")
+			sort.Slice(atRisk, func(i, j int) bool {
+				return atRisk[i].File.Path < atRisk[j].File.Path
+			})
+			for _, cm := range atRisk[:shown] {
+				age := time.Since(cm.File.ModTime)
+				buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(cm.File.Path), cm.ArrSource, formatDuration(age)))
+			}
+			buf.WriteString("\n")
 		}
-		buf.WriteString("\n")
 	}
 
 	if len(orphans) > 0 {
@@ -144,14 +294,65 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("- Test files or incomplete imports\n\n")
 		buf.WriteString("**Grace window**: Files newer than the configured grace hours are excluded to avoid false positives during active imports.\n\n")
 		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(orphanTotalSize)))
+		confidence := result.ArrUnmappedChecked && !legacy
+		if confidence {
+			buf.WriteString(fmt.Sprintf("**Confirmed by Arr**: %d of %d — high confidence: Sonarr/Radarr list the folder as unmapped in their root folder. The rest were found only by auditarr; check [path_mappings] before deleting them.\n\n", result.Summary.ArrConfirmedOrphans, len(orphans)))
+		}
+		shown := sectionRows(len(orphans), limit)
+		writeTruncated(&buf, shown, len(orphans), useEmoji)
+		if mf.groupBy != "" {
+			mf.writeGroups(&buf, orphans, shown, func(cm models.ClassifiedMedia) string {
+				detail := fmt.Sprintf("%s, %s", formatDuration(time.Since(cm.File.ModTime)), formatBytes(cm.File.Size))
+				if confidence && cm.ArrConfirmed {
+					detail += ", confirmed by Arr"
+				}
+				return detail
+			})
+		} else {
+			if confidence {
+				buf.WriteString("| Path | Age | Size | Confidence |\n")
+				buf.WriteString("|------|-----|------|------------|\n")
+			} else {
+				buf.WriteString("| Path | Age | Size |\n")
+				buf.WriteString("|------|-----|------|\n")
+			}
+			sort.Slice(orphans, func(i, j int) bool {
+				return orphans[i].File.Path < orphans[j].File.Path
+			})
+			for _, cm := range orphans[:shown] {
+				age := time.Since(cm.File.ModTime)
+				if !confidence {
+					buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(cm.File.Path), formatDuration(age), formatBytes(cm.File.Size)))
+					continue
+				}
+				level := "auditarr only"
+				if cm.ArrConfirmed {
+					level = "High (Arr agrees)"
+				}
+				buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", escapeMarkdown(cm.File.Path), formatDuration(age), formatBytes(cm.File.Size), level))
+			}
+			buf.WriteString("\n")
+		}
+	}
+
+	removed := filterByClassification(result.ClassifiedMedia, models.MediaRemovedFromArr)
+	if len(removed) > 0 && !legacy {
+		var removedTotalSize int64
+		for _, cm := range removed {
+			removedTotalSize += cm.File.Size
+		}
+		buf.WriteString("## Removed from Arr\n\n")
+		buf.WriteString("Files Sonarr/Radarr tracked in the previous run that they no longer know about. They were most likely deleted from Arr without deleting the files, which makes them high-confidence cleanup candidates. These are not counted as orphans:\n\n")
+		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(removedTotalSize)))
+		shown := sectionRows(len(removed), limit)
+		writeTruncated(&buf, shown, len(removed), useEmoji)
 		buf.WriteString("| Path | Age | Size |\n")
 		buf.WriteString("|------|-----|------|\n")
-		sort.Slice(orphans, func(i, j int) bool {
-			return orphans[i].File.Path < orphans[j].File.Path
+		sort.Slice(removed, func(i, j int) bool {
+			return removed[i].File.Path < removed[j].File.Path
 		})
-		for _, cm := range orphans {
-			age := time.Since(cm.File.ModTime)
-			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(cm.File.Path), formatDuration(age), formatBytes(cm.File.Size)))
+		for _, cm := range removed[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(cm.File.Path), formatDuration(time.Since(cm.File.ModTime)), formatBytes(cm.File.Size)))
 		}
 		buf.WriteString("\n")
 	}
@@ -176,16 +377,24 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("- Age exceeds grace window\n\n")
 		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(downloadTotalSize)))
 		buf.WriteString(fmt.Sprintf("**File Count**: %d\n\n", len(orphanedDownloads)))
-		buf.WriteString("| Path | Age | Size | Hardlinks |\n")
-		buf.WriteString("|------|-----|------|-----------|\n")
-		sort.Slice(orphanedDownloads, func(i, j int) bool {
-			return orphanedDownloads[i].File.Path < orphanedDownloads[j].File.Path
-		})
-		for _, cm := range orphanedDownloads {
-			age := time.Since(cm.File.ModTime)
-			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %d |\n", escapeMarkdown(cm.File.Path), formatDuration(age), formatBytes(cm.File.Size), cm.File.HardlinkCount))
+		shown := sectionRows(len(orphanedDownloads), limit)
+		writeTruncated(&buf, shown, len(orphanedDownloads), useEmoji)
+		if mf.groupBy != "" {
+			mf.writeGroups(&buf, orphanedDownloads, shown, func(cm models.ClassifiedMedia) string {
+				return fmt.Sprintf("%s, %s, %d hardlink(s)", formatDuration(time.Since(cm.File.ModTime)), formatBytes(cm.File.Size), cm.File.HardlinkCount)
+			})
+		} else {
+			buf.WriteString("| Path | Age | Size | Hardlinks |\n")
+			buf.WriteString("|------|-----|------|-----------|\n")
+			sort.Slice(orphanedDownloads, func(i, j int) bool {
+				return orphanedDownloads[i].File.Path < orphanedDownloads[j].File.Path
+			})
+			for _, cm := range orphanedDownloads[:shown] {
+				age := time.Since(cm.File.ModTime)
+				buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %d |\n", escapeMarkdown(cm.File.Path), formatDuration(age), formatBytes(cm.File.Size), cm.File.HardlinkCount))
+			}
+			buf.WriteString("\n")
 		}
-		buf.WriteString("\n")
 	}
 
 	if len(result.SuspiciousFiles) > 0 {
@@ -195,15 +404,58 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("- Malware or suspicious executables (.exe, .bat, .scr, etc.)\n")
 		buf.WriteString("- Incomplete downloads (.part, .crdownload, .tmp, etc.)\n")
 		buf.WriteString("- Suspicious archives or scripts that shouldn't be in media folders\n")
-		buf.WriteString("- Files with double extensions that could be malware\n\n")
+		buf.WriteString("- Files with double extensions that could be malware\n")
+		if !legacy {
+			buf.WriteString("- Files with more hardlinks than `max_expected_hardlinks`, a sign of a runaway import script\n")
+		}
+		buf.WriteString("\n")
 		buf.WriteString("**Action**: Review these files manually to determine if they should be removed.\n\n")
-		buf.WriteString("| Path | Reason |\n")
-		buf.WriteString("|------|--------|\n")
-		sort.Slice(result.SuspiciousFiles, func(i, j int) bool {
-			return result.SuspiciousFiles[i].Path < result.SuspiciousFiles[j].Path
-		})
-		for _, sf := range result.SuspiciousFiles {
-			buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeMarkdown(sf.Path), sf.Reason))
+		shown := sectionRows(len(result.SuspiciousFiles), limit)
+		writeTruncated(&buf, shown, len(result.SuspiciousFiles), useEmoji)
+		if legacy {
+			buf.WriteString("| Path | Reason |\n")
+			buf.WriteString("|------|--------|\n")
+		} else {
+			buf.WriteString("| Path | Severity | Reason |\n")
+			buf.WriteString("|------|----------|--------|\n")
+		}
+		sortSuspicious(result.SuspiciousFiles)
+		for _, sf := range result.SuspiciousFiles[:shown] {
+			if legacy {
+				buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeMarkdown(sf.Path), sf.Reason))
+				continue
+			}
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(sf.Path), sf.Severity, sf.Reason))
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(result.CorruptFiles) > 0 && !legacy {
+		buf.WriteString("## Corrupt Media\n\n")
+		buf.WriteString("Media files that ffprobe could not read:\n\n")
+		buf.WriteString("**What this checks**: Each probed file is opened with `ffprobe` to confirm the container can be parsed. Files that fail are likely truncated, partially copied, or corrupt.\n\n")
+		buf.WriteString(fmt.Sprintf("**Files Probed**: %d\n\n", result.Summary.VerifiedCount))
+		shown := sectionRows(len(result.CorruptFiles), limit)
+		writeTruncated(&buf, shown, len(result.CorruptFiles), useEmoji)
+		buf.WriteString("| Path | Size | Error |\n")
+		buf.WriteString("|------|------|-------|\n")
+		for _, cf := range result.CorruptFiles[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(cf.Path), formatBytes(cf.Size), escapeMarkdown(cf.Reason)))
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(result.SizeMismatches) > 0 && !legacy {
+		buf.WriteString("## Size Mismatch\n\n")
+		buf.WriteString("Library files whose size on disk differs from the size Sonarr/Radarr recorded:\n\n")
+		buf.WriteString("**What this checks**: Compares each tracked file's size with the size Arr stored at import or on its last rescan. Hardlink checks cannot catch a file that was swapped or truncated in place.\n\n")
+		buf.WriteString("**What to do**: Rescan the series/movie in Arr. If the size still differs, verify or re-download the file.\n\n")
+		shown := sectionRows(len(result.SizeMismatches), limit)
+		writeTruncated(&buf, shown, len(result.SizeMismatches), useEmoji)
+		buf.WriteString("| Path | Source | On Disk | Arr Recorded |\n")
+		buf.WriteString("|------|--------|---------|--------------|\n")
+		for _, sm := range result.SizeMismatches[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", escapeMarkdown(sm.Path), sm.ArrSource, formatBytes(sm.DiskSize), formatBytes(sm.ArrSize)))
 		}
 		buf.WriteString("\n")
 	}
@@ -218,21 +470,162 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("**What this checks**: Torrents marked as completed in qBittorrent that have no corresponding hardlinked files in your media directories.\n\n")
 		buf.WriteString("**Why this matters**: These torrents are consuming disk space in your download directory but aren't properly imported into your media library. The torrent files exist at the location below but aren't linked to Arr-managed media.\n\n")
 		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(totalSize)))
-		buf.WriteString("| Full Path | Completed | Size |\n")
-		buf.WriteString("|-----------|-----------|------|\n")
+		privateCount := 0
+		for _, t := range result.UnlinkedTorrents {
+			if t.IsPrivate {
+				privateCount++
+			}
+		}
+		if privateCount > 0 && !legacy {
+			buf.WriteString(fmt.Sprintf("**%s Private trackers**: %d of these torrents are from private trackers (marked %s). Removing them before their seeding requirements are met can incur ratio or hit-and-run penalties — check the tracker's rules first.\n\n", icon("⚠️", useEmoji), privateCount, icon("🔒", useEmoji)))
+		}
 		sort.Slice(result.UnlinkedTorrents, func(i, j int) bool {
 			pathI := filepath.Join(result.UnlinkedTorrents[i].SavePath, result.UnlinkedTorrents[i].Name)
 			pathJ := filepath.Join(result.UnlinkedTorrents[j].SavePath, result.UnlinkedTorrents[j].Name)
 			return pathI < pathJ
 		})
-		for _, t := range result.UnlinkedTorrents {
-			completed := "unknown"
-			if !t.CompletedOn.IsZero() {
-				completed = formatDuration(time.Since(t.CompletedOn)) + " ago"
+		writeTorrents := func(torrents []models.Torrent) {
+			shown := sectionRows(len(torrents), limit)
+			writeTruncated(&buf, shown, len(torrents), useEmoji)
+			if legacy {
+				buf.WriteString("| Full Path | Completed | Size |\n")
+				buf.WriteString("|-----------|-----------|------|\n")
+			} else {
+				buf.WriteString("| Full Path | Completed | Size | Private |\n")
+				buf.WriteString("|-----------|-----------|------|---------|\n")
+			}
+			for _, t := range torrents[:shown] {
+				completed := "unknown"
+				if !t.CompletedOn.IsZero() {
+					completed = formatDuration(time.Since(t.CompletedOn)) + " ago"
+				}
+				fullPath := filepath.Join(t.SavePath, t.Name)
+				displayPath := utils.NormalizePath(fullPath, cfg.PathMappings)
+				if legacy {
+					buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(displayPath), completed, formatBytes(t.Size)))
+					continue
+				}
+				private := ""
+				if t.IsPrivate {
+					private = icon("🔒", useEmoji)
+				}
+				buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", escapeMarkdown(displayPath), completed, formatBytes(t.Size), private))
+			}
+			buf.WriteString("\n")
+		}
+		if legacy {
+			writeTorrents(result.UnlinkedTorrents)
+		} else {
+			var seeding, inactive []models.Torrent
+			for _, t := range result.UnlinkedTorrents {
+				if t.State == models.StateSeeding {
+					seeding = append(seeding, t)
+				} else {
+					inactive = append(inactive, t)
+				}
+			}
+			if len(inactive) > 0 {
+				buf.WriteString("### Inactive (Unlinked)\n\n")
+				buf.WriteString(fmt.Sprintf("%d torrent(s) paused or otherwise not uploading. These are dead weight; clean them up first.\n\n", len(inactive)))
+				writeTorrents(inactive)
+			}
+			if len(seeding) > 0 {
+				buf.WriteString("### Actively Seeding (Unlinked)\n\n")
+				buf.WriteString(fmt.Sprintf("%d torrent(s) still uploading to peers. They are doing useful work, so leave them until they stop seeding.\n\n", len(seeding)))
+				writeTorrents(seeding)
+			}
+		}
+	}
+
+	if len(result.OutOfScopeTorrents) > 0 && !legacy {
+		buf.WriteString("## Out-of-Scope Torrents\n\n")
+		buf.WriteString("Completed torrents saved outside media_root, torrent_root and extra_scan_paths:\n\n")
+		buf.WriteString("**What this checks**: Each completed torrent's save path, after [path_mappings], is compared with the directories auditarr scans. Their files were never scanned, so whether they are hardlinked into the library is unknown and they are left out of Unlinked Torrents.\n\n")
+		buf.WriteString("**What to do**: Point torrent_root at qBittorrent's download directory, add the save path to extra_scan_paths, or add a [path_mappings] entry if the path is mounted elsewhere here.\n\n")
+		shown := sectionRows(len(result.OutOfScopeTorrents), limit)
+		writeTruncated(&buf, shown, len(result.OutOfScopeTorrents), useEmoji)
+		buf.WriteString("| Save Path | Torrent | Size |\n")
+		buf.WriteString("|-----------|---------|------|\n")
+		sort.Slice(result.OutOfScopeTorrents, func(i, j int) bool {
+			pathI := filepath.Join(result.OutOfScopeTorrents[i].SavePath, result.OutOfScopeTorrents[i].Name)
+			pathJ := filepath.Join(result.OutOfScopeTorrents[j].SavePath, result.OutOfScopeTorrents[j].Name)
+			return pathI < pathJ
+		})
+		for _, t := range result.OutOfScopeTorrents[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(t.SavePath), escapeMarkdown(t.Name), formatBytes(t.Size)))
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(result.UnimportedDownloads) > 0 && !legacy {
+		buf.WriteString("## Downloaded but Not Imported\n\n")
+		buf.WriteString("Files under the torrent root whose inode is not shared with any file under the media root:\n\n")
+		buf.WriteString("**What this checks**: Compares filesystem inodes between the torrent and media roots. Unlike Orphaned Downloads, this does not depend on what Sonarr/Radarr track — any torrent file without a hardlink into the library is listed, including torrents still seeding.\n\n")
+		buf.WriteString("**Why this matters**: This is download space that is not shared with your library. Once seeding is no longer needed, it can be reclaimed.\n\n")
+		buf.WriteString(fmt.Sprintf("**Total Size**: %s | **Files**: %d\n\n", formatBytes(result.Summary.UnimportedSize), result.Summary.UnimportedCount))
+		shown := sectionRows(len(result.UnimportedDownloads), limit)
+		writeTruncated(&buf, shown, len(result.UnimportedDownloads), useEmoji)
+		buf.WriteString("| Path | Age | Size | Hardlinks |\n")
+		buf.WriteString("|------|-----|------|-----------|\n")
+		for _, f := range result.UnimportedDownloads[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %d |\n", escapeMarkdown(f.Path), formatDuration(time.Since(f.ModTime)), formatBytes(f.Size), f.HardlinkCount))
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(result.CopiedImports) > 0 && !legacy {
+		buf.WriteString("## Copied Instead of Hardlinked\n\n")
+		buf.WriteString("At-risk library files with a same-name, same-size file under the torrent root on a different inode. The import copied the download instead of hardlinking it, so the data is stored more than once:\n\n")
+		buf.WriteString(fmt.Sprintf("**Wasted Space**: %s | **Files**: %d\n\n", formatBytes(result.Summary.CopiedImportWaste), result.Summary.CopiedImportCount))
+		shown := sectionRows(len(result.CopiedImports), limit)
+		writeTruncated(&buf, shown, len(result.CopiedImports), useEmoji)
+		buf.WriteString("| Path | Size | Torrent Copies | Wasted |\n")
+		buf.WriteString("|------|------|----------------|--------|\n")
+		for _, c := range result.CopiedImports[:shown] {
+			copies := make([]string, len(c.TorrentCopies))
+			for i, p := range c.TorrentCopies {
+				copies[i] = "`" + escapeMarkdown(p) + "`"
+			}
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %dx space wasted (%s) |\n", escapeMarkdown(c.Path), formatBytes(c.Size), strings.Join(copies, ", "), len(c.TorrentCopies)+1, formatBytes(c.WastedBytes)))
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(result.PartialSeasonPacks) > 0 && !legacy {
+		buf.WriteString("## Partial Season Packs\n\n")
+		buf.WriteString("Completed season-pack torrents where only some episodes made it into the library:\n\n")
+		buf.WriteString("**What this checks**: Each episode in a multi-episode torrent is matched, after [path_mappings], to the scanned download and counted as imported when it shares its data with a library file. Seasons that are fully imported or not imported at all are not listed.\n\n")
+		buf.WriteString("**What to do**: Check Sonarr's activity queue or manual import for the leftover episodes. If they were rejected on purpose (e.g. a better release was already imported), they can be cleaned up once the torrent stops seeding.\n\n")
+		shown := sectionRows(len(result.PartialSeasonPacks), limit)
+		writeTruncated(&buf, shown, len(result.PartialSeasonPacks), useEmoji)
+		buf.WriteString("| Series | Season | Imported | Leftover Files | Torrent |\n")
+		buf.WriteString("|--------|--------|----------|----------------|---------|\n")
+		for _, p := range result.PartialSeasonPacks[:shown] {
+			leftover := make([]string, len(p.Leftover))
+			for i, path := range p.Leftover {
+				leftover[i] = "`" + escapeMarkdown(filepath.Base(path)) + "`"
 			}
-			fullPath := filepath.Join(t.SavePath, t.Name)
-			displayPath := utils.NormalizePath(fullPath, cfg.PathMappings)
-			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(displayPath), completed, formatBytes(t.Size)))
+			total := len(p.Imported) + len(p.Leftover)
+			buf.WriteString(fmt.Sprintf("| %s | %d | %d/%d imported, %d leftover | %s | %s |\n", escapeMarkdown(p.Series), p.Season, len(p.Imported), total, len(p.Leftover), strings.Join(leftover, "<br>"), escapeMarkdown(p.Torrent)))
+		}
+		buf.WriteString("\n")
+	}
+
+	// Orphaned sidecars section
+	sidecars := filterByClassification(result.ClassifiedMedia, models.MediaOrphanedSidecar)
+	if len(sidecars) > 0 && !legacy {
+		buf.WriteString("## Orphaned Sidecars\n\n")
+		buf.WriteString("Subtitle and metadata files left behind after their video was removed or renamed:\n\n")
+		buf.WriteString("**What this checks**: Each subtitle, `.nfo` and `-thumb` image in the library is matched by name against the videos in the same directory (e.g. `Movie.en.srt` belongs to `Movie.mkv`). Folder-level artwork such as `poster.jpg` is not checked.\n\n")
+		shown := sectionRows(len(sidecars), limit)
+		writeTruncated(&buf, shown, len(sidecars), useEmoji)
+		buf.WriteString("| Path | Size |\n")
+		buf.WriteString("|------|------|\n")
+		sort.Slice(sidecars, func(i, j int) bool {
+			return sidecars[i].File.Path < sidecars[j].File.Path
+		})
+		for _, cm := range sidecars[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeMarkdown(cm.File.Path), formatBytes(cm.File.Size)))
 		}
 		buf.WriteString("\n")
 	}
@@ -247,12 +640,14 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("## Hidden Files\n\n")
 		buf.WriteString("Hidden dot-files found in media/torrent directories (typically qBittorrent `.parts` incomplete download fragments):\n\n")
 		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(hiddenTotalSize)))
+		shown := sectionRows(len(hiddenFiles), limit)
+		writeTruncated(&buf, shown, len(hiddenFiles), useEmoji)
 		buf.WriteString("| Path | Size |\n")
 		buf.WriteString("|------|------|\n")
 		sort.Slice(hiddenFiles, func(i, j int) bool {
 			return hiddenFiles[i].File.Size > hiddenFiles[j].File.Size
 		})
-		for _, cm := range hiddenFiles {
+		for _, cm := range hiddenFiles[:shown] {
 			buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeMarkdown(cm.File.Path), formatBytes(cm.File.Size)))
 		}
 		buf.WriteString("\n")
@@ -269,18 +664,64 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("## Lost+Found Files\n\n")
 		buf.WriteString("Files found in extra scan paths (e.g. ext4 lost+found). These are typically sparse filesystem recovery artifacts.\n\n")
 		buf.WriteString(fmt.Sprintf("**Apparent Size**: %s | **Actual Blocks**: %s | **Files**: %d\n\n", formatBytes(lfTotalSize), formatBytes(lfTotalBlocks), len(lostFound)))
+		shown := sectionRows(len(lostFound), limit)
+		writeTruncated(&buf, shown, len(lostFound), useEmoji)
 		buf.WriteString("| Path | Apparent Size | Block Size | Age |\n")
 		buf.WriteString("|------|---------------|------------|-----|\n")
 		sort.Slice(lostFound, func(i, j int) bool {
 			return lostFound[i].File.Size > lostFound[j].File.Size
 		})
-		for _, cm := range lostFound {
+		for _, cm := range lostFound[:shown] {
 			age := time.Since(cm.File.ModTime)
 			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", escapeMarkdown(cm.File.Path), formatBytes(cm.File.Size), formatBytes(cm.File.BlockSize), formatDuration(age)))
 		}
 		buf.WriteString("\n")
 	}
 
+	otherFiles := filterByClassification(result.ClassifiedMedia, models.MediaOtherFile)
+	if len(otherFiles) > 0 && !legacy {
+		var otherTotalSize int64
+		for _, cm := range otherFiles {
+			otherTotalSize += cm.File.Size
+		}
+		buf.WriteString("## Other Files\n\n")
+		buf.WriteString("Files not tracked by Sonarr or Radarr whose extension is listed in `[analysis].orphan_ignore_extensions`. These are not counted as orphans:\n\n")
+		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(otherTotalSize)))
+		shown := sectionRows(len(otherFiles), limit)
+		writeTruncated(&buf, shown, len(otherFiles), useEmoji)
+		buf.WriteString("| Path | Size |\n")
+		buf.WriteString("|------|------|\n")
+		sort.Slice(otherFiles, func(i, j int) bool {
+			return otherFiles[i].File.Path < otherFiles[j].File.Path
+		})
+		for _, cm := range otherFiles[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeMarkdown(cm.File.Path), formatBytes(cm.File.Size)))
+		}
+		buf.WriteString("\n")
+	}
+
+	expected := filterByClassification(result.ClassifiedMedia, models.MediaExpectedUntracked)
+	if len(expected) > 0 && !legacy {
+		var expectedTotalSize int64
+		for _, cm := range expected {
+			expectedTotalSize += cm.File.Size
+		}
+		buf.WriteString("## Expected Untracked\n\n")
+		buf.WriteString("Files not tracked by Sonarr or Radarr under `[analysis].expected_untracked_paths`. These are kept out of Arr on purpose and are not counted as orphans:\n\n")
+		buf.WriteString(fmt.Sprintf("**Total Size**: %s\n\n", formatBytes(expectedTotalSize)))
+		shown := sectionRows(len(expected), limit)
+		writeTruncated(&buf, shown, len(expected), useEmoji)
+		buf.WriteString("| Path | Size |\n")
+		buf.WriteString("|------|------|\n")
+		sort.Slice(expected, func(i, j int) bool {
+			return expected[i].File.Path < expected[j].File.Path
+		})
+		for _, cm := range expected[:shown] {
+			buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeMarkdown(cm.File.Path), formatBytes(cm.File.Size)))
+		}
+		buf.WriteString("\n")
+	}
+
 	// Orphaned directories section
 	if len(result.OrphanedDirectories) > 0 {
 		fullyOrphanedCount := 0
@@ -296,9 +737,11 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		if fullyOrphanedCount > 0 {
 			buf.WriteString(fmt.Sprintf("**Fully orphaned directories** (safe to remove entirely): **%d** (%s)\n\n", fullyOrphanedCount, formatBytes(fullyOrphanedSize)))
 		}
+		shown := sectionRows(len(result.OrphanedDirectories), limit)
+		writeTruncated(&buf, shown, len(result.OrphanedDirectories), useEmoji)
 		buf.WriteString("| Directory | Orphaned / Total | Size | Fully Orphaned |\n")
 		buf.WriteString("|-----------|------------------|------|----------------|\n")
-		for _, dir := range result.OrphanedDirectories {
+		for _, dir := range result.OrphanedDirectories[:shown] {
 			status := "No"
 			if dir.FullyOrphaned {
 				status = "Yes"
@@ -308,6 +751,27 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 		buf.WriteString("\n")
 	}
 
+	if len(result.CaseDuplicates) > 0 && !legacy {
+		buf.WriteString("## Case-Only Duplicates\n\n")
+		buf.WriteString("Sibling directories or files whose names differ only by case. Arr and a case-insensitive mount can resolve these to different places; this usually means a path-mapping or import bug:\n\n")
+		shown := sectionRows(len(result.CaseDuplicates), limit)
+		writeTruncated(&buf, shown, len(result.CaseDuplicates), useEmoji)
+		buf.WriteString("| Parent | Names | Type |\n")
+		buf.WriteString("|--------|-------|------|\n")
+		for _, d := range result.CaseDuplicates[:shown] {
+			kind := "File"
+			if d.IsDir {
+				kind = "Directory"
+			}
+			names := make([]string, len(d.Names))
+			for i, n := range d.Names {
+				names[i] = "`" + escapeMarkdown(n) + "`"
+			}
+			buf.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", escapeMarkdown(d.Parent), strings.Join(names, ", "), kind))
+		}
+		buf.WriteString("\n")
+	}
+
 	buf.WriteString("## Configuration\n\n")
 	buf.WriteString(fmt.Sprintf("- Sonarr Grace: %d hours\n", cfg.Sonarr.GraceHours))
 	buf.WriteString(fmt.Sprintf("- Radarr Grace: %d hours\n", cfg.Radarr.GraceHours))
@@ -317,10 +781,18 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 
 	if len(cfg.PathMappings) > 0 {
 		buf.WriteString("\n### Path Mappings\n\n")
+		if cfg.DefaultPathMappings && !legacy {
+			buf.WriteString("Built-in defaults: no `[path_mappings]` are configured. If Arr reports paths that don't start with `/data`, add `[path_mappings]` or set `[paths].default_path_mappings = false`.\n\n")
+		}
 		buf.WriteString("| API Path | Filesystem Path |\n")
 		buf.WriteString("|----------|----------------|\n")
-		for apiPath, fsPath := range cfg.PathMappings {
-			buf.WriteString(fmt.Sprintf("| `%s` | `%s` |\n", apiPath, fsPath))
+		apiPaths := make([]string, 0, len(cfg.PathMappings))
+		for apiPath := range cfg.PathMappings {
+			apiPaths = append(apiPaths, apiPath)
+		}
+		sort.Strings(apiPaths)
+		for _, apiPath := range apiPaths {
+			buf.WriteString(fmt.Sprintf("| `%s` | `%s` |\n", apiPath, cfg.PathMappings[apiPath]))
 		}
 		buf.WriteString("\n")
 	}
@@ -328,6 +800,33 @@ func (mf *MarkdownFormatter) Format(result *analysis.AnalysisResult, cfg *config
 	return buf.String()
 }
 
+// sectionRows returns how many of total rows a section lists under limit;
+// zero or negative lists them all.
+func sectionRows(total, limit int) int {
+	if limit > 0 && total > limit {
+		return limit
+	}
+	return total
+}
+
+// writeTruncated warns above a section's table when only shown of its total
+// rows will be listed. A section that large is almost always a setup problem
+// rather than real findings.
+func writeTruncated(buf *bytes.Buffer, shown, total int, useEmoji bool) {
+	if shown >= total {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("> %s **Truncated**: showing %d of %d entries. This many findings is likely a misconfiguration, such as a wrong path mapping; run `auditarr doctor`, or `auditarr explain <path>` on a listed file. The JSON report lists every entry; `outputs.max_section_entries` sets the cap.\n\n", icon("⚠️", useEmoji), shown, total))
+}
+
+// WriteToPath writes the report to exactly path, overwriting any previous run.
+func (mf *MarkdownFormatter) WriteToPath(content, path string) error {
+	if err := writeFileAtomic(path, []byte(content)); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
 func (mf *MarkdownFormatter) WriteToFile(content, reportDir string) (string, error) {
 	if err := os.MkdirAll(reportDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create report directory: %w", err)
@@ -0,0 +1,257 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestMarkdownFormatter_LegacyLayout(t *testing.T) {
+	result := &analysis.AnalysisResult{
+		Warnings:       []string{"media and torrent roots are on different filesystems"},
+		SizeMismatches: []models.SizeMismatch{{Path: "/media/a.mkv", ArrSource: "sonarr", DiskSize: 1, ArrSize: 2}},
+		UnlinkedTorrents: []models.Torrent{
+			{Name: "Release", SavePath: "/data", IsPrivate: true},
+		},
+		Summary: analysis.SummaryStats{SizeMismatchCount: 1},
+	}
+
+	current := NewMarkdownFormatter().Format(result, &config.Config{}, time.Second)
+	for _, heading := range []string{"## ⚠️ Warnings", "## Size Mismatch", "| Full Path | Completed | Size | Private |"} {
+		if !strings.Contains(current, heading) {
+			t.Errorf("current layout is missing %q", heading)
+		}
+	}
+
+	legacyCfg := &config.Config{Outputs: config.OutputConfig{MarkdownVersion: 1}}
+	legacy := NewMarkdownFormatter().Format(result, legacyCfg, time.Second)
+	for _, heading := range []string{"## ⚠️ Warnings", "## Size Mismatch", "Private"} {
+		if strings.Contains(legacy, heading) {
+			t.Errorf("legacy layout unexpectedly contains %q", heading)
+		}
+	}
+	if !strings.Contains(legacy, "| Full Path | Completed | Size |\n") {
+		t.Error("legacy layout lost the original Unlinked Torrents columns")
+	}
+}
+
+func TestGroupMedia_Show(t *testing.T) {
+	orphan := func(path string, size int64) models.ClassifiedMedia {
+		return models.ClassifiedMedia{
+			File:           models.MediaFile{Path: path, Size: size},
+			Classification: models.MediaOrphan,
+		}
+	}
+	files := []models.ClassifiedMedia{
+		orphan("/media/tv/Show/Season 02/e1.mkv", 10),
+		orphan("/media/tv/Show/Season 01/e1.mkv", 20),
+		orphan("/media/movies/Film (2020)/film.mkv", 5),
+	}
+
+	groups := groupMedia(files, GroupByShow)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d: %+v", len(groups), groups)
+	}
+	if groups[0].Key != "/media/movies/Film (2020)" || groups[1].Key != "/media/tv/Show" {
+		t.Errorf("unexpected group keys %q, %q", groups[0].Key, groups[1].Key)
+	}
+	if groups[1].Size != 30 || groups[1].Files[0].File.Path != "/media/tv/Show/Season 01/e1.mkv" {
+		t.Errorf("show group not totalled or sorted: %+v", groups[1])
+	}
+
+	if got := len(groupMedia(files, GroupByDir)); got != 3 {
+		t.Errorf("expected 3 dir groups, got %d", got)
+	}
+
+	mf := NewMarkdownFormatter()
+	mf.SetGroupBy(GroupByShow)
+	report := mf.Format(&analysis.AnalysisResult{ClassifiedMedia: files}, &config.Config{}, time.Second)
+	if !strings.Contains(report, "- **`/media/tv/Show`** — 2 file(s)") {
+		t.Errorf("grouped report missing show heading:\n%s", report)
+	}
+	if strings.Contains(report, "| Path | Age | Size |") {
+		t.Error("grouped report still renders the flat orphan table")
+	}
+}
+
+func TestMarkdownFormatter_FailedServicesBanner(t *testing.T) {
+	result := &analysis.AnalysisResult{
+		ConnectionStatus: []analysis.ServiceStatus{
+			{Name: "Sonarr", Enabled: true, Error: "connection refused", Reason: "Unreachable"},
+			{Name: "Radarr", Enabled: true, OK: true},
+		},
+	}
+	report := NewMarkdownFormatter().Format(result, &config.Config{}, time.Second)
+	for _, want := range []string{"## ⛔ Incomplete Audit", "**1 of 2 service(s) failed**: Sonarr (Unreachable)", "## Service Connections"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+	if HighestSeverity(result) != "error" {
+		t.Errorf("HighestSeverity = %q, want error", HighestSeverity(result))
+	}
+
+	empty := NewMarkdownFormatter().Format(&analysis.AnalysisResult{}, &config.Config{}, time.Second)
+	if !strings.Contains(empty, "## Service Connections") || strings.Contains(empty, "Incomplete Audit") {
+		t.Error("an empty run should still show Service Connections without the failure banner")
+	}
+}
+
+func TestMarkdownFormatter_SummaryPercentages(t *testing.T) {
+	result := &analysis.AnalysisResult{Summary: analysis.SummaryStats{TotalFiles: 200, HealthyCount: 190, AtRiskCount: 6, OrphanCount: 4}}
+	report := NewMarkdownFormatter().Format(result, &config.Config{}, time.Second)
+	for _, want := range []string{"**95% healthy, 3% at-risk, 2% orphaned** of 200 files", "| Healthy Media | 190 | 95% |", "| Suspicious Files | 0 | — |"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+
+	legacy := NewMarkdownFormatter().Format(result, &config.Config{Outputs: config.OutputConfig{MarkdownVersion: 1}}, time.Second)
+	if strings.Contains(legacy, "Share") || !strings.Contains(legacy, "| Healthy Media | 190 | ✅ |") {
+		t.Error("legacy layout changed the summary table")
+	}
+
+	if got := percent(3, 0); got != 0 {
+		t.Errorf("percent with no files = %v, want 0", got)
+	}
+}
+
+func TestMarkdownFormatter_PlainLabels(t *testing.T) {
+	off := false
+	cfg := &config.Config{Outputs: config.OutputConfig{UseEmoji: &off}}
+	result := &analysis.AnalysisResult{
+		Warnings:         []string{"something odd"},
+		ConnectionStatus: []analysis.ServiceStatus{{Name: "Sonarr", Enabled: true, Reason: "Unreachable"}},
+		UnlinkedTorrents: []models.Torrent{{Name: "Show", SavePath: "/data", IsPrivate: true}},
+	}
+	report := NewMarkdownFormatter().Format(result, cfg, time.Second)
+	for _, want := range []string{"## [FAIL] Incomplete Audit", "## [WARN] Warnings", "| [OK] |", "[FAIL] Unreachable", "[PRIVATE]"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+	for e := range plainLabels {
+		if strings.Contains(report, e) {
+			t.Errorf("report still contains %q with use_emoji = false", e)
+		}
+	}
+}
+
+func TestFormatters_RunLabel(t *testing.T) {
+	result := &analysis.AnalysisResult{}
+	cfg := &config.Config{}
+
+	mf := NewMarkdownFormatter()
+	mf.SetLabel("nightly-prod")
+	if report := mf.Format(result, cfg, time.Second); !strings.Contains(report, "**Run**: nightly-prod\n") {
+		t.Errorf("markdown header is missing the run label:\n%s", report)
+	}
+	if report := NewMarkdownFormatter().Format(result, cfg, time.Second); strings.Contains(report, "**Run**") {
+		t.Error("markdown shows a run line without a label")
+	}
+
+	jf := NewJSONFormatter()
+	jf.SetLabel("nightly-prod")
+	data, err := jf.Format(result, cfg, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"run_label": "nightly-prod"`) {
+		t.Error("JSON report is missing run_label")
+	}
+}
+
+func TestMarkdownFormatter_UnlinkedTorrentsBySeeding(t *testing.T) {
+	result := &analysis.AnalysisResult{
+		UnlinkedTorrents: []models.Torrent{
+			{Name: "Seeder", SavePath: "/data", State: models.StateSeeding},
+			{Name: "Paused", SavePath: "/data", State: models.StateCompleted},
+		},
+	}
+
+	out := NewMarkdownFormatter().Format(result, &config.Config{}, time.Second)
+	inactive := strings.Index(out, "### Inactive (Unlinked)")
+	seeding := strings.Index(out, "### Actively Seeding (Unlinked)")
+	if inactive < 0 || seeding < 0 {
+		t.Fatalf("expected both unlinked torrent subsections:\n%s", out)
+	}
+	if paused := strings.Index(out, "/data/Paused"); paused < inactive || paused > seeding {
+		t.Error("paused torrent is not listed under Inactive")
+	}
+	if strings.Index(out, "/data/Seeder") < seeding {
+		t.Error("seeding torrent is not listed under Actively Seeding")
+	}
+}
+
+func TestFormatters_CategoryAges(t *testing.T) {
+	now := time.Now()
+	result := &analysis.AnalysisResult{
+		ClassifiedMedia: []models.ClassifiedMedia{
+			{File: models.MediaFile{Path: "/m/a.mkv", ModTime: now.Add(-412 * 24 * time.Hour)}, Classification: models.MediaOrphan},
+			{File: models.MediaFile{Path: "/m/b.mkv", ModTime: now.Add(-3*24*time.Hour - time.Hour)}, Classification: models.MediaOrphan},
+			{File: models.MediaFile{Path: "/m/c.mkv", ModTime: now.Add(-10 * 24 * time.Hour)}, Classification: models.MediaOrphan},
+		},
+		Summary: analysis.SummaryStats{TotalFiles: 3, OrphanCount: 3},
+	}
+
+	out := NewMarkdownFormatter().Format(result, &config.Config{}, time.Second)
+	if !strings.Contains(out, "- Orphaned Media: oldest 412 days, newest 3 days\n") {
+		t.Errorf("markdown missing orphan age range:\n%s", out)
+	}
+	if strings.Contains(out, "- Healthy Media: oldest") {
+		t.Error("markdown lists an age range for an empty category")
+	}
+
+	data, err := NewJSONFormatter().Format(result, &config.Config{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	age, ok := report.Summary.Ages["orphan"]
+	if !ok || len(report.Summary.Ages) != 1 {
+		t.Fatalf("JSON ages = %v, want only orphan", report.Summary.Ages)
+	}
+	if age.OldestAgeDays != 412 || age.NewestAgeDays != 3 {
+		t.Errorf("JSON orphan ages = %d/%d days, want 412/3", age.OldestAgeDays, age.NewestAgeDays)
+	}
+}
+
+func TestMarkdownFormatter_TruncatesLargeSections(t *testing.T) {
+	var orphans []models.ClassifiedMedia
+	for i := range 5 {
+		orphans = append(orphans, models.ClassifiedMedia{
+			File:           models.MediaFile{Path: fmt.Sprintf("/data/media/tv/Show/e%d.mkv", i)},
+			Classification: models.MediaOrphan,
+		})
+	}
+	result := &analysis.AnalysisResult{ClassifiedMedia: orphans}
+	cfg := &config.Config{Outputs: config.OutputConfig{MaxSectionEntries: 2}}
+
+	report := NewMarkdownFormatter().Format(result, cfg, time.Second)
+	if !strings.Contains(report, "**Truncated**: showing 2 of 5 entries") || !strings.Contains(report, "auditarr doctor") {
+		t.Error("report is missing the truncation banner")
+	}
+	if !strings.Contains(report, "e1.mkv") || strings.Contains(report, "e2.mkv") {
+		t.Error("report should list exactly the first 2 orphans")
+	}
+
+	grouped := NewMarkdownFormatter()
+	grouped.SetGroupBy("dir")
+	if report := grouped.Format(result, cfg, time.Second); !strings.Contains(report, "5 file(s)") || strings.Contains(report, "e2.mkv") {
+		t.Error("grouped report should keep the group total but list only 2 files")
+	}
+
+	cfg.Outputs.MaxSectionEntries = -1
+	if report := NewMarkdownFormatter().Format(result, cfg, time.Second); strings.Contains(report, "Truncated") || !strings.Contains(report, "e4.mkv") {
+		t.Error("a negative cap should list every entry")
+	}
+}
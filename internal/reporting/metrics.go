@@ -0,0 +1,60 @@
+package reporting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WatchStatus tracks audit outcomes across watch cycles. A run is
+// successful when the filesystem walk and every enabled service worked, so
+// "audits are running but failing" shows up as a growing gap between
+// LastRun and LastSuccess.
+type WatchStatus struct {
+	Started     time.Time
+	LastRun     time.Time
+	LastSuccess time.Time
+	LastRunOK   bool
+}
+
+// SinceLastSuccess returns how long ago the last successful run finished, or
+// how long the watch has been up if no run has succeeded yet.
+func (s WatchStatus) SinceLastSuccess(now time.Time) time.Duration {
+	if s.LastSuccess.IsZero() {
+		return now.Sub(s.Started)
+	}
+	return now.Sub(s.LastSuccess)
+}
+
+// FormatWatchMetrics renders s in the Prometheus text exposition format.
+func FormatWatchMetrics(s WatchStatus, now time.Time) string {
+	var b strings.Builder
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	timestamp := func(t time.Time) float64 {
+		if t.IsZero() {
+			return 0
+		}
+		return float64(t.Unix())
+	}
+	ok := 0.0
+	if s.LastRunOK {
+		ok = 1
+	}
+	gauge("auditarr_last_run_timestamp_seconds", "Unix time the last audit finished.", timestamp(s.LastRun))
+	gauge("auditarr_last_success_timestamp_seconds", "Unix time the last successful audit finished, 0 if none has.", timestamp(s.LastSuccess))
+	gauge("auditarr_last_run_success", "Whether the last audit collected from the filesystem and every enabled service.", ok)
+	gauge("auditarr_seconds_since_last_success", "Seconds since the last successful audit, or since the watch started if none has succeeded.", s.SinceLastSuccess(now).Truncate(time.Second).Seconds())
+	return b.String()
+}
+
+// WriteWatchMetrics writes s to path for node_exporter's textfile collector.
+// The file is replaced atomically so a scrape never reads a partial file.
+func WriteWatchMetrics(path string, s WatchStatus, now time.Time) error {
+	if err := writeFileAtomic(path, []byte(FormatWatchMetrics(s, now))); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteWatchMetrics(t *testing.T) {
+	started := time.Unix(1700000000, 0)
+	status := WatchStatus{
+		Started:     started,
+		LastRun:     started.Add(3 * time.Hour),
+		LastSuccess: started.Add(time.Hour),
+	}
+	path := filepath.Join(t.TempDir(), "auditarr.prom")
+	if err := WriteWatchMetrics(path, status, started.Add(3*time.Hour)); err != nil {
+		t.Fatalf("WriteWatchMetrics: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"auditarr_last_run_timestamp_seconds 1700010800\n",
+		"auditarr_last_success_timestamp_seconds 1700003600\n",
+		"auditarr_last_run_success 0\n",
+		"auditarr_seconds_since_last_success 7200\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("metrics missing %q:\n%s", want, data)
+		}
+	}
+
+	if got := (WatchStatus{Started: started}).SinceLastSuccess(started.Add(time.Minute)); got != time.Minute {
+		t.Errorf("SinceLastSuccess with no success = %s, want time since start", got)
+	}
+}
@@ -2,70 +2,315 @@ package reporting
 
 import (
 	"bytes"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
 )
 
+// Notifier delivers a run summary to an external channel.
+type Notifier interface {
+	Name() string
+	Send(result *analysis.AnalysisResult, reportPath string, duration time.Duration) error
+}
+
+// NotifierKinds lists the notifiers built in, by the name each reports.
+var NotifierKinds = []string{"discord"}
+
+// SendAll sends to every notifier concurrently, giving each up to timeout.
+// One failing or hanging notifier doesn't hold up the others; a send that
+// times out is abandoned. The returned error joins every failure, each
+// prefixed with its notifier's name, in notifier order.
+func SendAll(notifiers []Notifier, result *analysis.AnalysisResult, reportPath string, duration, timeout time.Duration) error {
+	errs := make([]error, len(notifiers))
+	var wg sync.WaitGroup
+	for i, n := range notifiers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sendWithTimeout(n, result, reportPath, duration, timeout); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", n.Name(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func sendWithTimeout(n Notifier, result *analysis.AnalysisResult, reportPath string, duration, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- n.Send(result, reportPath, duration)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+var severityRank = map[string]int{"info": 1, "warning": 2, "error": 3}
+
+// HighestSeverity returns the most severe level ("error", "warning" or
+// "info") among the run's findings, or "" for a completely clean run.
+func HighestSeverity(result *analysis.AnalysisResult) string {
+	return Severity(result, config.NotificationConfig{})
+}
+
+// Severity is HighestSeverity with the orphan and at-risk levels taken from
+// the configured count thresholds.
+func Severity(result *analysis.AnalysisResult, cfg config.NotificationConfig) string {
+	s := result.Summary
+	levels := []string{
+		CountSeverity(s.OrphanCount, cfg.OrphanWarnThreshold, cfg.OrphanCriticalThreshold, "error"),
+		CountSeverity(s.AtRiskCount, cfg.AtRiskWarnThreshold, cfg.AtRiskCriticalThreshold, "warning"),
+	}
+	levels = append(levels, suspiciousLevel(result.SuspiciousFiles))
+	switch {
+	case s.CorruptCount > 0, s.PermissionErrors > 0, len(result.FailedServices()) > 0:
+		levels = append(levels, "error")
+	case s.OrphanedDownloadCount > 0, s.RemovedFromArrCount > 0, s.SizeMismatchCount > 0, s.CaseDuplicateCount > 0, s.PermissionWarnings > 0, len(result.Warnings) > 0:
+		levels = append(levels, "warning")
+	case s.HiddenFileCount > 0, s.LostAndFoundCount > 0, s.OrphanedSidecarCount > 0, s.UnimportedCount > 0, s.CopiedImportCount > 0, len(result.UnlinkedTorrents) > 0:
+		levels = append(levels, "info")
+	}
+
+	highest := ""
+	for _, l := range levels {
+		if severityRank[l] > severityRank[highest] {
+			highest = l
+		}
+	}
+	return highest
+}
+
+// suspiciousLevel maps the most severe suspicious finding onto a
+// notification level: critical is an error.
+func suspiciousLevel(files []models.SuspiciousFile) string {
+	level := ""
+	for _, sf := range files {
+		l := sf.Severity
+		if l == models.SuspiciousCritical || l == "" {
+			l = "error"
+		}
+		if severityRank[l] > severityRank[level] {
+			level = l
+		}
+	}
+	return level
+}
+
+// CountSeverity grades a finding count against warn and critical
+// thresholds. With neither set, any count is defaultLevel. Otherwise a count
+// at or above critical is an error, at or above warn a warning, and a count
+// below warn is info (below critical, with no warn set, a warning).
+func CountSeverity(count, warn, critical int, defaultLevel string) string {
+	switch {
+	case count == 0:
+		return ""
+	case warn == 0 && critical == 0:
+		return defaultLevel
+	case critical > 0 && count >= critical:
+		return "error"
+	case warn > 0 && count >= warn:
+		return "warning"
+	case warn > 0:
+		return "info"
+	default:
+		return "warning"
+	}
+}
+
+// ShouldNotify applies the only_on_issues and min_severity thresholds.
+func ShouldNotify(result *analysis.AnalysisResult, cfg config.NotificationConfig) bool {
+	rank := severityRank[Severity(result, cfg)]
+	if cfg.OnlyOnIssues && rank < severityRank["warning"] {
+		return false
+	}
+	if cfg.MinSeverity != "" && rank < severityRank[cfg.MinSeverity] {
+		return false
+	}
+	return true
+}
+
 type DiscordNotifier struct {
-	webhookURL string
-	client     *http.Client
+	webhooks     []discordWebhook
+	client       *http.Client
+	attachReport bool
+	useEmoji     bool
+	thresholds   config.NotificationConfig
+	label        string
+}
+
+// discordWebhook is one destination; runs whose most severe finding is
+// below minSeverity are not sent to it.
+type discordWebhook struct {
+	url         string
+	minSeverity string
 }
 
 func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
-	return &DiscordNotifier{
-		webhookURL: webhookURL,
+	dn := &DiscordNotifier{
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		useEmoji: true,
 	}
+	if webhookURL != "" {
+		dn.AddWebhook(webhookURL, "")
+	}
+	return dn
+}
+
+// AddWebhook routes runs whose most severe finding reaches minSeverity
+// (info, warning or error) to another webhook, e.g. errors to an alerts
+// channel. An empty minSeverity receives every run.
+func (dn *DiscordNotifier) AddWebhook(url, minSeverity string) {
+	dn.webhooks = append(dn.webhooks, discordWebhook{url: url, minSeverity: minSeverity})
+}
+
+// SetRootCAs verifies the webhook server's certificate against pool. A nil
+// pool keeps the system roots.
+func (dn *DiscordNotifier) SetRootCAs(pool *x509.CertPool) {
+	if pool != nil {
+		dn.client.Transport = utils.TransportWithRootCAs(pool)
+	}
+}
+
+// SetThresholds grades orphan and at-risk counts, for both webhook routing
+// and the embed color, by the configured warn and critical thresholds.
+func (dn *DiscordNotifier) SetThresholds(cfg config.NotificationConfig) {
+	dn.thresholds = cfg
+}
+
+// SetLabel adds the run label to the message title.
+func (dn *DiscordNotifier) SetLabel(label string) {
+	dn.label = label
+}
+
+// SetUseEmoji swaps emoji markers for plain labels like [OK] when false.
+func (dn *DiscordNotifier) SetUseEmoji(use bool) {
+	dn.useEmoji = use
+}
+
+// SetAttachReport uploads the report file alongside the embed. Reports larger
+// than Discord's attachment limit fall back to the path reference.
+func (dn *DiscordNotifier) SetAttachReport(attach bool) {
+	dn.attachReport = attach
+}
+
+func (dn *DiscordNotifier) Name() string {
+	return "discord"
 }
 
 func (dn *DiscordNotifier) Send(result *analysis.AnalysisResult, reportPath string, duration time.Duration) error {
-	if dn.webhookURL == "" {
+	severity := Severity(result, dn.thresholds)
+	rank := severityRank[severity]
+	var targets []string
+	for _, wh := range dn.webhooks {
+		if wh.minSeverity == "" || rank >= severityRank[wh.minSeverity] {
+			targets = append(targets, wh.url)
+		}
+	}
+	if len(targets) == 0 {
 		return nil
 	}
 
+	failed := result.FailedServices()
+
 	color := 3447003
-	if result.Summary.OrphanCount > 0 || result.Summary.PermissionErrors > 0 {
+	switch severity {
+	case "error":
 		color = 15158332
-	} else if result.Summary.AtRiskCount > 0 || result.Summary.PermissionWarnings > 0 {
+	case "warning":
 		color = 16776960
 	}
 
 	summaryValue := fmt.Sprintf(
-		"✅ %d healthy (tracked + hardlinked)\n⚠️ %d at risk (tracked, NOT hardlinked)\n❌ %d orphaned (not tracked)\n🚨 %d suspicious file(s)",
-		result.Summary.HealthyCount,
-		result.Summary.AtRiskCount,
-		result.Summary.OrphanCount,
-		result.Summary.SuspiciousCount,
+		"%s %d healthy (tracked + hardlinked)\n%s %d at risk (tracked, NOT hardlinked)\n%s %d orphaned (not tracked)\n%s %d suspicious file(s)",
+		icon("✅", dn.useEmoji), result.Summary.HealthyCount,
+		icon("⚠️", dn.useEmoji), result.Summary.AtRiskCount,
+		icon("❌", dn.useEmoji), result.Summary.OrphanCount,
+		icon("🚨", dn.useEmoji), result.Summary.SuspiciousCount,
 	)
 
+	if result.Summary.CorruptCount > 0 {
+		summaryValue += fmt.Sprintf("\n%s %d corrupt file(s)", icon("🩺", dn.useEmoji), result.Summary.CorruptCount)
+	}
+
+	if result.Summary.SizeMismatchCount > 0 {
+		summaryValue += fmt.Sprintf("\n%s %d size mismatch(es)", icon("📏", dn.useEmoji), result.Summary.SizeMismatchCount)
+	}
+
 	if result.Summary.PermissionErrors+result.Summary.PermissionWarnings > 0 {
-		summaryValue += fmt.Sprintf("\n⚠️ %d permission issue(s)", result.Summary.PermissionErrors+result.Summary.PermissionWarnings)
+		summaryValue += fmt.Sprintf("\n%s %d permission issue(s)", icon("⚠️", dn.useEmoji), result.Summary.PermissionErrors+result.Summary.PermissionWarnings)
 	}
 
+	fields := []map[string]interface{}{
+		{
+			"name":   "Summary",
+			"value":  summaryValue,
+			"inline": false,
+		},
+	}
+	if len(failed) > 0 {
+		fields = append(fields, map[string]interface{}{
+			"name":   icon("⛔", dn.useEmoji) + " Incomplete audit",
+			"value":  truncateField("Failed to connect: " + strings.Join(failed, ", ") + "\nFindings are incomplete; a clean summary does not mean the library is healthy."),
+			"inline": false,
+		})
+	}
+	if len(result.Warnings) > 0 {
+		fields = append(fields, map[string]interface{}{
+			"name":   icon("⚠️", dn.useEmoji) + " Warnings",
+			"value":  truncateField(strings.Join(result.Warnings, "\n\n")),
+			"inline": false,
+		})
+	}
+	var attachment []byte
+	if dn.attachReport && reportPath != "" {
+		data, err := readAttachment(reportPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: not attaching report to Discord message: %v\n", err)
+		} else {
+			attachment = data
+		}
+	}
+
+	reportValue := reportPath
+	if attachment != nil {
+		reportValue = fmt.Sprintf("Attached as `%s` (%s)", filepath.Base(reportPath), reportPath)
+	}
+	fields = append(fields, map[string]interface{}{
+		"name":   "Report Location",
+		"value":  reportValue,
+		"inline": false,
+	})
+
+	title := "Media Audit Complete"
+	if dn.label != "" {
+		title += ": " + dn.label
+	}
 	payload := map[string]interface{}{
 		"content": nil,
 		"embeds": []map[string]interface{}{
 			{
-				"title": "Media Audit Complete",
-				"color": color,
-				"fields": []map[string]interface{}{
-					{
-						"name":   "Summary",
-						"value":  summaryValue,
-						"inline": false,
-					},
-					{
-						"name":   "Report Location",
-						"value":  reportPath,
-						"inline": false,
-					},
-				},
+				"title":  title,
+				"color":  color,
+				"fields": fields,
 				"footer": map[string]interface{}{
 					"text": fmt.Sprintf("Duration: %.1fs", duration.Seconds()),
 				},
@@ -78,7 +323,30 @@ func (dn *DiscordNotifier) Send(result *analysis.AnalysisResult, reportPath stri
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := dn.client.Post(dn.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	var errs []error
+	for i, url := range targets {
+		if err := dn.post(url, jsonData, reportPath, attachment); err != nil {
+			if len(targets) > 1 {
+				err = fmt.Errorf("webhook %d: %w", i+1, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (dn *DiscordNotifier) post(url string, jsonData []byte, reportPath string, attachment []byte) error {
+	contentType := "application/json"
+	body := bytes.NewBuffer(jsonData)
+	if attachment != nil {
+		var err error
+		body, contentType, err = multipartPayload(jsonData, filepath.Base(reportPath), attachment)
+		if err != nil {
+			return fmt.Errorf("failed to build attachment upload: %w", err)
+		}
+	}
+
+	resp, err := dn.client.Post(url, contentType, body)
 	if err != nil {
 		return fmt.Errorf("failed to send webhook: %w", err)
 	}
@@ -90,3 +358,49 @@ func (dn *DiscordNotifier) Send(result *analysis.AnalysisResult, reportPath stri
 
 	return nil
 }
+
+// discordAttachmentLimit is Discord's upload limit for webhooks on servers
+// without boosts.
+const discordAttachmentLimit = 8 << 20
+
+func readAttachment(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > discordAttachmentLimit {
+		return nil, fmt.Errorf("report is %s, over Discord's %s limit", formatBytes(info.Size()), formatBytes(discordAttachmentLimit))
+	}
+	return os.ReadFile(path)
+}
+
+// multipartPayload wraps the JSON payload and a file in the multipart form
+// Discord expects for webhook uploads.
+func multipartPayload(payload []byte, filename string, data []byte) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("payload_json", string(payload)); err != nil {
+		return nil, "", err
+	}
+	part, err := w.CreateFormFile("files[0]", filename)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body, w.FormDataContentType(), nil
+}
+
+// discordFieldLimit is the maximum length of an embed field value.
+const discordFieldLimit = 1024
+
+func truncateField(s string) string {
+	if len(s) <= discordFieldLimit {
+		return s
+	}
+	return s[:discordFieldLimit-3] + "..."
+}
@@ -0,0 +1,177 @@
+package reporting
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/config"
+)
+
+func TestShouldNotify(t *testing.T) {
+	clean := &analysis.AnalysisResult{}
+	atRisk := &analysis.AnalysisResult{Summary: analysis.SummaryStats{AtRiskCount: 1}}
+	orphan := &analysis.AnalysisResult{Summary: analysis.SummaryStats{OrphanCount: 1}}
+
+	cases := []struct {
+		name   string
+		result *analysis.AnalysisResult
+		cfg    config.NotificationConfig
+		want   bool
+	}{
+		{"default notifies on clean runs", clean, config.NotificationConfig{}, true},
+		{"only_on_issues skips clean runs", clean, config.NotificationConfig{OnlyOnIssues: true}, false},
+		{"only_on_issues sends at-risk runs", atRisk, config.NotificationConfig{OnlyOnIssues: true}, true},
+		{"min_severity error skips warnings", atRisk, config.NotificationConfig{MinSeverity: "error"}, false},
+		{"min_severity error sends orphans", orphan, config.NotificationConfig{MinSeverity: "error"}, true},
+	}
+	for _, c := range cases {
+		if got := ShouldNotify(c.result, c.cfg); got != c.want {
+			t.Errorf("%s: ShouldNotify = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDiscordNotifier_AttachesReport(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "audit.md")
+	if err := os.WriteFile(reportPath, []byte("# Media Audit Report\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotFile, gotPayload string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("expected multipart upload: %v", err)
+			return
+		}
+		gotPayload = r.FormValue("payload_json")
+		f, _, err := r.FormFile("files[0]")
+		if err != nil {
+			t.Errorf("missing attachment: %v", err)
+			return
+		}
+		defer f.Close()
+		data, _ := io.ReadAll(f)
+		gotFile = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	dn := NewDiscordNotifier(srv.URL)
+	dn.SetAttachReport(true)
+	dn.SetLabel("nightly-prod")
+	if err := dn.Send(&analysis.AnalysisResult{}, reportPath, time.Second); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(gotPayload, "Media Audit Complete: nightly-prod") {
+		t.Errorf("payload title is missing the run label: %s", gotPayload)
+	}
+	if gotFile != "# Media Audit Report\n" {
+		t.Errorf("attachment = %q", gotFile)
+	}
+	if !strings.Contains(gotPayload, "Attached as") {
+		t.Errorf("payload does not mention the attachment: %s", gotPayload)
+	}
+}
+
+func TestDiscordNotifier_RoutesBySeverity(t *testing.T) {
+	hits := map[string]int{}
+	newServer := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[name]++
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	alerts, audits := newServer("alerts"), newServer("audits")
+
+	dn := NewDiscordNotifier("")
+	dn.AddWebhook(alerts.URL, "error")
+	dn.AddWebhook(audits.URL, "")
+
+	orphans := &analysis.AnalysisResult{Summary: analysis.SummaryStats{OrphanCount: 1}}
+	hidden := &analysis.AnalysisResult{Summary: analysis.SummaryStats{HiddenFileCount: 1}}
+	for _, result := range []*analysis.AnalysisResult{orphans, hidden} {
+		if err := dn.Send(result, "", time.Second); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if hits["alerts"] != 1 || hits["audits"] != 2 {
+		t.Errorf("alerts got %d message(s), audits %d; want 1 and 2", hits["alerts"], hits["audits"])
+	}
+}
+
+func TestSeverity_Thresholds(t *testing.T) {
+	cfg := config.NotificationConfig{OrphanWarnThreshold: 10, OrphanCriticalThreshold: 100}
+	for _, tc := range []struct {
+		orphans int
+		want    string
+	}{
+		{0, ""},
+		{2, "info"},
+		{10, "warning"},
+		{250, "error"},
+	} {
+		result := &analysis.AnalysisResult{Summary: analysis.SummaryStats{OrphanCount: tc.orphans}}
+		if got := Severity(result, cfg); got != tc.want {
+			t.Errorf("%d orphans: severity = %q, want %q", tc.orphans, got, tc.want)
+		}
+	}
+
+	twoOrphans := &analysis.AnalysisResult{Summary: analysis.SummaryStats{OrphanCount: 2}}
+	if got := HighestSeverity(twoOrphans); got != "error" {
+		t.Errorf("without thresholds any orphan should be an error, got %q", got)
+	}
+}
+
+type stubNotifier struct {
+	name  string
+	delay time.Duration
+	err   error
+	sent  chan struct{}
+}
+
+func (s *stubNotifier) Name() string { return s.name }
+
+func (s *stubNotifier) Send(*analysis.AnalysisResult, string, time.Duration) error {
+	time.Sleep(s.delay)
+	close(s.sent)
+	return s.err
+}
+
+func TestSendAll_IsolatesFailures(t *testing.T) {
+	hung := &stubNotifier{name: "hung", delay: time.Hour, sent: make(chan struct{})}
+	failing := &stubNotifier{name: "failing", err: io.ErrUnexpectedEOF, sent: make(chan struct{})}
+	ok := &stubNotifier{name: "ok", sent: make(chan struct{})}
+
+	start := time.Now()
+	err := SendAll([]Notifier{hung, failing, ok}, &analysis.AnalysisResult{}, "", 0, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("SendAll took %s; a hung notifier should time out", elapsed)
+	}
+
+	select {
+	case <-ok.sent:
+	default:
+		t.Error("healthy notifier was not sent to")
+	}
+	joined, isJoined := err.(interface{ Unwrap() []error })
+	if !isJoined || len(joined.Unwrap()) != 2 {
+		t.Fatalf("err = %v, want two joined failures", err)
+	}
+	errs := joined.Unwrap()
+	if !strings.HasPrefix(errs[0].Error(), "hung: timed out") {
+		t.Errorf("first error = %v, want the hung notifier's timeout", errs[0])
+	}
+	if !strings.HasPrefix(errs[1].Error(), "failing: ") || !errors.Is(errs[1], io.ErrUnexpectedEOF) {
+		t.Errorf("second error = %v, want the failing notifier's error", errs[1])
+	}
+}
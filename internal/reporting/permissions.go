@@ -0,0 +1,48 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// JSONPermissionRecord is a raw permission record as collected, before any
+// audit rules are applied. Useful for designing a permissions policy.
+type JSONPermissionRecord struct {
+	Path        string `json:"path"`
+	Mode        string `json:"mode"`
+	ModeString  string `json:"mode_string"`
+	OwnerUID    int    `json:"owner_uid"`
+	GroupGID    int    `json:"group_gid"`
+	IsDirectory bool   `json:"is_directory"`
+	SGID        bool   `json:"sgid"`
+}
+
+func WritePermissionsDump(path string, permissions []models.FilePermissions) error {
+	records := make([]JSONPermissionRecord, 0, len(permissions))
+	for _, p := range permissions {
+		records = append(records, JSONPermissionRecord{
+			Path:        p.Path,
+			Mode:        fmt.Sprintf("%04o", p.Mode&07777),
+			ModeString:  p.ModeString(),
+			OwnerUID:    p.OwnerUID,
+			GroupGID:    p.GroupGID,
+			IsDirectory: p.IsDirectory,
+			SGID:        p.HasSGID(),
+		})
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode permissions: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,49 @@
+package reporting
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestWritePermissionsDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dumps", "permissions.json")
+	perms := []models.FilePermissions{
+		{Path: "/mnt/media/tv", Mode: 0o2775, OwnerUID: 1000, GroupGID: 1000, IsDirectory: true},
+		{Path: "/mnt/media/tv/E01.mkv", Mode: 0o644, OwnerUID: 1000, GroupGID: 100},
+	}
+	if err := WritePermissionsDump(path, perms); err != nil {
+		t.Fatalf("WritePermissionsDump: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []JSONPermissionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	want := []JSONPermissionRecord{
+		{Path: "/mnt/media/tv", Mode: "2775", ModeString: perms[0].ModeString(), OwnerUID: 1000, GroupGID: 1000, IsDirectory: true, SGID: true},
+		{Path: "/mnt/media/tv/E01.mkv", Mode: "0644", ModeString: perms[1].ModeString(), OwnerUID: 1000, GroupGID: 100},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records = %+v, want %+v", records, want)
+	}
+	for i := range want {
+		if records[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, records[i], want[i])
+		}
+	}
+
+	if err := WritePermissionsDump(path, nil); err != nil {
+		t.Fatalf("WritePermissionsDump with no records: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "[]" {
+		t.Errorf("empty dump = %q, want []", data)
+	}
+}
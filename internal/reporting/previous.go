@@ -0,0 +1,80 @@
+package reporting
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LatestJSONReport returns the newest timestamped JSON report, compressed
+// or not, across reportDirs, or "" when there is none. Reports are compared
+// by the timestamp in their name; on a tie the earlier directory wins.
+func LatestJSONReport(reportDirs ...string) (string, error) {
+	latest, latestName := "", ""
+	for _, dir := range reportDirs {
+		reports, err := listReports(dir)
+		if err != nil {
+			return "", err
+		}
+		for _, path := range reports {
+			if !strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".json.gz") {
+				continue
+			}
+			if name := strings.TrimSuffix(filepath.Base(path), ".gz"); name > latestName {
+				latest, latestName = path, name
+			}
+		}
+	}
+	return latest, nil
+}
+
+// LoadTrackedPaths returns the files an earlier JSON report recorded as
+// tracked by Arr. Reports written before tracked_paths existed only list
+// their at-risk files.
+func LoadTrackedPaths(path string) ([]string, error) {
+	data, err := readJSONReport(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		TrackedPaths []string        `json:"tracked_paths"`
+		AtRisk       []JSONFileEntry `json:"at_risk"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse previous report %s: %w", path, err)
+	}
+	if len(report.TrackedPaths) > 0 {
+		return report.TrackedPaths, nil
+	}
+	paths := make([]string, 0, len(report.AtRisk))
+	for _, e := range report.AtRisk {
+		paths = append(paths, e.Path)
+	}
+	return paths, nil
+}
+
+// readJSONReport reads an earlier JSON report, decompressing it when its
+// name ends in .gz.
+func readJSONReport(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous report: %w", err)
+	}
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,75 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadTrackedPaths_FromLatestReport(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("audit-report-2026-01-01-00-00-00.json", `{"at_risk": [{"path": "/media/old.mkv"}]}`)
+	write("audit-report-2026-01-02-00-00-00.json", `{"tracked_paths": ["/media/a.mkv", "/media/b.mkv"]}`)
+	write("audit-report-2026-01-02-00-00-00.md", "# report")
+	if _, err := CompressReports(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := LatestJSONReport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "audit-report-2026-01-02-00-00-00.json.gz"); latest != want {
+		t.Fatalf("LatestJSONReport = %q, want %q", latest, want)
+	}
+	paths, err := LoadTrackedPaths(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/media/a.mkv", "/media/b.mkv"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("tracked paths = %q, want %q", paths, want)
+	}
+
+	paths, err = LoadTrackedPaths(filepath.Join(dir, "audit-report-2026-01-01-00-00-00.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/media/old.mkv"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("older report without tracked_paths = %q, want its at-risk files %q", paths, want)
+	}
+}
+
+func TestLatestJSONReport_AcrossDirs(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for _, path := range []string{
+		filepath.Join(first, "audit-report-2026-01-01-00-00-00.json"),
+		filepath.Join(second, "audit-report-2026-01-03-00-00-00.json.gz"),
+		filepath.Join(second, "audit-report-2026-01-04-00-00-00.md"),
+		filepath.Join(first, "audit-report-2026-01-02-00-00-00.json"),
+	} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := LatestJSONReport(first, second, filepath.Join(first, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(second, "audit-report-2026-01-03-00-00-00.json.gz"); latest != want {
+		t.Errorf("LatestJSONReport = %q, want %q", latest, want)
+	}
+
+	if latest, err := LatestJSONReport(); err != nil || latest != "" {
+		t.Errorf("LatestJSONReport() = %q, %v; want none", latest, err)
+	}
+}
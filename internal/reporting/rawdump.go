@@ -0,0 +1,82 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jdpx/auditarr/internal/models"
+	"github.com/jdpx/auditarr/internal/utils"
+)
+
+// rawArrFile is an Arr file as collected plus the path it maps to here, so a
+// path-mapping mismatch shows up next to the scanned files.
+type rawArrFile struct {
+	models.ArrFile
+	MappedPath string
+}
+
+// rawTorrent is a torrent as collected plus its mapped save path.
+type rawTorrent struct {
+	models.Torrent
+	MappedSavePath string
+}
+
+// WriteRawDump writes what each collector returned, before analysis, to dir:
+// media_files.json, arr_<service>.json per Arr service and torrents.json.
+// Arr and torrent paths also carry their path-mapped form.
+func WriteRawDump(dir string, mappings map[string]string, mediaFiles []models.MediaFile, arrFiles map[string][]models.ArrFile, torrents []models.Torrent) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	if mediaFiles == nil {
+		mediaFiles = []models.MediaFile{}
+	}
+	files := map[string]any{"media_files.json": mediaFiles}
+	services := make([]string, 0, len(arrFiles))
+	for service := range arrFiles {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		raw := make([]rawArrFile, 0, len(arrFiles[service]))
+		for _, f := range arrFiles[service] {
+			raw = append(raw, rawArrFile{f, utils.NormalizePath(f.Path, mappings)})
+		}
+		files["arr_"+dumpFileName(service)+".json"] = raw
+	}
+	if torrents != nil {
+		raw := make([]rawTorrent, 0, len(torrents))
+		for _, t := range torrents {
+			raw = append(raw, rawTorrent{t, utils.NormalizePath(t.SavePath, mappings)})
+		}
+		files["torrents.json"] = raw
+	}
+
+	for name, v := range files {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpFileName lowercases an instance name and replaces anything but
+// letters, digits, '-' and '_' so it is safe in a file name.
+func dumpFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, strings.ToLower(name))
+}
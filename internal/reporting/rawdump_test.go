@@ -0,0 +1,40 @@
+package reporting
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestWriteRawDump(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "raw")
+	mappings := map[string]string{"/data/media": "/mnt/media"}
+	arr := map[string][]models.ArrFile{
+		"Sonarr 4K": {{Path: "/data/media/tv/Show/E01.mkv", SeriesID: 1}},
+	}
+	if err := WriteRawDump(dir, mappings, nil, arr, nil); err != nil {
+		t.Fatalf("WriteRawDump: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "arr_sonarr_4k.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []map[string]any
+	if err := json.Unmarshal(data, &files); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0]["Path"] != "/data/media/tv/Show/E01.mkv" || files[0]["MappedPath"] != "/mnt/media/tv/Show/E01.mkv" {
+		t.Errorf("arr dump = %s, want the raw and mapped path", data)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "media_files.json")); err != nil || string(data) != "[]" {
+		t.Errorf("media_files.json = %q, %v; want []", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "torrents.json")); !os.IsNotExist(err) {
+		t.Errorf("torrents.json written without qBittorrent data: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// RegressionSections are the JSON report sections compared against a
+// baseline report. Unlinked torrents and lost+found entries come and go with
+// normal use, so they are left out.
+var RegressionSections = []string{
+	"orphaned_media",
+	"removed_from_arr",
+	"orphaned_downloads",
+	"at_risk",
+	"suspicious_files",
+	"corrupt_files",
+	"size_mismatches",
+	"permission_issues",
+}
+
+// Finding is one reported problem, identified across runs by the JSON report
+// section it appears in and its path.
+type Finding struct {
+	Section string
+	Path    string
+}
+
+// LoadFindings reads the findings in RegressionSections from an earlier JSON
+// report, compressed or not.
+func LoadFindings(path string) ([]Finding, error) {
+	data, err := readJSONReport(path)
+	if err != nil {
+		return nil, err
+	}
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse previous report %s: %w", path, err)
+	}
+
+	var findings []Finding
+	for _, section := range RegressionSections {
+		raw, ok := report[section]
+		if !ok {
+			continue
+		}
+		var entries []struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse %s in %s: %w", section, path, err)
+		}
+		for _, e := range entries {
+			findings = append(findings, Finding{Section: section, Path: e.Path})
+		}
+	}
+	return findings, nil
+}
+
+// ResultFindings lists the findings in RegressionSections that result's JSON
+// report would contain.
+func ResultFindings(result *analysis.AnalysisResult) []Finding {
+	var findings []Finding
+	for _, cm := range result.ClassifiedMedia {
+		switch cm.Classification {
+		case models.MediaOrphan:
+			findings = append(findings, Finding{"orphaned_media", cm.File.Path})
+		case models.MediaRemovedFromArr:
+			findings = append(findings, Finding{"removed_from_arr", cm.File.Path})
+		case models.MediaOrphanedDownload:
+			findings = append(findings, Finding{"orphaned_downloads", cm.File.Path})
+		case models.MediaAtRisk:
+			findings = append(findings, Finding{"at_risk", cm.File.Path})
+		}
+	}
+	for _, sf := range result.SuspiciousFiles {
+		findings = append(findings, Finding{"suspicious_files", sf.Path})
+	}
+	for _, cf := range result.CorruptFiles {
+		findings = append(findings, Finding{"corrupt_files", cf.Path})
+	}
+	for _, sm := range result.SizeMismatches {
+		findings = append(findings, Finding{"size_mismatches", sm.Path})
+	}
+	for _, issue := range result.PermissionIssues {
+		findings = append(findings, Finding{"permission_issues", issue.Path})
+	}
+	return findings
+}
+
+// NewFindings returns the findings in current that baseline does not list,
+// once each, sorted by section order and then path.
+func NewFindings(current, baseline []Finding) []Finding {
+	known := make(map[Finding]bool, len(baseline))
+	for _, f := range baseline {
+		known[f] = true
+	}
+	order := make(map[string]int, len(RegressionSections))
+	for i, section := range RegressionSections {
+		order[section] = i
+	}
+
+	var added []Finding
+	for _, f := range current {
+		if !known[f] {
+			known[f] = true
+			added = append(added, f)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool {
+		if added[i].Section != added[j].Section {
+			return order[added[i].Section] < order[added[j].Section]
+		}
+		return added[i].Path < added[j].Path
+	})
+	return added
+}
@@ -0,0 +1,55 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/config"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestNewFindings_AgainstBaselineReport(t *testing.T) {
+	orphan := func(path string) models.ClassifiedMedia {
+		return models.ClassifiedMedia{File: models.MediaFile{Path: path}, Classification: models.MediaOrphan}
+	}
+	before := &analysis.AnalysisResult{
+		ClassifiedMedia: []models.ClassifiedMedia{orphan("/media/old.mkv")},
+		SuspiciousFiles: []models.SuspiciousFile{{Path: "/media/setup.exe"}},
+	}
+	data, err := NewJSONFormatter().Format(before, &config.Config{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := LoadFindings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewFindings(ResultFindings(before), baseline); len(got) != 0 {
+		t.Errorf("an unchanged run reported new findings: %v", got)
+	}
+
+	after := &analysis.AnalysisResult{
+		ClassifiedMedia: []models.ClassifiedMedia{
+			orphan("/media/new.mkv"),
+			orphan("/media/old.mkv"),
+			{File: models.MediaFile{Path: "/media/setup.exe"}, Classification: models.MediaAtRisk},
+		},
+	}
+	want := []Finding{{"orphaned_media", "/media/new.mkv"}, {"at_risk", "/media/setup.exe"}}
+	if got := NewFindings(ResultFindings(after), baseline); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewFindings = %v, want %v (a known path in a new section is new)", got, want)
+	}
+
+	if _, err := LoadFindings(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadFindings should fail on a missing baseline report")
+	}
+}
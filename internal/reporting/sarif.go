@@ -0,0 +1,197 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+// SARIF 2.1.0 is the version code-scanning dashboards ingest.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string           `json:"id"`
+	ShortDescription     sarifMessage     `json:"shortDescription"`
+	DefaultConfiguration sarifRuleDefault `json:"defaultConfiguration"`
+}
+
+type sarifRuleDefault struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRules describes the rules results reference, with the level used
+// when a finding carries no severity of its own.
+var sarifRules = map[string]sarifRule{
+	string(models.MediaOrphan):           {ShortDescription: sarifMessage{"Library file not tracked by Arr"}, DefaultConfiguration: sarifRuleDefault{"error"}},
+	string(models.MediaRemovedFromArr):   {ShortDescription: sarifMessage{"File left on disk after it was removed from Arr"}, DefaultConfiguration: sarifRuleDefault{"warning"}},
+	string(models.MediaOrphanedDownload): {ShortDescription: sarifMessage{"Download neither hardlinked nor tracked by Arr"}, DefaultConfiguration: sarifRuleDefault{"warning"}},
+	string(models.MediaAtRisk):           {ShortDescription: sarifMessage{"Tracked file not hardlinked to a torrent"}, DefaultConfiguration: sarifRuleDefault{"warning"}},
+
+	string(models.ReasonSuspiciousExtension): {ShortDescription: sarifMessage{"File with a suspicious extension"}, DefaultConfiguration: sarifRuleDefault{"error"}},
+	string(models.ReasonDoubleExtension):     {ShortDescription: sarifMessage{"Executable disguised with a double extension"}, DefaultConfiguration: sarifRuleDefault{"error"}},
+	string(models.ReasonKnownBadHash):        {ShortDescription: sarifMessage{"File matches the known-bad hash list"}, DefaultConfiguration: sarifRuleDefault{"error"}},
+	string(models.ReasonExcessiveHardlinks):  {ShortDescription: sarifMessage{"File with more hardlinks than expected"}, DefaultConfiguration: sarifRuleDefault{"warning"}},
+
+	string(models.ReasonWrongOwner):             {ShortDescription: sarifMessage{"Owner not in permissions.allowed_uids"}, DefaultConfiguration: sarifRuleDefault{"error"}},
+	string(models.ReasonWrongGroup):             {ShortDescription: sarifMessage{"Group is not permissions.group_gid"}, DefaultConfiguration: sarifRuleDefault{"error"}},
+	string(models.ReasonNotGroupWritable):       {ShortDescription: sarifMessage{"Group cannot write to the file"}, DefaultConfiguration: sarifRuleDefault{"warning"}},
+	string(models.ReasonNonstandardPermissions): {ShortDescription: sarifMessage{"Mode differs from the expected mode"}, DefaultConfiguration: sarifRuleDefault{"note"}},
+	string(models.ReasonMissingSGID):            {ShortDescription: sarifMessage{"Directory missing the setgid bit"}, DefaultConfiguration: sarifRuleDefault{"warning"}},
+}
+
+// SARIFFormatter renders orphaned, at-risk, suspicious and permission
+// findings as a SARIF log, so they can be ingested by code-scanning
+// dashboards. Each result's location is the file's path as a file:// URI.
+type SARIFFormatter struct{}
+
+func NewSARIFFormatter() *SARIFFormatter {
+	return &SARIFFormatter{}
+}
+
+func (sf *SARIFFormatter) Format(result *analysis.AnalysisResult) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "auditarr",
+			InformationURI: "https://github.com/jdpx/auditarr",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	add := func(ruleID, level, path, message string) {
+		idx, ok := ruleIndex[ruleID]
+		if !ok {
+			rule := sarifRules[ruleID]
+			rule.ID = ruleID
+			if rule.ShortDescription.Text == "" {
+				rule.ShortDescription.Text = ruleID
+			}
+			if rule.DefaultConfiguration.Level == "" {
+				rule.DefaultConfiguration.Level = "warning"
+			}
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[ruleID] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+		if level == "" {
+			level = run.Tool.Driver.Rules[idx].DefaultConfiguration.Level
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: idx,
+			Level:     level,
+			Message:   sarifMessage{message},
+			Locations: []sarifLocation{{sarifPhysicalLocation{sarifArtifactLocation{fileURI(path)}}}},
+		})
+	}
+
+	for _, cm := range result.ClassifiedMedia {
+		switch cm.Classification {
+		case models.MediaOrphan, models.MediaRemovedFromArr, models.MediaOrphanedDownload, models.MediaAtRisk:
+			add(string(cm.Classification), "", cm.File.Path, cm.Reason)
+		}
+	}
+	for _, f := range result.SuspiciousFiles {
+		add(string(f.Code), sarifLevel(f.Severity), f.Path, f.Reason)
+	}
+	for _, issue := range result.PermissionIssues {
+		add(string(issue.Issue), sarifLevel(issue.Severity), issue.Path, issue.FixHint)
+	}
+
+	out := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// sarifLevel maps a finding severity onto a SARIF level: critical is an
+// error and info a note.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "", models.SuspiciousCritical, "error":
+		return "error"
+	case models.SuspiciousInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// WriteToPath writes the log to exactly path, overwriting any previous run.
+func (sf *SARIFFormatter) WriteToPath(data []byte, path string) error {
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
+
+func (sf *SARIFFormatter) WriteToFile(data []byte, reportDir string) (string, error) {
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	timestamp := time.Now().Format("2006-01-02-15-04-05")
+	filename := filepath.Join(reportDir, fmt.Sprintf("audit-report-%s.sarif", timestamp))
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+
+	return filename, nil
+}
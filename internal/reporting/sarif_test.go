@@ -0,0 +1,61 @@
+package reporting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestSARIFFormatter(t *testing.T) {
+	result := &analysis.AnalysisResult{
+		ClassifiedMedia: []models.ClassifiedMedia{
+			{File: models.MediaFile{Path: "/mnt/media/movies/Old Film.mkv"}, Classification: models.MediaOrphan, Reason: "Not tracked"},
+			{File: models.MediaFile{Path: "/mnt/media/tv/E01.mkv"}, Classification: models.MediaHealthy},
+			{File: models.MediaFile{Path: "/mnt/media/tv/E02.mkv"}, Classification: models.MediaOrphan, Reason: "Not tracked"},
+		},
+		SuspiciousFiles: []models.SuspiciousFile{
+			{Path: "/mnt/torrents/x/setup.exe", Code: models.ReasonSuspiciousExtension, Severity: models.SuspiciousInfo},
+		},
+		PermissionIssues: []models.PermissionIssue{
+			{Path: "/mnt/media/tv", Issue: models.ReasonWrongGroup, Severity: "error", FixHint: "File group is GID 0"},
+		},
+	}
+
+	data, err := NewSARIFFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version %q with %d runs, want one 2.1.0 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("rules = %+v, want one per rule used", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 4 {
+		t.Fatalf("results = %+v, want 4 (healthy files skipped)", run.Results)
+	}
+
+	orphan := run.Results[0]
+	if orphan.RuleID != "orphan" || orphan.Level != "error" || orphan.RuleIndex != 0 {
+		t.Errorf("orphan result = %+v", orphan)
+	}
+	if uri := orphan.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "file:///mnt/media/movies/Old%20Film.mkv" {
+		t.Errorf("uri = %q", uri)
+	}
+	if run.Results[1].RuleIndex != 0 {
+		t.Errorf("second orphan rule index = %d, want the shared rule", run.Results[1].RuleIndex)
+	}
+	if s := run.Results[2]; s.RuleID != "suspicious_extension" || s.Level != "note" {
+		t.Errorf("suspicious result = %+v, want an info finding as a note", s)
+	}
+	if p := run.Results[3]; p.RuleID != "wrong_group" || p.Level != "error" || p.Message.Text != "File group is GID 0" {
+		t.Errorf("permission result = %+v", p)
+	}
+}
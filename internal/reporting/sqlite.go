@@ -0,0 +1,140 @@
+package reporting
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/models"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	run_at              TEXT    NOT NULL,
+	duration_ms         INTEGER NOT NULL,
+	total_files         INTEGER NOT NULL,
+	healthy             INTEGER NOT NULL,
+	at_risk             INTEGER NOT NULL,
+	orphan              INTEGER NOT NULL,
+	orphaned_download   INTEGER NOT NULL,
+	suspicious          INTEGER NOT NULL,
+	corrupt             INTEGER NOT NULL,
+	permission_errors   INTEGER NOT NULL,
+	permission_warnings INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS findings (
+	run_id     INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	run_at     TEXT    NOT NULL,
+	path       TEXT    NOT NULL,
+	category   TEXT    NOT NULL,
+	reason     TEXT    NOT NULL,
+	size_bytes INTEGER NOT NULL,
+	PRIMARY KEY (run_id, path, category)
+);
+CREATE INDEX IF NOT EXISTS findings_path ON findings(path, run_at);
+`
+
+// SQLiteWriter appends each run's findings to a SQLite database so audit
+// history can be queried across runs.
+type SQLiteWriter struct {
+	path string
+}
+
+func NewSQLiteWriter(path string) *SQLiteWriter {
+	return &SQLiteWriter{path: path}
+}
+
+type sqliteFinding struct {
+	path     string
+	category string
+	reason   string
+	size     int64
+}
+
+// Write records one run and its findings in a single transaction.
+func (sw *SQLiteWriter) Write(result *analysis.AnalysisResult, runAt time.Time, duration time.Duration) error {
+	db, err := sql.Open("sqlite", sw.path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ts := runAt.UTC().Format(time.RFC3339)
+	s := result.Summary
+	res, err := tx.Exec(`INSERT INTO runs (run_at, duration_ms, total_files, healthy, at_risk, orphan,
+		orphaned_download, suspicious, corrupt, permission_errors, permission_warnings)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ts, duration.Milliseconds(), s.TotalFiles, s.HealthyCount, s.AtRiskCount, s.OrphanCount,
+		s.OrphanedDownloadCount, s.SuspiciousCount, s.CorruptCount, s.PermissionErrors, s.PermissionWarnings)
+	if err != nil {
+		return fmt.Errorf("failed to insert run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read run id: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO findings (run_id, run_at, path, category, reason, size_bytes)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare finding insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, f := range collectFindings(result) {
+		if _, err := stmt.Exec(runID, ts, f.path, f.category, f.reason, f.size); err != nil {
+			return fmt.Errorf("failed to insert finding for %s: %w", f.path, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit run: %w", err)
+	}
+	return nil
+}
+
+// collectFindings flattens every non-healthy result into path/category rows.
+func collectFindings(result *analysis.AnalysisResult) []sqliteFinding {
+	var findings []sqliteFinding
+	for _, cm := range result.ClassifiedMedia {
+		if cm.Classification == models.MediaHealthy {
+			continue
+		}
+		findings = append(findings, sqliteFinding{cm.File.Path, string(cm.Classification), cm.Reason, cm.File.Size})
+	}
+	for _, sf := range result.SuspiciousFiles {
+		findings = append(findings, sqliteFinding{sf.Path, "suspicious", sf.Reason, 0})
+	}
+	for _, cf := range result.CorruptFiles {
+		findings = append(findings, sqliteFinding{cf.Path, "corrupt", cf.Reason, cf.Size})
+	}
+	for _, sm := range result.SizeMismatches {
+		reason := fmt.Sprintf("%d bytes on disk, %d recorded by %s", sm.DiskSize, sm.ArrSize, sm.ArrSource)
+		findings = append(findings, sqliteFinding{sm.Path, "size_mismatch", reason, sm.DiskSize})
+	}
+	for _, f := range result.UnimportedDownloads {
+		findings = append(findings, sqliteFinding{f.Path, "unimported_download", "Not hardlinked into the library", f.Size})
+	}
+	for _, t := range result.UnlinkedTorrents {
+		findings = append(findings, sqliteFinding{filepath.Join(t.SavePath, t.Name), "unlinked_torrent", "Completed torrent with no matching media", t.Size})
+	}
+	for _, pi := range result.PermissionIssues {
+		findings = append(findings, sqliteFinding{pi.Path, "permission_" + pi.Severity, string(pi.Issue), 0})
+	}
+	return findings
+}
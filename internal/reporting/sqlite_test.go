@@ -0,0 +1,55 @@
+package reporting
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jdpx/auditarr/internal/analysis"
+	"github.com/jdpx/auditarr/internal/models"
+)
+
+func TestSQLiteWriter_AppendsRuns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+	result := &analysis.AnalysisResult{
+		ClassifiedMedia: []models.ClassifiedMedia{
+			{File: models.MediaFile{Path: "/media/a.mkv"}, Classification: models.MediaHealthy},
+			{File: models.MediaFile{Path: "/media/b.mkv", Size: 10}, Classification: models.MediaOrphan, Reason: "Not tracked"},
+		},
+		SuspiciousFiles: []models.SuspiciousFile{{Path: "/media/b.exe", Reason: "Suspicious extension"}},
+	}
+
+	w := NewSQLiteWriter(dbPath)
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := w.Write(result, first.Add(time.Duration(i)*24*time.Hour), time.Second); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var runs, findings int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM runs`).Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM findings WHERE path = '/media/b.mkv' AND category = 'orphan'`).Scan(&findings); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 || findings != 2 {
+		t.Errorf("got %d runs and %d orphan rows, want 2 and 2", runs, findings)
+	}
+
+	var healthy int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM findings WHERE path = '/media/a.mkv'`).Scan(&healthy); err != nil {
+		t.Fatal(err)
+	}
+	if healthy != 0 {
+		t.Errorf("healthy files should not be recorded as findings")
+	}
+}
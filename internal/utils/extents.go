@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// FIEMAP ioctl layout from linux/fiemap.h.
+const (
+	fsIocFiemap         = 0xC020660B
+	fiemapFlagSync      = 0x1
+	fiemapExtentUnknown = 0x2
+	fiemapExtentShared  = 0x2000
+	// fiemapMaxExtents is how many extents of each file are compared; a
+	// reflinked video rarely has more, and a match that far in is conclusive.
+	fiemapMaxExtents = 32
+)
+
+type fiemapHeader struct {
+	Start         uint64
+	Length        uint64
+	Flags         uint32
+	MappedExtents uint32
+	ExtentCount   uint32
+	Reserved      uint32
+}
+
+type fiemapExtent struct {
+	Logical    uint64
+	Physical   uint64
+	Length     uint64
+	Reserved64 [2]uint64
+	Flags      uint32
+	Reserved   [3]uint32
+}
+
+type fiemapRequest struct {
+	fiemapHeader
+	Extents [fiemapMaxExtents]fiemapExtent
+}
+
+// SharesExtents reports whether a and b are copy-on-write clones (reflinks)
+// of each other: their leading extents, as reported by FIEMAP, are marked
+// shared and map to the same physical blocks. It is always false off Linux
+// and on filesystems without FIEMAP.
+func SharesExtents(a, b string) bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	extA, err := fileExtents(a)
+	if err != nil || len(extA) == 0 {
+		return false
+	}
+	extB, err := fileExtents(b)
+	if err != nil || len(extB) != len(extA) {
+		return false
+	}
+	for i := range extA {
+		x, y := extA[i], extB[i]
+		if x.Flags&fiemapExtentShared == 0 || x.Flags&fiemapExtentUnknown != 0 {
+			return false
+		}
+		if x.Logical != y.Logical || x.Physical != y.Physical || x.Length != y.Length {
+			return false
+		}
+	}
+	return true
+}
+
+func fileExtents(path string) ([]fiemapExtent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	req := fiemapRequest{fiemapHeader: fiemapHeader{
+		Length:      ^uint64(0),
+		Flags:       fiemapFlagSync,
+		ExtentCount: fiemapMaxExtents,
+	}}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIocFiemap, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return nil, errno
+	}
+	return req.Extents[:req.MappedExtents], nil
+}
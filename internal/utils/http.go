@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes connection reuse for NewTransport. Zero values keep
+// the net/http defaults.
+type TransportOptions struct {
+	RootCAs             *x509.CertPool
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+}
+
+// NewTransport returns a copy of the default transport with opts applied.
+// One transport keeps a pool of idle connections per host, so clients that
+// share it reuse connections to the same service instead of each paying
+// for its own TCP and TLS handshakes.
+func NewTransport(opts TransportOptions) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.RootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: opts.RootCAs}
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		if transport.MaxIdleConns < opts.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = opts.MaxIdleConnsPerHost
+		}
+	}
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	return transport
+}
+
+// TransportWithRootCAs returns a copy of the default transport that verifies
+// servers against pool instead of the system roots.
+func TransportWithRootCAs(pool *x509.CertPool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os/user"
+	"strconv"
+	"sync"
+)
+
+// idNames caches UID and GID name lookups. A permission audit can report the
+// same handful of owners across thousands of files, and each uncached lookup
+// may read /etc/passwd or query NSS.
+var idNames = struct {
+	sync.Mutex
+	users  map[int]string
+	groups map[int]string
+}{users: make(map[int]string), groups: make(map[int]string)}
+
+// UserName returns the login name for uid, or "" if it has none.
+func UserName(uid int) string {
+	idNames.Lock()
+	defer idNames.Unlock()
+	if name, ok := idNames.users[uid]; ok {
+		return name
+	}
+	name := ""
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		name = u.Username
+	}
+	idNames.users[uid] = name
+	return name
+}
+
+// GroupName returns the name of group gid, or "" if it has none.
+func GroupName(gid int) string {
+	idNames.Lock()
+	defer idNames.Unlock()
+	if name, ok := idNames.groups[gid]; ok {
+		return name
+	}
+	name := ""
+	if g, err := user.LookupGroupId(strconv.Itoa(gid)); err == nil {
+		name = g.Name
+	}
+	idNames.groups[gid] = name
+	return name
+}
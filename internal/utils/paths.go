@@ -1,8 +1,6 @@
 package utils
 
 import (
-	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -11,72 +9,47 @@ import (
 	"github.com/jdpx/auditarr/internal/models"
 )
 
-func CollectPermissions(mediaRoot, torrentRoot string, skipPaths []string) ([]models.FilePermissions, error) {
-	var allPermissions []models.FilePermissions
-
-	if mediaRoot != "" {
-		perms, err := collectFromRoot(mediaRoot, skipPaths)
-		if err != nil {
-			return nil, fmt.Errorf("failed to collect permissions from media root: %w", err)
-		}
-		allPermissions = append(allPermissions, perms...)
+// IsUnderPath reports whether path is root itself or lies beneath it,
+// comparing whole path components so /media/tv2 is not under /media/tv.
+func IsUnderPath(path, root string) bool {
+	path = filepath.Clean(path)
+	root = filepath.Clean(root)
+	if path == root {
+		return true
 	}
-
-	if torrentRoot != "" {
-		perms, err := collectFromRoot(torrentRoot, skipPaths)
-		if err != nil {
-			return nil, fmt.Errorf("failed to collect permissions from torrent root: %w", err)
-		}
-		allPermissions = append(allPermissions, perms...)
+	if root == "/" {
+		return strings.HasPrefix(path, "/")
 	}
-
-	return allPermissions, nil
+	return strings.HasPrefix(path, root+string(filepath.Separator))
 }
 
-func collectFromRoot(root string, skipPaths []string) ([]models.FilePermissions, error) {
-	var permissions []models.FilePermissions
-
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			if os.IsPermission(err) {
-				fmt.Fprintf(os.Stderr, "Warning: permission denied: %s\n", path)
-				return nil
-			}
-			return err
-		}
-
-		if shouldSkipPath(path, skipPaths) {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		var stat syscall.Stat_t
-		if err := syscall.Stat(path, &stat); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to stat %s: %v\n", path, err)
-			return nil
-		}
-
-		permissions = append(permissions, models.FilePermissions{
-			Path:        path,
-			Mode:        uint32(stat.Mode),
-			OwnerUID:    int(stat.Uid),
-			GroupGID:    int(stat.Gid),
-			IsDirectory: d.IsDir(),
-		})
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
+// SiblingPath swaps path's extension for ext, e.g. the JSON report written
+// next to a --report-file markdown report. A path already ending in ext gets
+// ext appended, so the sibling never overwrites path itself.
+func SiblingPath(path, ext string) string {
+	sibling := strings.TrimSuffix(path, filepath.Ext(path)) + ext
+	if sibling == path {
+		sibling += ext
 	}
+	return sibling
+}
 
-	return permissions, nil
+// SameDevice reports whether two paths live on the same filesystem. Hardlinks
+// cannot span filesystems, so media and torrents on different devices can
+// never be hardlinked to each other.
+func SameDevice(a, b string) (bool, error) {
+	var statA, statB syscall.Stat_t
+	if err := syscall.Stat(a, &statA); err != nil {
+		return false, err
+	}
+	if err := syscall.Stat(b, &statB); err != nil {
+		return false, err
+	}
+	return statA.Dev == statB.Dev, nil
 }
 
-func shouldSkipPath(path string, skipPaths []string) bool {
+// ShouldSkipPath reports whether path starts with any of skipPaths.
+func ShouldSkipPath(path string, skipPaths []string) bool {
 	for _, skip := range skipPaths {
 		if strings.HasPrefix(path, skip) {
 			return true
@@ -86,7 +59,7 @@ func shouldSkipPath(path string, skipPaths []string) bool {
 }
 
 func IsMediaFile(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
+	ext := models.Ext(path)
 	mediaExts := []string{".mkv", ".mp4", ".avi", ".mov", ".wmv", ".flv", ".webm", ".m4v", ".mpg", ".mpeg", ".ts"}
 	for _, me := range mediaExts {
 		if ext == me {
@@ -97,7 +70,7 @@ func IsMediaFile(path string) bool {
 }
 
 func IsSubtitleFile(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
+	ext := models.Ext(path)
 	subtitleExts := []string{".srt", ".ass", ".ssa", ".vtt", ".sub", ".idx", ".pgs"}
 	for _, se := range subtitleExts {
 		if ext == se {
@@ -125,7 +98,7 @@ func NormalizePath(path string, mappings map[string]string) string {
 
 	for _, apiPath := range sortedPaths {
 		apiPathClean := filepath.Clean(apiPath)
-		if strings.HasPrefix(normalized, apiPathClean) {
+		if IsUnderPath(normalized, apiPathClean) {
 			relative := strings.TrimPrefix(normalized, apiPathClean)
 			normalized = filepath.Join(mappings[apiPath], relative)
 			break
@@ -144,7 +117,7 @@ func NormalizePathReverse(path string, mappings map[string]string) string {
 
 	for apiPath, fsPath := range mappings {
 		fsPathClean := filepath.Clean(fsPath)
-		if strings.HasPrefix(normalized, fsPathClean) {
+		if IsUnderPath(normalized, fsPathClean) {
 			relative := strings.TrimPrefix(normalized, fsPathClean)
 			normalized = filepath.Join(apiPath, relative)
 			break
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizePath_MatchesWholeComponents(t *testing.T) {
+	mappings := map[string]string{"/tv": "/mnt/media/tv", "/data/": "/mnt/media-arr"}
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"/tv/Show/S01E01.mkv", "/mnt/media/tv/Show/S01E01.mkv"},
+		{"/tv", "/mnt/media/tv"},
+		{"/tvshows/Show/S01E01.mkv", "/tvshows/Show/S01E01.mkv"},
+		{"/tv2/Show/S01E01.mkv", "/tv2/Show/S01E01.mkv"},
+		{"/data/torrents/a.mkv", "/mnt/media-arr/torrents/a.mkv"},
+	} {
+		if got := NormalizePath(tc.in, mappings); got != tc.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	if got := NormalizePathReverse("/mnt/media/tv2/a.mkv", map[string]string{"/tv": "/mnt/media/tv"}); got != "/mnt/media/tv2/a.mkv" {
+		t.Errorf("NormalizePathReverse crossed a component boundary: %q", got)
+	}
+}
+
+func TestSameDevice(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "media")
+	b := filepath.Join(dir, "torrents")
+	for _, p := range []string{a, b} {
+		if err := os.Mkdir(p, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	same, err := SameDevice(a, b)
+	if err != nil || !same {
+		t.Errorf("SameDevice(%q, %q) = %t, %v; want true", a, b, same, err)
+	}
+
+	if _, err := SameDevice(a, filepath.Join(dir, "missing")); err == nil {
+		t.Error("SameDevice with a missing path returned no error")
+	}
+}
+
+func TestSiblingPath(t *testing.T) {
+	for _, tc := range []struct {
+		path, ext, want string
+	}{
+		{"/reports/latest.md", ".json", "/reports/latest.json"},
+		{"/reports/latest", ".json", "/reports/latest.json"},
+		{"/reports/audit.v2.md", ".json", "/reports/audit.v2.json"},
+		{"/reports/latest.json", ".json", "/reports/latest.json.json"},
+		{"/reports.d/latest", ".sarif", "/reports.d/latest.sarif"},
+	} {
+		if got := SiblingPath(tc.path, tc.ext); got != tc.want {
+			t.Errorf("SiblingPath(%q, %q) = %q, want %q", tc.path, tc.ext, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress periodically writes a "<label>: N files (elapsed)" line while a
+// long-running collection is in progress. Add is safe to call from multiple
+// goroutines; a nil *Progress is a no-op so callers need not check.
+type Progress struct {
+	label    string
+	out      io.Writer
+	interval time.Duration
+	start    time.Time
+	count    atomic.Int64
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// StartProgress begins reporting to out every interval until Stop is called.
+func StartProgress(out io.Writer, label string, interval time.Duration) *Progress {
+	p := &Progress{
+		label:    label,
+		out:      out,
+		interval: interval,
+		start:    time.Now(),
+		done:     make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+func (p *Progress) run() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.print()
+		}
+	}
+}
+
+func (p *Progress) print() {
+	elapsed := time.Since(p.start).Truncate(time.Second)
+	fmt.Fprintf(p.out, "\r\033[K%s: %d files (%s)", p.label, p.count.Load(), elapsed)
+}
+
+// Add records n more items.
+func (p *Progress) Add(n int) {
+	if p == nil {
+		return
+	}
+	p.count.Add(int64(n))
+}
+
+// Stop halts reporting and clears the progress line.
+func (p *Progress) Stop() {
+	if p == nil {
+		return
+	}
+	close(p.done)
+	p.wg.Wait()
+	fmt.Fprint(p.out, "\r\033[K")
+}
+
+// IsTerminal reports whether f is attached to a character device such as a TTY.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
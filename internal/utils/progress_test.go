@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer lets the test read what the progress goroutine has written.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProgress_ConcurrentAdd(t *testing.T) {
+	var out lockedBuffer
+	p := StartProgress(&out, "Scanning media", 5*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				p.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "1600 files") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no line reported all 1600 files: %q", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Stop()
+
+	// Each update clears the line and rewrites it; Stop leaves it cleared.
+	parts := strings.Split(out.String(), "\r\x1b[K")
+	if len(parts) < 3 || parts[0] != "" || parts[len(parts)-1] != "" {
+		t.Fatalf("output = %q, want cleared updates ending in a cleared line", out.String())
+	}
+	line := regexp.MustCompile(`^Scanning media: \d+ files \(\d+s\)$`)
+	for _, l := range parts[1 : len(parts)-1] {
+		if !line.MatchString(l) {
+			t.Errorf("progress line %q does not match %s", l, line)
+		}
+	}
+}
+
+func TestProgress_Throttled(t *testing.T) {
+	var out lockedBuffer
+	p := StartProgress(&out, "Scanning", time.Hour)
+	for range 1000 {
+		p.Add(1)
+	}
+	p.Stop()
+	if got := out.String(); got != "\r\x1b[K" {
+		t.Errorf("output before the first interval = %q, want only the clear on Stop", got)
+	}
+}
+
+func TestProgress_Nil(t *testing.T) {
+	var p *Progress
+	p.Add(1)
+	p.Stop()
+}
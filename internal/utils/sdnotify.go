@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SdNotify sends a state message (e.g. "READY=1") to systemd's notification
+// socket. It is a no-op returning false when NOTIFY_SOCKET is unset, i.e. when
+// not running under a Type=notify unit.
+func SdNotify(state string) (bool, error) {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return false, nil
+	}
+
+	// A leading '@' denotes a socket in the abstract namespace.
+	if socketPath[0] == '@' {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SdWatchdogInterval returns the watchdog timeout systemd expects pings
+// within, or false if the watchdog is not enabled for this process.
+func SdWatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// Watchdog pings systemd's watchdog on behalf of code that calls Beat as it
+// makes progress, so a process that hangs stops pinging and is restarted
+// once the watchdog timeout passes. Pings are limited to one per half
+// interval. A nil *Watchdog does nothing.
+type Watchdog struct {
+	interval time.Duration
+	ping     func()
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewWatchdog returns a watchdog that calls ping, typically sending
+// WATCHDOG=1, at most once per interval/2.
+func NewWatchdog(interval time.Duration, ping func()) *Watchdog {
+	return &Watchdog{interval: interval, ping: ping, now: time.Now}
+}
+
+// Beat records progress and pings if the last ping was long enough ago.
+func (w *Watchdog) Beat() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	now := w.now()
+	due := now.Sub(w.last) >= w.interval/2
+	if due {
+		w.last = now
+	}
+	w.mu.Unlock()
+	if due {
+		w.ping()
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSdNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := SdNotify("READY=1"); sent || err != nil {
+		t.Fatalf("without NOTIFY_SOCKET: sent=%t err=%v, want a no-op", sent, err)
+	}
+
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	sent, err := SdNotify("WATCHDOG=1")
+	if !sent || err != nil {
+		t.Fatalf("SdNotify: sent=%t err=%v", sent, err)
+	}
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "WATCHDOG=1" {
+		t.Errorf("socket received %q, want WATCHDOG=1", got)
+	}
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	if sent, err := SdNotify("READY=1"); sent || err == nil {
+		t.Errorf("missing socket: sent=%t err=%v, want an error", sent, err)
+	}
+}
+
+func TestSdWatchdogInterval(t *testing.T) {
+	self := strconv.Itoa(os.Getpid())
+	for _, tc := range []struct {
+		name, usec, pid string
+		want            time.Duration
+		ok              bool
+	}{
+		{"enabled", "30000000", "", 30 * time.Second, true},
+		{"for this process", "500000", self, 500 * time.Millisecond, true},
+		{"for another process", "500000", "1", 0, false},
+		{"unset", "", "", 0, false},
+		{"invalid", "soon", "", 0, false},
+		{"zero", "0", "", 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tc.usec)
+			t.Setenv("WATCHDOG_PID", tc.pid)
+			got, ok := SdWatchdogInterval()
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("SdWatchdogInterval() = %s, %t; want %s, %t", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestWatchdog_BeatRateLimitsPings(t *testing.T) {
+	pings := 0
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	w := NewWatchdog(10*time.Second, func() { pings++ })
+	w.now = func() time.Time { return now }
+
+	w.Beat()
+	w.Beat()
+	if pings != 1 {
+		t.Fatalf("pings = %d after two immediate beats, want 1", pings)
+	}
+	now = now.Add(4 * time.Second)
+	w.Beat()
+	if pings != 1 {
+		t.Fatalf("pings = %d before half the interval passed, want 1", pings)
+	}
+	now = now.Add(time.Second)
+	w.Beat()
+	if pings != 2 {
+		t.Fatalf("pings = %d after half the interval, want 2", pings)
+	}
+
+	var nilWatchdog *Watchdog
+	nilWatchdog.Beat()
+}